@@ -0,0 +1,330 @@
+package ring
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Animation is a change over time, advanced by an Animator.
+type Animation interface {
+	// Tick advances the animation by dt, and reports whether the animation
+	// finished.
+	Tick(dt time.Duration) bool
+}
+
+// Animator advances a set of animations. Finished animations are removed
+// automatically. A ring has its own animator, advanced while the ring runs
+// (see Ring.Animator).
+type Animator struct {
+	mu     sync.Mutex
+	anims  []*playing
+	paused bool
+}
+
+// playing is an animation played by an animator, with the functions to call
+// once it ends.
+type playing struct {
+	an   Animation
+	then []func(completed bool)
+}
+
+// NewAnimator creates an animator without animations.
+func NewAnimator() *Animator {
+	return &Animator{}
+}
+
+// Play starts advancing an animation.
+func (a *Animator) Play(an Animation) {
+	a.play(&playing{an: an})
+}
+
+// PlayThen starts advancing an animation, and calls then once it ends, with
+// whether it completed, or was stopped with Stop. then is called from the
+// goroutine that ticks the animator, usually Ring.Run, so it must not block,
+// but it can play the next animation, such as to chain animations:
+//
+//	r.Animator().PlayThen(wipe, func(completed bool) {
+//		if completed {
+//			r.Animator().Play(fade)
+//		}
+//	})
+func (a *Animator) PlayThen(an Animation, then func(completed bool)) {
+	a.play(&playing{an: an, then: []func(bool){then}})
+}
+
+// PlayDone starts advancing an animation, and returns a channel that receives
+// whether it completed, or was stopped with Stop, once it ends.
+func (a *Animator) PlayDone(an Animation) <-chan bool {
+	done := make(chan bool, 1)
+	a.PlayThen(an, func(completed bool) { done <- completed })
+
+	return done
+}
+
+func (a *Animator) play(p *playing) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.anims = append(a.anims, p)
+}
+
+// Stop stops advancing an animation, leaving it in its current state.
+// Animations are matched with ==, so animations of types that are not
+// comparable, such as slices and funcs, cannot be stopped, and should be
+// played as pointers.
+func (a *Animator) Stop(an Animation) {
+	if p := a.remove(func(p *playing) bool { return sameAnimation(p.an, an) }); p != nil {
+		p.end(false)
+	}
+}
+
+// sameAnimation reports whether two animations are equal, without panicking
+// on animations that are not comparable.
+func sameAnimation(x, y Animation) bool {
+	t := reflect.TypeOf(x)
+	if t != reflect.TypeOf(y) || t != nil && !t.Comparable() {
+		return false
+	}
+	return x == y
+}
+
+// remove stops advancing the first animation that matches, and returns it, or
+// nil if none matches.
+func (a *Animator) remove(match func(p *playing) bool) *playing {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, p := range a.anims {
+		if match(p) {
+			a.anims = append(a.anims[:i:i], a.anims[i+1:]...)
+			return p
+		}
+	}
+
+	return nil
+}
+
+// end calls the functions of an animation that ended.
+func (p *playing) end(completed bool) {
+	for _, f := range p.then {
+		f(completed)
+	}
+}
+
+// Pause freezes all the animations in their current state until Resume is
+// called. Animations can still be played and stopped while paused.
+func (a *Animator) Pause() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.paused = true
+}
+
+// Resume continues advancing the animations after Pause.
+func (a *Animator) Resume() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.paused = false
+}
+
+// Paused reports whether the animator is paused.
+func (a *Animator) Paused() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.paused
+}
+
+// Tick advances all the animations by dt, unless the animator is paused. It
+// reports whether there are no more animations to play, so an animator can be
+// played by another animator.
+func (a *Animator) Tick(dt time.Duration) bool {
+	a.mu.Lock()
+	anims := a.anims
+	paused := a.paused
+	a.mu.Unlock()
+
+	if paused {
+		return false
+	}
+
+	var done []*playing
+	for _, p := range anims {
+		if p.an.Tick(dt) {
+			done = append(done, p)
+		}
+	}
+	for _, p := range done {
+		// Animations stopped while ticking have already ended.
+		if a.remove(func(q *playing) bool { return q == p }) != nil {
+			p.end(true)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return len(a.anims) == 0
+}
+
+// Sequence returns an animation that plays animations one after the other,
+// and finishes with the last one, such as to wipe in, hold and fade out:
+//
+//	r.Animator().Play(ring.Sequence(wipeIn, ring.Wait(2*time.Second), fadeOut))
+//
+// The time left of the tick that finishes an animation of this package, such
+// as a Wait or a Timeline, is passed to the next animation in the same tick.
+// Other animations start from the tick after the previous one finished.
+func Sequence(anims ...Animation) Animation {
+	return &sequence{anims: anims}
+}
+
+type sequence struct {
+	mu    sync.Mutex
+	anims []Animation
+	i     int           // animation playing
+	left  time.Duration // time left of the tick that finished the last one
+}
+
+// overrunner is implemented by animations that know how much of the tick that
+// finished them was left, so a sequence can pass it to the next animation.
+type overrunner interface {
+	overrun() time.Duration
+}
+
+// overrun returns the time left of the tick that finished an animation, or 0
+// if the animation does not know it.
+func overrun(an Animation) time.Duration {
+	if o, ok := an.(overrunner); ok {
+		return o.overrun()
+	}
+	return 0
+}
+
+func (s *sequence) Tick(dt time.Duration) bool {
+	for {
+		s.mu.Lock()
+		if s.i >= len(s.anims) {
+			s.mu.Unlock()
+			return true
+		}
+		an := s.anims[s.i]
+		s.mu.Unlock()
+
+		if !an.Tick(dt) {
+			return false
+		}
+		dt = overrun(an)
+
+		s.mu.Lock()
+		s.i++
+		s.left = dt
+		last := s.i >= len(s.anims)
+		s.mu.Unlock()
+
+		if last || dt <= 0 {
+			return last
+		}
+	}
+}
+
+func (s *sequence) overrun() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.left
+}
+
+// Parallel returns an animation that plays animations at the same time, and
+// finishes once all of them finished.
+func Parallel(anims ...Animation) Animation {
+	return &parallel{anims: anims, done: make([]bool, len(anims))}
+}
+
+type parallel struct {
+	mu    sync.Mutex
+	anims []Animation
+	done  []bool        // animations finished
+	left  time.Duration // time left of the tick that finished the last ones
+}
+
+func (p *parallel) Tick(dt time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	finished := true
+	p.left = dt
+	for i, an := range p.anims {
+		if !p.done[i] {
+			p.done[i] = an.Tick(dt)
+			if left := overrun(an); p.done[i] && left < p.left {
+				p.left = left
+			}
+		}
+		finished = finished && p.done[i]
+	}
+
+	return finished
+}
+
+func (p *parallel) overrun() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.left
+}
+
+// Wait returns an animation that does nothing for a duration, such as to hold
+// a Sequence.
+func Wait(d time.Duration) Animation {
+	return &wait{left: d}
+}
+
+type wait struct {
+	mu   sync.Mutex
+	left time.Duration
+}
+
+func (w *wait) Tick(dt time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.left -= dt
+	return w.left <= 0
+}
+
+func (w *wait) overrun() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.left < 0 {
+		return -w.left
+	}
+	return 0
+}
+
+// Easing maps the progress of an animation, from 0.0 to 1.0, to an eased
+// progress.
+type Easing func(t float64) float64
+
+// Linear keeps a constant speed.
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseIn starts slow and speeds up.
+func EaseIn(t float64) float64 {
+	return t * t
+}
+
+// EaseOut starts fast and slows down.
+func EaseOut(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOut starts slow, speeds up and slows down at the end.
+func EaseInOut(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
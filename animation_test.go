@@ -0,0 +1,136 @@
+package ring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// countdown is an animation that finishes after a duration.
+type countdown struct {
+	left time.Duration
+}
+
+func (c *countdown) Tick(dt time.Duration) bool {
+	c.left -= dt
+	return c.left <= 0
+}
+
+func TestAnimatorDone(t *testing.T) {
+	a := NewAnimator()
+	var ended []string
+	then := func(name string) func(bool) {
+		return func(completed bool) {
+			if completed {
+				ended = append(ended, name+" completed")
+			} else {
+				ended = append(ended, name+" stopped")
+			}
+		}
+	}
+	first := &countdown{left: time.Second}
+	next := &countdown{left: time.Second}
+	stopped := &countdown{left: time.Second}
+
+	a.PlayThen(first, func(completed bool) {
+		then("first")(completed)
+		a.PlayThen(next, then("next"))
+	})
+	a.PlayThen(stopped, then("stopped"))
+	done := a.PlayDone(&countdown{left: time.Second})
+
+	a.Tick(500 * time.Millisecond)
+	a.Stop(stopped)
+	a.Tick(500 * time.Millisecond)
+	a.Tick(time.Second)
+
+	want := []string{"stopped stopped", "first completed", "next completed"}
+	if !reflect.DeepEqual(ended, want) {
+		t.Errorf("got: %v, want: %v", ended, want)
+	}
+	select {
+	case completed := <-done:
+		if !completed {
+			t.Errorf("got: stopped, want: completed")
+		}
+	default:
+		t.Errorf("got: no signal, want: completed")
+	}
+}
+
+// tickFunc is an animation of a type that is not comparable.
+type tickFunc func(dt time.Duration) bool
+
+func (f tickFunc) Tick(dt time.Duration) bool { return f(dt) }
+
+func TestAnimatorStopUncomparable(t *testing.T) {
+	a := NewAnimator()
+	ticks := 0
+	a.Play(tickFunc(func(time.Duration) bool { ticks++; return false }))
+	c := &countdown{left: time.Second}
+	a.Play(c)
+
+	a.Stop(c)
+	a.Stop(tickFunc(nil))
+	a.Tick(time.Millisecond)
+	if got, want := c.left, time.Second; got != want {
+		t.Errorf("got: %v left, want: stopped at %v", got, want)
+	}
+	if got, want := ticks, 1; got != want {
+		t.Errorf("got: %d ticks, want: %d", got, want)
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	tests := []struct {
+		name  string
+		anim  func() Animation
+		ticks int // ticks of 500ms until it finishes
+	}{
+		{"sequence", func() Animation { return Sequence(Wait(time.Second), Wait(500*time.Millisecond)) }, 3},
+		{"parallel", func() Animation { return Parallel(Wait(time.Second), Wait(1500*time.Millisecond)) }, 3},
+		{"nested", func() Animation {
+			return Sequence(Parallel(Wait(time.Second), Wait(500*time.Millisecond)), Wait(time.Second))
+		}, 4},
+		{"empty sequence", func() Animation { return Sequence() }, 1},
+		{"empty parallel", func() Animation { return Parallel() }, 1},
+	}
+
+	for _, ts := range tests {
+		an := ts.anim()
+		ticks := 1
+		for !an.Tick(500*time.Millisecond) && ticks < 10 {
+			ticks++
+		}
+		if ticks != ts.ticks {
+			t.Errorf("%s got: finished after %d ticks, want: %d", ts.name, ticks, ts.ticks)
+		}
+	}
+}
+
+func TestSequenceOverrun(t *testing.T) {
+	tests := []struct {
+		name string
+		anim func() Animation
+		left time.Duration // left of the last countdown after a tick of 1s
+	}{
+		{"wait", func() Animation { return Wait(300 * time.Millisecond) }, 300 * time.Millisecond},
+		{"timeline", func() Animation {
+			tl := NewTimeline()
+			tl.Opacity(newTestLayer(t, &LayerOptions{Resolution: 4}), Keyframe{Value: 1}, Keyframe{At: 300 * time.Millisecond, Value: 0})
+			return tl
+		}, 300 * time.Millisecond},
+		{"nested", func() Animation {
+			return Sequence(Wait(100*time.Millisecond), Parallel(Wait(100*time.Millisecond), Wait(200*time.Millisecond)))
+		}, 300 * time.Millisecond},
+		{"unknown", func() Animation { return &countdown{left: 300 * time.Millisecond} }, time.Second},
+	}
+
+	for _, ts := range tests {
+		c := &countdown{left: time.Second}
+		Sequence(ts.anim(), c).Tick(time.Second)
+		if got := c.left; got != ts.left {
+			t.Errorf("%s got: %v left, want: %v", ts.name, got, ts.left)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// ballStep is the longest step of the simulation of the balls, so fast balls
+// do not pass through the floor.
+const ballStep = 5 * time.Millisecond
+
+// maxBallSteps is the most steps the balls are simulated per advance. Longer
+// advances, such as after a pause, skip the rest of the time, so they do not
+// stall the rendering.
+const maxBallSteps = 200
+
+// Balls is a layer with balls that bounce on a vertical ring, like the
+// bouncing balls of LED strips bent into a circle. The first pixel is the
+// floor, which can be placed with Rotate, and the balls climb the ring on
+// alternating sides, pulled down by gravity, losing speed on each bounce until
+// they are thrown up again. Add it to a ring with AddLayer and run the ring
+// (see Ring.Run) to animate it. Its parameters are "balls", "palette",
+// "gravity" in degrees per second squared, and "damping", the fraction of the
+// speed kept on each bounce (see Tunable).
+type Balls struct {
+	*Layer
+	params
+
+	mu      sync.Mutex
+	palette Palette
+	gravity float64 // angular acceleration at the sides in radians per second squared
+	damping float64 // fraction of the speed kept on each bounce
+	balls   []*ball
+}
+
+// ball is a ball of a Balls layer.
+type ball struct {
+	side   float64 // 1 to climb in the direction of the pixel indices, -1 against it
+	pos    float64 // angle from the floor in radians
+	vel    float64 // angular velocity in radians per second
+	height float64 // highest angle it is thrown up to
+}
+
+// NewBalls creates n balls painted through a palette, that keep 90% of their
+// speed on each bounce. It returns an error if n is negative.
+func NewBalls(n int, p Palette, options *LayerOptions) (*Balls, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("ring: invalid number of balls %d", n)
+	}
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Balls{
+		Layer:   l,
+		palette: p,
+		gravity: radians(1440),
+		damping: 0.9,
+	}
+	b.params = params{specs: ballsParams, get: b.param, set: b.setParam}
+	b.SetBalls(n)
+
+	return b, nil
+}
+
+// SetBalls sets the number of balls, and throws them all up again. Negative
+// numbers remove all the balls.
+func (b *Balls) SetBalls(n int) {
+	if n < 0 {
+		n = 0
+	}
+	b.mu.Lock()
+	b.balls = make([]*ball, n)
+	for i := range b.balls {
+		side := 1.0
+		if i%2 == 1 {
+			side = -1
+		}
+		// Balls are thrown to different heights, so they bounce out of
+		// step.
+		b.balls[i] = &ball{side: side, height: math.Pi * (0.9 - 0.5*float64(i)/float64(n))}
+		b.throw(b.balls[i])
+	}
+	b.mu.Unlock()
+
+	b.paint()
+}
+
+// SetPalette sets the palette the balls are painted through.
+func (b *Balls) SetPalette(p Palette) {
+	b.mu.Lock()
+	b.palette = p
+	b.mu.Unlock()
+
+	b.paint()
+}
+
+// SetGravity sets the pull of gravity, as the angular acceleration of the balls
+// at the sides of the ring (in radians per second squared).
+func (b *Balls) SetGravity(acceleration float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.gravity = acceleration
+}
+
+// SetDamping sets the fraction of the speed the balls keep on each bounce, from
+// 0.0 to 1.0.
+func (b *Balls) SetDamping(damping float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.damping = damping
+}
+
+var ballsParams = []ParamSpec{
+	{Name: "balls", Type: ParamInt, Default: "3", Doc: "number of balls", Min: 1, Max: 16},
+	{Name: "palette", Type: ParamPalette, Default: "rainbow", Doc: "palette of the balls"},
+	{Name: "gravity", Type: ParamFloat, Default: "1440", Doc: "pull of gravity, in degrees per second squared", Min: 0, Max: 10000},
+	{Name: "damping", Type: ParamFloat, Default: "0.9", Doc: "fraction of the speed kept on each bounce", Min: 0, Max: 1},
+}
+
+func (b *Balls) param(name string) interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch name {
+	case "balls":
+		return len(b.balls)
+	case "palette":
+		return b.palette
+	case "gravity":
+		return b.gravity * 180 / math.Pi
+	case "damping":
+		return b.damping
+	}
+	return nil
+}
+
+func (b *Balls) setParam(name string, v interface{}) {
+	switch name {
+	case "balls":
+		b.SetBalls(v.(int))
+	case "palette":
+		b.SetPalette(v.(Palette))
+	case "gravity":
+		b.SetGravity(radians(v.(float64)))
+	case "damping":
+		b.SetDamping(v.(float64))
+	}
+}
+
+// throw throws a ball up from the floor to its height. It must be called with
+// mu held.
+func (b *Balls) throw(x *ball) {
+	x.pos = 0
+	x.vel = b.launch(x.height)
+}
+
+// launch returns the speed that takes a ball from the floor up to a height, as
+// a bead sliding on a vertical ring. It must be called with mu held.
+func (b *Balls) launch(height float64) float64 {
+	return math.Sqrt(2 * b.gravity * (1 - math.Cos(height)))
+}
+
+func (b *Balls) advance(dt time.Duration) {
+	b.mu.Lock()
+	left := dt
+	if left > maxBallSteps*ballStep {
+		left = maxBallSteps * ballStep
+	}
+	for ; left > 0; left -= ballStep {
+		s := math.Min(left.Seconds(), ballStep.Seconds())
+		for _, x := range b.balls {
+			x.vel -= b.gravity * math.Sin(x.pos) * s
+			x.pos += x.vel * s
+			if x.pos > 0 {
+				continue
+			}
+			x.pos = -x.pos
+			x.vel = -x.vel * b.damping
+			if x.vel < 0.1*b.launch(x.height) {
+				b.throw(x)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.paint()
+	b.Layer.advance(dt)
+}
+
+func (b *Balls) paint() {
+	b.mu.Lock()
+	scale := float64(b.opt.Resolution) / (2 * math.Pi)
+	type spot struct {
+		pos   float64
+		paint func(float64) color.RGBA64
+	}
+	spots := make([]spot, len(b.balls))
+	for i, x := range b.balls {
+		c := b.palette.at64(float64(i) / float64(len(b.balls)))
+		spots[i] = spot{x.side * x.pos * scale, func(float64) color.RGBA64 { return c }}
+	}
+	b.mu.Unlock()
+
+	b.SetAll(color.Transparent)
+	for _, s := range spots {
+		b.drawSpan(s.pos-0.5, s.pos+0.5, s.paint)
+	}
+}
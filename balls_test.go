@@ -0,0 +1,92 @@
+package ring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBalls(t *testing.T) {
+	tests := []struct {
+		name    string
+		damping float64
+		peaks   []float64 // highest angles of the first bounces, in degrees
+	}{
+		{"elastic", 1, []float64{162, 162, 162}},
+		{"damped", 0.5, []float64{162, 59.2, 28.6, 14.2, 162}},
+	}
+
+	for _, ts := range tests {
+		b, err := NewBalls(1, PaletteRainbow, &LayerOptions{Resolution: 36})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.SetDamping(ts.damping)
+
+		var peaks []float64
+		peak, rising := 0.0, true
+		for i := 0; i < 10000 && len(peaks) < len(ts.peaks); i++ {
+			b.advance(10 * time.Millisecond)
+			x := b.balls[0]
+			switch {
+			case x.vel > 0 && !rising:
+				rising, peak = true, 0
+			case x.vel <= 0 && rising:
+				rising = false
+				peaks = append(peaks, peak*180/math.Pi)
+			}
+			peak = math.Max(peak, x.pos)
+		}
+
+		for i, want := range ts.peaks {
+			if i >= len(peaks) {
+				t.Errorf("%s got: %d bounces, want: %d", ts.name, len(peaks), len(ts.peaks))
+				break
+			}
+			if got := peaks[i]; math.Abs(got-want) > want*0.05 {
+				t.Errorf("%s bounce %d got: peak at %.1f°, want: %.1f°", ts.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestBallsSides(t *testing.T) {
+	b, err := NewBalls(2, PaletteRainbow, &LayerOptions{Resolution: 36})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.advance(100 * time.Millisecond)
+
+	if !(b.balls[0].side > 0 && b.balls[1].side < 0) {
+		t.Errorf("got: sides %v and %v, want: 1 and -1", b.balls[0].side, b.balls[1].side)
+	}
+	for i, want := range []int{int(b.balls[0].pos * 36 / (2 * math.Pi)), 36 - int(b.balls[1].pos*36/(2*math.Pi)) - 1} {
+		if _, _, _, a := b.Pixel(want).RGBA(); a == 0 {
+			t.Errorf("ball %d got: pixel %d dark, want: lit", i, want)
+		}
+	}
+}
+
+func TestBallsLimits(t *testing.T) {
+	if _, err := NewBalls(-1, PaletteRainbow, &LayerOptions{Resolution: 36}); err == nil {
+		t.Errorf("-1 balls got: nil, want: error")
+	}
+
+	b, err := NewBalls(2, PaletteRainbow, &LayerOptions{Resolution: 36})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A long advance, such as after a pause, is cut short instead of
+	// simulating every step.
+	b.advance(1000 * time.Hour)
+	for i, x := range b.balls {
+		if x.pos < 0 || x.pos > math.Pi {
+			t.Errorf("ball %d got: at %v, want: on the ring", i, x.pos)
+		}
+	}
+
+	b.SetBalls(-1)
+	if got := len(b.balls); got != 0 {
+		t.Errorf("got: %d balls, want: 0", got)
+	}
+}
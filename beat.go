@@ -0,0 +1,262 @@
+package ring
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Beat is a beat of music.
+type Beat struct {
+	// At is the time of the beat from the start of the audio.
+	At time.Duration
+	// Strength is how much louder the beat is than the music before it, at
+	// least OnsetOptions.Sensitivity.
+	Strength float64
+	// Tempo is the estimated tempo of the music, in beats per minute, or 0
+	// until enough beats are detected.
+	Tempo float64
+}
+
+// BeatSource tells effects when music beats, so they can change in time with
+// it, such as from an OnsetDetector fed from an audio capture.
+type BeatSource interface {
+	// OnBeat calls f on each beat until cancel is called. f is called from
+	// the goroutine that detects the beats, so it must not block.
+	OnBeat(f func(b Beat)) (cancel func())
+}
+
+// minBeatEnergy is the energy of audio, as the mean square of its samples,
+// below which there are no beats, so noise in silence is ignored.
+const minBeatEnergy = 1e-6
+
+// OnsetOptions is the list of options of an onset detector.
+type OnsetOptions struct {
+	// SampleRate is the number of samples per second of the audio (default:
+	// 44100).
+	SampleRate int
+	// Window is the duration of the blocks of audio whose energy is compared
+	// (default: 20 milliseconds).
+	Window time.Duration
+	// History is how long the energy of the music is averaged to compare the
+	// current block against (default: 1 second).
+	History time.Duration
+	// Sensitivity is how many times louder than the average a block must be
+	// to be a beat (default: 1.5).
+	Sensitivity float64
+	// MinInterval is the minimum time between beats (default: 250
+	// milliseconds, or 240 beats per minute).
+	MinInterval time.Duration
+}
+
+// OnsetDetector is a BeatSource that detects beats in audio by comparing the
+// energy of each short block of audio with the average energy of the music
+// before it. Feed it mono audio with WriteSamples or ReadFrom, such as from
+// "arecord -f S16_LE -c 1 -r 44100":
+//
+//	d := ring.NewOnsetDetector(nil)
+//	d.OnBeat(func(b ring.Beat) {
+//		comet.SetParam("color", colors[n%len(colors)])
+//		n++
+//	})
+//	go d.ReadFrom(os.Stdin)
+type OnsetDetector struct {
+	opt     OnsetOptions
+	block   int // samples per block
+	history int // blocks in the history
+
+	mu       sync.Mutex
+	subs     map[int]func(Beat)
+	next     int
+	sum      float64   // sum of squares of the current block
+	n        int       // samples in the current block
+	energies []float64 // energies of the previous blocks, oldest first
+	samples  int64     // samples written
+	last     time.Duration
+	beats    int
+	periods  []time.Duration // latest times between beats
+}
+
+// NewOnsetDetector creates an onset detector with the defaults of the
+// options.
+func NewOnsetDetector(options *OnsetOptions) *OnsetDetector {
+	d := &OnsetDetector{
+		subs: make(map[int]func(Beat)),
+	}
+	if options != nil {
+		d.opt = *options
+	}
+	if d.opt.SampleRate <= 0 {
+		d.opt.SampleRate = 44100
+	}
+	if d.opt.Window <= 0 {
+		d.opt.Window = 20 * time.Millisecond
+	}
+	if d.opt.History <= 0 {
+		d.opt.History = time.Second
+	}
+	if d.opt.Sensitivity == 0 {
+		d.opt.Sensitivity = 1.5
+	}
+	if d.opt.MinInterval <= 0 {
+		d.opt.MinInterval = 250 * time.Millisecond
+	}
+
+	d.block = int(int64(d.opt.SampleRate) * int64(d.opt.Window) / int64(time.Second))
+	if d.block < 1 {
+		d.block = 1
+	}
+	d.history = int(d.opt.History / d.opt.Window)
+	if d.history < 1 {
+		d.history = 1
+	}
+
+	return d
+}
+
+// OnBeat calls f on each beat detected, until cancel is called. f is called
+// from the goroutine that feeds the audio, so it must not block.
+func (d *OnsetDetector) OnBeat(f func(b Beat)) (cancel func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	id := d.next
+	d.next++
+	d.subs[id] = f
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		delete(d.subs, id)
+	}
+}
+
+// WriteSamples feeds mono audio samples, from -1.0 to 1.0, to the detector.
+func (d *OnsetDetector) WriteSamples(samples []float64) {
+	for _, s := range samples {
+		d.mu.Lock()
+		d.sum += s * s
+		d.n++
+		d.samples++
+		var beat *Beat
+		var subs []func(Beat)
+		if d.n == d.block {
+			beat = d.endBlock()
+			d.sum, d.n = 0, 0
+			if beat != nil {
+				for _, f := range d.subs {
+					subs = append(subs, f)
+				}
+			}
+		}
+		d.mu.Unlock()
+
+		for _, f := range subs {
+			f(*beat)
+		}
+	}
+}
+
+// ReadFrom feeds the detector with mono audio read from r as signed 16-bit
+// little-endian samples, until r returns an error. It returns the number of
+// bytes read, and the error of r unless it is io.EOF.
+func (d *OnsetDetector) ReadFrom(r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, 2*d.block)
+	samples := make([]float64, d.block)
+	var total int64
+	for {
+		n, err := io.ReadFull(br, buf)
+		total += int64(n)
+		for i := 0; i < n/2; i++ {
+			samples[i] = float64(int16(binary.LittleEndian.Uint16(buf[2*i:]))) / 32768
+		}
+		d.WriteSamples(samples[:n/2])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// endBlock compares the energy of the block that just ended with the history,
+// and returns the beat, if there is one. It must be called with mu held.
+func (d *OnsetDetector) endBlock() *Beat {
+	energy := d.sum / float64(d.n)
+	full := len(d.energies) == d.history
+	var avg float64
+	for _, e := range d.energies {
+		avg += e
+	}
+	if len(d.energies) > 0 {
+		avg /= float64(len(d.energies))
+	}
+
+	if full {
+		d.energies = append(d.energies[:0], d.energies[1:]...)
+	}
+	d.energies = append(d.energies, energy)
+	if !full || energy < minBeatEnergy || energy < d.opt.Sensitivity*avg {
+		return nil
+	}
+
+	at := time.Duration(d.samples) * time.Second / time.Duration(d.opt.SampleRate)
+	if d.beats > 0 && at-d.last < d.opt.MinInterval {
+		return nil
+	}
+	if d.beats > 0 && at-d.last <= 8*d.opt.MinInterval {
+		d.periods = append(d.periods, at-d.last)
+		if len(d.periods) > 8 {
+			d.periods = d.periods[1:]
+		}
+	}
+	d.last = at
+	d.beats++
+
+	b := &Beat{
+		At:       at,
+		Strength: energy / avg,
+		Tempo:    d.tempo(),
+	}
+	if avg == 0 {
+		b.Strength = d.opt.Sensitivity
+	}
+
+	return b
+}
+
+// tempo estimates the tempo, in beats per minute, from the median time between
+// the latest beats.
+func (d *OnsetDetector) tempo() float64 {
+	if len(d.periods) < 3 {
+		return 0
+	}
+	ps := append([]time.Duration(nil), d.periods...)
+	sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+
+	return float64(time.Minute) / float64(ps[len(ps)/2])
+}
+
+// CycleOnBeat changes a parameter of a tunable effect to the next of the values
+// on each beat of a source, such as to change the palette of an effect in time
+// with music. Values are given as for Tunable.SetParam. Call cancel to stop.
+func CycleOnBeat(src BeatSource, t Tunable, param string, values ...interface{}) (cancel func()) {
+	var mu sync.Mutex
+	i := 0
+	return src.OnBeat(func(Beat) {
+		if len(values) == 0 {
+			return
+		}
+		mu.Lock()
+		v := values[i]
+		i = (i + 1) % len(values)
+		mu.Unlock()
+		t.SetParam(param, v)
+	})
+}
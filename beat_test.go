@@ -0,0 +1,95 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// music returns audio with a quiet tone and a loud click on each beat.
+func music(rate int, bpm float64, d time.Duration) []float64 {
+	samples := make([]float64, int(d.Seconds()*float64(rate)))
+	period := int(60 / bpm * float64(rate))
+	click := rate / 50
+	for i := range samples {
+		samples[i] = 0.05 * math.Sin(2*math.Pi*220*float64(i)/float64(rate))
+		if i%period < click {
+			samples[i] += 0.8 * math.Sin(2*math.Pi*60*float64(i)/float64(rate))
+		}
+	}
+	return samples
+}
+
+func TestOnsetDetector(t *testing.T) {
+	d := NewOnsetDetector(&OnsetOptions{SampleRate: 8000})
+	var beats []Beat
+	cancel := d.OnBeat(func(b Beat) { beats = append(beats, b) })
+	d.WriteSamples(music(8000, 120, 4*time.Second))
+
+	// The first second fills the history.
+	if got, want := len(beats), 6; got != want {
+		t.Fatalf("got: %d beats, want: %d", got, want)
+	}
+	for i, b := range beats {
+		if got, want := b.At, time.Duration(i+2)*500*time.Millisecond; got < want || got > want+40*time.Millisecond {
+			t.Errorf("beat %d got: at %v, want: %v", i, got, want)
+		}
+		if b.Strength < 1.5 {
+			t.Errorf("beat %d got: strength %v, want: >= 1.5", i, b.Strength)
+		}
+	}
+	if got := beats[len(beats)-1].Tempo; math.Abs(got-120) > 1 {
+		t.Errorf("got: tempo %v, want: 120", got)
+	}
+
+	cancel()
+	d.WriteSamples(music(8000, 120, time.Second))
+	if got, want := len(beats), 6; got != want {
+		t.Errorf("after cancel got: %d beats, want: %d", got, want)
+	}
+}
+
+func TestOnsetDetectorReadFrom(t *testing.T) {
+	var buf bytes.Buffer
+	for _, s := range music(8000, 120, 2*time.Second) {
+		binary.Write(&buf, binary.LittleEndian, int16(s*32767))
+	}
+	size := int64(buf.Len())
+
+	d := NewOnsetDetector(&OnsetOptions{SampleRate: 8000})
+	n := 0
+	d.OnBeat(func(Beat) { n++ })
+	if got, err := d.ReadFrom(&buf); err != nil || got != size {
+		t.Fatalf("got: %d, %v, want: %d, nil", got, err, size)
+	}
+	if n != 2 {
+		t.Errorf("got: %d beats, want: 2", n)
+	}
+}
+
+type fakeBeats struct {
+	f func(Beat)
+}
+
+func (b *fakeBeats) OnBeat(f func(Beat)) func() {
+	b.f = f
+	return func() { b.f = nil }
+}
+
+func TestCycleOnBeat(t *testing.T) {
+	e, err := NewEffect("rainbow", nil, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &fakeBeats{}
+	CycleOnBeat(src, e.(Tunable), "palette", "heat", "ocean")
+
+	for _, want := range []string{"heat", "ocean", "heat"} {
+		src.f(Beat{})
+		if got := e.(Tunable).Params()[0].String(); got != want {
+			t.Errorf("got: %s, want: %s", got, want)
+		}
+	}
+}
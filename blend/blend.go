@@ -0,0 +1,210 @@
+// Package blend composites alpha pre-multiplied colors, with the same color
+// math used by rings to blend their layers, so custom Pixelers can blend
+// colors correctly:
+//
+//	c := blend.Over(bottom, top)
+//	c = blend.Lerp(c, glow, 0.25)
+//	c = blend.Screen(c, highlight)
+//
+// All the functions take the color below (dst) first and the color above
+// (src) second, like the Porter-Duff operators.
+package blend
+
+import (
+	"image/color"
+	"math"
+)
+
+// Func blends a color src above a color dst.
+type Func func(dst, src color.RGBA64) color.RGBA64
+
+// compose returns the Porter-Duff composition src*fa + dst*fb, with the
+// factors going from 0 to 0xFFFF. Channels over the maximum are clamped.
+func compose(dst, src color.RGBA64, fa, fb uint32) color.RGBA64 {
+	ch := func(s, d uint16) uint16 {
+		v := (uint64(s)*uint64(fa) + uint64(d)*uint64(fb)) / 0xFFFF
+		if v > 0xFFFF {
+			v = 0xFFFF
+		}
+		return uint16(v)
+	}
+
+	return color.RGBA64{
+		R: ch(src.R, dst.R),
+		G: ch(src.G, dst.G),
+		B: ch(src.B, dst.B),
+		A: ch(src.A, dst.A),
+	}
+}
+
+// Clear returns a transparent color.
+func Clear(dst, src color.RGBA64) color.RGBA64 {
+	return color.RGBA64{}
+}
+
+// Src returns src, ignoring dst.
+func Src(dst, src color.RGBA64) color.RGBA64 {
+	return src
+}
+
+// Dst returns dst, ignoring src.
+func Dst(dst, src color.RGBA64) color.RGBA64 {
+	return dst
+}
+
+// Over places src over dst. It is the Porter-Duff source-over operator, used
+// by rings to blend their layers.
+func Over(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF, 0xFFFF-uint32(src.A))
+}
+
+// DstOver places dst over src.
+func DstOver(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF-uint32(dst.A), 0xFFFF)
+}
+
+// SrcIn keeps the part of src inside dst.
+func SrcIn(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, uint32(dst.A), 0)
+}
+
+// DstIn keeps the part of dst inside src.
+func DstIn(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0, uint32(src.A))
+}
+
+// SrcOut keeps the part of src outside dst.
+func SrcOut(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF-uint32(dst.A), 0)
+}
+
+// DstOut keeps the part of dst outside src.
+func DstOut(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0, 0xFFFF-uint32(src.A))
+}
+
+// SrcAtop places the part of src inside dst over dst.
+func SrcAtop(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, uint32(dst.A), 0xFFFF-uint32(src.A))
+}
+
+// DstAtop places the part of dst inside src over src.
+func DstAtop(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF-uint32(dst.A), uint32(src.A))
+}
+
+// Xor keeps the parts of src and dst outside each other.
+func Xor(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF-uint32(dst.A), 0xFFFF-uint32(src.A))
+}
+
+// Plus adds src and dst, clamping each channel.
+func Plus(dst, src color.RGBA64) color.RGBA64 {
+	return compose(dst, src, 0xFFFF, 0xFFFF)
+}
+
+// Lerp linearly interpolates between two colors given the amount t: (0.0 to
+// 1.0) -> (a to b).
+func Lerp(a, b color.RGBA64, t float64) color.RGBA64 {
+	lerp := func(a, b, l uint32) uint16 {
+		if b >= a {
+			return uint16(a + (b-a)*l/0xFFFF)
+		}
+		return uint16(a - (a-b)*l/0xFFFF)
+	}
+
+	l16 := uint32(t * 0xFFFF)
+
+	return color.RGBA64{
+		R: lerp(uint32(a.R), uint32(b.R), l16),
+		G: lerp(uint32(a.G), uint32(b.G), l16),
+		B: lerp(uint32(a.B), uint32(b.B), l16),
+		A: lerp(uint32(a.A), uint32(b.A), l16),
+	}
+}
+
+// Multiply multiplies the colors, which darkens dst.
+func Multiply(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, func(b, s float64) float64 {
+		return b * s
+	})
+}
+
+// Screen inverts, multiplies and inverts the colors, which lightens dst.
+func Screen(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, screen)
+}
+
+// Overlay multiplies the dark channels of dst and screens the light ones.
+func Overlay(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, func(b, s float64) float64 {
+		return hardLight(s, b)
+	})
+}
+
+// HardLight multiplies dst by the dark channels of src and screens it by the
+// light ones.
+func HardLight(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, hardLight)
+}
+
+// Darken keeps the darkest of each channel.
+func Darken(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, math.Min)
+}
+
+// Lighten keeps the lightest of each channel.
+func Lighten(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, math.Max)
+}
+
+// Difference subtracts the darkest of each channel from the lightest.
+func Difference(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, func(b, s float64) float64 {
+		return math.Abs(b - s)
+	})
+}
+
+// Exclusion is like Difference, with lower contrast.
+func Exclusion(dst, src color.RGBA64) color.RGBA64 {
+	return separable(dst, src, func(b, s float64) float64 {
+		return b + s - 2*b*s
+	})
+}
+
+func screen(b, s float64) float64 {
+	return b + s - b*s
+}
+
+func hardLight(b, s float64) float64 {
+	if s <= 0.5 {
+		return b * 2 * s
+	}
+	return screen(b, 2*s-1)
+}
+
+// separable blends src over dst with a blend mode that mixes each color
+// channel on its own, from 0.0 to 1.0, without alpha pre-multiplication. Where
+// the colors do not overlap, they are composited as with Over.
+func separable(dst, src color.RGBA64, mix func(b, s float64) float64) color.RGBA64 {
+	as, ab := float64(src.A)/0xFFFF, float64(dst.A)/0xFFFF
+	ch := func(s, d uint16) uint16 {
+		cs, cb := float64(s)/0xFFFF, float64(d)/0xFFFF
+		var us, ub float64
+		if as > 0 {
+			us = cs / as
+		}
+		if ab > 0 {
+			ub = cb / ab
+		}
+		v := cs*(1-ab) + cb*(1-as) + as*ab*mix(ub, us)
+		return uint16(math.Round(math.Max(0, math.Min(1, v)) * 0xFFFF))
+	}
+
+	return color.RGBA64{
+		R: ch(src.R, dst.R),
+		G: ch(src.G, dst.G),
+		B: ch(src.B, dst.B),
+		A: uint16(math.Round((as + ab - as*ab) * 0xFFFF)),
+	}
+}
@@ -0,0 +1,86 @@
+package blend
+
+import (
+	"image/color"
+	"testing"
+)
+
+var (
+	red       = color.RGBA64{0xFFFF, 0, 0, 0xFFFF}
+	blue      = color.RGBA64{0, 0, 0xFFFF, 0xFFFF}
+	halfBlue  = color.RGBA64{0, 0, 0x8000, 0x8000}
+	gray      = color.RGBA64{0x8000, 0x8000, 0x8000, 0xFFFF}
+	white     = color.RGBA64{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}
+	invisible = color.RGBA64{}
+)
+
+func TestPorterDuff(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Func
+		dst, src color.RGBA64
+		want     color.RGBA64
+	}{
+		{"clear", Clear, red, blue, invisible},
+		{"src", Src, red, halfBlue, halfBlue},
+		{"dst", Dst, red, halfBlue, red},
+		{"over", Over, red, halfBlue, color.RGBA64{0x7FFF, 0, 0x8000, 0xFFFF}},
+		{"over transparent", Over, red, invisible, red},
+		{"dst over", DstOver, halfBlue, red, color.RGBA64{0x7FFF, 0, 0x8000, 0xFFFF}},
+		{"src in", SrcIn, halfBlue, red, color.RGBA64{0x8000, 0, 0, 0x8000}},
+		{"src in transparent", SrcIn, invisible, red, invisible},
+		{"dst in", DstIn, red, halfBlue, color.RGBA64{0x8000, 0, 0, 0x8000}},
+		{"src out", SrcOut, halfBlue, red, color.RGBA64{0x7FFF, 0, 0, 0x7FFF}},
+		{"dst out", DstOut, red, halfBlue, color.RGBA64{0x7FFF, 0, 0, 0x7FFF}},
+		{"src atop", SrcAtop, red, halfBlue, color.RGBA64{0x7FFF, 0, 0x8000, 0xFFFF}},
+		{"src atop transparent", SrcAtop, invisible, red, invisible},
+		{"dst atop", DstAtop, halfBlue, red, color.RGBA64{0x7FFF, 0, 0x8000, 0xFFFF}},
+		{"xor", Xor, red, blue, invisible},
+		{"plus", Plus, red, blue, color.RGBA64{0xFFFF, 0, 0xFFFF, 0xFFFF}},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := ts.f(ts.dst, ts.src); got != ts.want {
+				t.Errorf("got: %#v, want: %#v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		f        Func
+		dst, src color.RGBA64
+		want     color.RGBA64
+	}{
+		{"multiply", Multiply, white, red, red},
+		{"multiply gray", Multiply, gray, gray, color.RGBA64{0x4000, 0x4000, 0x4000, 0xFFFF}},
+		{"screen", Screen, red, blue, color.RGBA64{0xFFFF, 0, 0xFFFF, 0xFFFF}},
+		{"overlay", Overlay, red, gray, color.RGBA64{0xFFFF, 0, 0, 0xFFFF}},
+		{"hard light", HardLight, red, blue, color.RGBA64{0, 0, 0xFFFF, 0xFFFF}},
+		{"darken", Darken, red, white, red},
+		{"lighten", Lighten, red, blue, color.RGBA64{0xFFFF, 0, 0xFFFF, 0xFFFF}},
+		{"difference", Difference, white, red, color.RGBA64{0, 0xFFFF, 0xFFFF, 0xFFFF}},
+		{"exclusion", Exclusion, white, red, color.RGBA64{0, 0xFFFF, 0xFFFF, 0xFFFF}},
+		{"over transparent", Multiply, invisible, halfBlue, halfBlue},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := ts.f(ts.dst, ts.src); got != ts.want {
+				t.Errorf("got: %#v, want: %#v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestLerp(t *testing.T) {
+	if got, want := Lerp(red, blue, 0.5), (color.RGBA64{0x8000, 0, 0x7FFF, 0xFFFF}); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+	if got, want := Lerp(red, blue, 1), blue; got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+}
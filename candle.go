@@ -0,0 +1,139 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Candle is a layer that flickers like the flame of a candle, for ambient
+// lighting. The brightness and the hue of the pixels wander randomly around a
+// warm color, smoothly over time and together with the pixels next to them.
+// Add it to a ring with AddLayer and run the ring (see Ring.Run) to animate
+// it. Its parameters are "color", "flicker" and "speed" (see Tunable).
+type Candle struct {
+	*Layer
+	params
+
+	mu      sync.Mutex
+	color   color.Color
+	flicker float64 // depth of the flicker, from 0 to 1
+	speed   float64 // noise units per second
+	t       float64 // time in noise space
+}
+
+// NewCandle creates a candle of a color, such as amber, with a flicker of 0.4
+// and a speed of 3.
+func NewCandle(c color.Color, options *LayerOptions) (*Candle, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	cd := &Candle{
+		Layer:   l,
+		color:   c,
+		flicker: 0.4,
+		speed:   3,
+	}
+	cd.params = params{specs: candleParams, get: cd.param, set: cd.setParam}
+	cd.paint()
+
+	return cd, nil
+}
+
+// SetColor sets the color of the flame at its brightest.
+func (c *Candle) SetColor(col color.Color) {
+	c.mu.Lock()
+	c.color = col
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetFlicker sets how deep the flame flickers, from 0.0 (steady) to 1.0 (down
+// to dark and red).
+func (c *Candle) SetFlicker(flicker float64) {
+	c.mu.Lock()
+	c.flicker = flicker
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetSpeed sets how fast the flame flickers.
+func (c *Candle) SetSpeed(speed float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.speed = speed
+}
+
+var candleParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#ff9329", Doc: "color of the flame at its brightest"},
+	{Name: "flicker", Type: ParamFloat, Default: "0.4", Doc: "depth of the flicker", Min: 0, Max: 1},
+	{Name: "speed", Type: ParamFloat, Default: "3", Doc: "how fast the flame flickers", Min: 0, Max: 20},
+}
+
+func (c *Candle) param(name string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch name {
+	case "color":
+		return c.color
+	case "flicker":
+		return c.flicker
+	case "speed":
+		return c.speed
+	}
+	return nil
+}
+
+func (c *Candle) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		c.SetColor(v.(color.Color))
+	case "flicker":
+		c.SetFlicker(v.(float64))
+	case "speed":
+		c.SetSpeed(v.(float64))
+	}
+}
+
+func (c *Candle) advance(dt time.Duration) {
+	c.mu.Lock()
+	c.t += c.speed * dt.Seconds()
+	c.mu.Unlock()
+
+	c.paint()
+	c.Layer.advance(dt)
+}
+
+// paint samples two noises along a circle, as Noise does, one for the
+// brightness and a slower one for the hue, which turns redder as the flame
+// dims.
+func (c *Candle) paint() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := c.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	base := toRGBA64(c.color)
+	red := base
+	red.G = uint16(float64(red.G) * 0.6)
+	red.B = uint16(float64(red.B) * 0.3)
+	for i := range l.pixels {
+		a := float64(i) * l.pixArc
+		x, y := math.Cos(a), math.Sin(a)
+		dim := c.flicker * (perlin(x+0.37, y+0.71, c.t) + 1) / 2
+		hue := c.flicker * (perlin(x+5.37, y+5.71, c.t/2) + 1) / 2
+		p := blendLerp(base, red, hue)
+		scale := func(v uint16) uint16 { return uint16(float64(v) * (1 - dim)) }
+		l.pixels[i] = color.RGBA64{R: scale(p.R), G: scale(p.G), B: scale(p.B), A: p.A}
+	}
+	l.update()
+}
@@ -0,0 +1,40 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestCandle(t *testing.T) {
+	amber := color.RGBA{0xFF, 0x93, 0x29, 0xFF}
+	c, err := NewCandle(amber, &LayerOptions{Resolution: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := false
+	for i := 0; i < 20; i++ {
+		before := c.pixel64(0)
+		c.advance(50 * time.Millisecond)
+		for j := 0; j < 12; j++ {
+			p := c.pixel64(j)
+			if p.A != 0xFFFF || p.R < 0xFFFF*6/10 || p.R < p.G || p.G < p.B {
+				t.Fatalf("pixel %d got: %#v, want: opaque warm color no dimmer than 60%%", j, p)
+			}
+		}
+		if c.pixel64(0) != before {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Errorf("got: steady flame, want: flicker")
+	}
+
+	c.SetFlicker(0)
+	for j := 0; j < 12; j++ {
+		if got, want := c.pixel64(j), toRGBA64(amber); got != want {
+			t.Errorf("pixel %d got: %#v, want: %#v", j, got, want)
+		}
+	}
+}
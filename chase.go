@@ -0,0 +1,160 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Chase is a layer with blocks of pixels separated by gaps that step around
+// the ring, like the marquee lights of a theater. The blocks are painted
+// through a palette along the ring. Add it to a ring with AddLayer and run the
+// ring (see Ring.Run) to animate it. Its parameters are "palette", "block" and
+// "gap" in pixels, and "speed" in pixels per second (see Tunable).
+type Chase struct {
+	*Layer
+	params
+
+	mu      sync.Mutex
+	palette Palette
+	block   int     // pixels per block
+	gap     int     // pixels between blocks
+	speed   float64 // pixels per second
+	offset  float64 // pixels stepped
+}
+
+// NewChase creates a chase of blocks of 3 pixels separated by 3 pixels,
+// painted through a palette, that steps 10 pixels per second.
+func NewChase(p Palette, options *LayerOptions) (*Chase, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Chase{
+		Layer:   l,
+		palette: p,
+		block:   3,
+		gap:     3,
+		speed:   10,
+	}
+	c.params = params{specs: chaseParams, get: c.param, set: c.setParam}
+	c.paint()
+
+	return c, nil
+}
+
+// SetPalette sets the palette the blocks are painted through.
+func (c *Chase) SetPalette(p Palette) {
+	c.mu.Lock()
+	c.palette = p
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetBlock sets the number of pixels of each block.
+func (c *Chase) SetBlock(n int) {
+	c.mu.Lock()
+	c.block = n
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetGap sets the number of pixels between blocks.
+func (c *Chase) SetGap(n int) {
+	c.mu.Lock()
+	c.gap = n
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetSpeed sets how many pixels per second the blocks step, in the direction
+// of the pixel indices.
+func (c *Chase) SetSpeed(pixels float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.speed = pixels
+}
+
+var chaseParams = []ParamSpec{
+	{Name: "palette", Type: ParamPalette, Default: "rainbow", Doc: "palette of the blocks along the ring"},
+	{Name: "block", Type: ParamInt, Default: "3", Doc: "pixels per block", Min: 1, Max: 64},
+	{Name: "gap", Type: ParamInt, Default: "3", Doc: "pixels between blocks", Min: 0, Max: 64},
+	{Name: "speed", Type: ParamFloat, Default: "10", Doc: "pixels per second", Min: -100, Max: 100},
+}
+
+func (c *Chase) param(name string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch name {
+	case "palette":
+		return c.palette
+	case "block":
+		return c.block
+	case "gap":
+		return c.gap
+	case "speed":
+		return c.speed
+	}
+	return nil
+}
+
+func (c *Chase) setParam(name string, v interface{}) {
+	switch name {
+	case "palette":
+		c.SetPalette(v.(Palette))
+	case "block":
+		c.SetBlock(v.(int))
+	case "gap":
+		c.SetGap(v.(int))
+	case "speed":
+		c.SetSpeed(v.(float64))
+	}
+}
+
+func (c *Chase) advance(dt time.Duration) {
+	c.mu.Lock()
+	c.offset += c.speed * dt.Seconds()
+	period := float64(c.block + c.gap)
+	if period > 0 {
+		c.offset = math.Mod(c.offset, period)
+	}
+	c.mu.Unlock()
+
+	c.paint()
+	c.Layer.advance(dt)
+}
+
+// paint paints the blocks at the whole pixels stepped, so they jump from pixel
+// to pixel. Each block takes the color of the palette where it starts.
+func (c *Chase) paint() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := c.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(l.pixels)
+	period := c.block + c.gap
+	step := int(math.Floor(c.offset))
+	for i := range l.pixels {
+		if period <= 0 {
+			l.pixels[i] = color.RGBA64{}
+			continue
+		}
+		j := mod(i-step, period)
+		if j >= c.block {
+			l.pixels[i] = color.RGBA64{}
+			continue
+		}
+		l.pixels[i] = c.palette.at64(float64(mod(i-j, n)) / float64(n))
+	}
+	l.update()
+}
@@ -0,0 +1,43 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestChase(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	c, err := NewChase(NewPalette(red, red), &LayerOptions{Resolution: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBlock(2)
+	c.SetGap(2)
+	c.SetSpeed(2)
+
+	tests := []struct {
+		dt   time.Duration
+		want string // lit pixels
+	}{
+		{0, "xx..xx.."},
+		{250 * time.Millisecond, "xx..xx.."},
+		{250 * time.Millisecond, ".xx..xx."},
+		{500 * time.Millisecond, "..xx..xx"},
+		{time.Second, "xx..xx.."},
+	}
+
+	for _, ts := range tests {
+		c.advance(ts.dt)
+		got := make([]byte, 8)
+		for i := range got {
+			got[i] = '.'
+			if _, _, _, a := c.Pixel(i).RGBA(); a > 0 {
+				got[i] = 'x'
+			}
+		}
+		if string(got) != ts.want {
+			t.Errorf("got: %s, want: %s", got, ts.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ring
+
+import "time"
+
+// Clock tells the time to the parts of a ring that change over time, such as
+// Run, so they can be tested with a fake clock (see ringtest.Clock).
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a ticker that sends the time every period d.
+	NewTicker(d time.Duration) Ticker
+	// After returns a channel that receives the time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker sends the time at regular intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// SystemClock returns the clock of the system, used by rings without a Clock
+// (see Options.Clock).
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// systemClock is the clock of the system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// clock returns the clock of the options, or the clock of the system if there
+// is none.
+func (o *Options) clock() Clock {
+	if o.Clock == nil {
+		return systemClock{}
+	}
+	return o.Clock
+}
+
+// Clock returns the clock of the ring (see Options.Clock), so the parts of
+// other packages that change over time along with the ring, such as the
+// packages ringsync, input and web, follow the same clock.
+func (r *Ring) Clock() Clock {
+	return r.opt.clock()
+}
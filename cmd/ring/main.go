@@ -0,0 +1,434 @@
+// Command ring controls an LED ring from the shell, to verify the wiring of
+// the LEDs without writing Go.
+//
+// Usage:
+//
+//	ring [flags] <command> [arguments]
+//
+// The commands are:
+//
+//	demo <effect> [param=value...] play an effect until interrupted, such as
+//	                               "ring demo comet speed=360"
+//	effects                        list the effects and their parameters
+//	preset [-file path] <name|index>
+//	                               play a saved preset until interrupted
+//	presets [-file path]           list the saved presets
+//	set [-pixel n] -color #rrggbb  set the color of a pixel, or all of them
+//	off                            turn off all the LEDs
+//	test-pattern                   show red, green, blue and white, then
+//	                               light each LED in order
+//	daemon [-socket path] [-group name] [-fps n] [-presets path]
+//	                               own the device and serve the requests of
+//	                               unprivileged programs (see package daemon)
+//	designer [-addr host:port] [-scenes dir] [-fps n]
+//	                               serve a web page to design scenes on the
+//	                               ring (see package web), only to this
+//	                               computer unless -addr is set
+//
+// The flags are:
+//
+//	-leds n         number of LEDs of the ring (default: 12)
+//	-gpio n         GPIO pin of the PWM output (default: 18)
+//	-brightness n   maximum brightness, from 0 to 255 (default: 64)
+//	-spi path       drive the LEDs through an SPI device, such as
+//	                /dev/spidev0.0, instead of PWM
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"image/color"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/daemon"
+	"github.com/cgxeiji/ring/web"
+)
+
+func main() {
+	leds := flag.Int("leds", 12, "number of LEDs of the ring")
+	gpio := flag.Int("gpio", 18, "GPIO pin of the PWM output")
+	brightness := flag.Int("brightness", 64, "maximum brightness, from 0 to 255")
+	spi := flag.String("spi", "", "drive the LEDs through an SPI device, such as /dev/spidev0.0")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	opt := &ring.Options{
+		LedCount:      *leds,
+		GpioPin:       *gpio,
+		MaxBrightness: *brightness,
+	}
+	if *spi != "" {
+		opt.Driver = ring.SPI(*spi)
+	}
+
+	if err := run(opt, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "ring:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `usage: ring [flags] <command> [arguments]
+
+commands:
+  demo <effect> [param=value...] play an effect until interrupted, such as
+                                 "ring demo comet speed=360"
+  effects                        list the effects and their parameters
+  preset [-file path] <name|index>
+                                 play a saved preset until interrupted
+  presets [-file path]           list the saved presets
+  set [-pixel n] -color #rrggbb  set the color of a pixel, or all of them
+  off                            turn off all the LEDs
+  test-pattern                   show red, green, blue and white, then light
+                                 each LED in order
+  daemon [-socket path] [-group name] [-fps n] [-presets path]
+                                 own the device and serve the requests of
+                                 unprivileged programs
+  designer [-addr host:port] [-scenes dir] [-fps n]
+                                 serve a web page to design scenes on the ring,
+                                 only to this computer unless -addr is set
+
+flags:
+`)
+	flag.PrintDefaults()
+}
+
+func run(opt *ring.Options, cmd string, args []string) error {
+	switch cmd {
+	case "demo":
+		if len(args) < 1 {
+			return fmt.Errorf("usage: ring demo <effect> [param=value...]")
+		}
+		return demo(opt, strings.Join(args, " "))
+	case "effects":
+		listEffects()
+		return nil
+	case "preset":
+		return playPreset(opt, args)
+	case "presets":
+		return listPresets(args)
+	case "set":
+		return set(opt, args)
+	case "off":
+		r, err := ring.New(opt)
+		if err != nil {
+			return err
+		}
+		r.Close()
+		return nil
+	case "test-pattern":
+		return testPattern(opt)
+	case "daemon":
+		return runDaemon(opt, args)
+	case "designer":
+		return runDesigner(opt, args)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// interruptible returns a context that is canceled on an interrupt or
+// termination signal, such as sent by systemd to stop the daemon.
+func interruptible() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sig)
+	}()
+
+	return ctx, cancel
+}
+
+func demo(opt *ring.Options, effect string) error {
+	name, args, err := ring.ParseEffect(effect)
+	if err != nil {
+		return err
+	}
+	e, err := ring.NewEffect(name, args, &ring.LayerOptions{Resolution: opt.LedCount})
+	if err != nil {
+		return err
+	}
+
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	r.AddEffect(e)
+
+	ctx, cancel := interruptible()
+	defer cancel()
+	if err := r.Run(ctx, 60); err != context.Canceled {
+		return err
+	}
+
+	return nil
+}
+
+func listEffects() {
+	for _, spec := range ring.DefaultRegistry.Effects() {
+		fmt.Printf("%s: %s\n", spec.Name, spec.Doc)
+		for _, p := range spec.Params {
+			fmt.Printf("  %s=%s (%v): %s\n", p.Name, p.Default, p.Type, p.Doc)
+		}
+	}
+}
+
+// presetFlags adds the flag of the file of the presets to a flag set.
+func presetFlags(fs *flag.FlagSet) *string {
+	return fs.String("file", "presets.json", "file of the presets")
+}
+
+func playPreset(opt *ring.Options, args []string) error {
+	fs := flag.NewFlagSet("preset", flag.ContinueOnError)
+	file := presetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: ring preset [-file path] <name|index>")
+	}
+	presets, err := ring.OpenPresets(*file)
+	if err != nil {
+		return err
+	}
+	p, err := presets.Get(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if _, err := p.AddTo(r); err != nil {
+		return err
+	}
+
+	ctx, cancel := interruptible()
+	defer cancel()
+	if err := r.Run(ctx, 60); err != context.Canceled {
+		return err
+	}
+
+	return nil
+}
+
+func listPresets(args []string) error {
+	fs := flag.NewFlagSet("presets", flag.ContinueOnError)
+	file := presetFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	presets, err := ring.OpenPresets(*file)
+	if err != nil {
+		return err
+	}
+	for i, p := range presets.Presets() {
+		fmt.Printf("%d: %s\n", i, p.Name)
+		for _, l := range p.Layers {
+			fmt.Printf("  %s", l.Effect)
+			for _, name := range sortedKeys(l.Params) {
+				fmt.Printf(" %s=%s", name, l.Params[name])
+			}
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func set(opt *ring.Options, args []string) error {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	pixel := fs.Int("pixel", -1, "pixel to set, or -1 for all the pixels")
+	hex := fs.String("color", "", "color of the pixel, as #rgb, #rrggbb or a name such as orange")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := ring.ParseColor(*hex)
+	if err != nil {
+		return err
+	}
+	if *pixel >= opt.LedCount {
+		return fmt.Errorf("pixel %d out of range", *pixel)
+	}
+
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: opt.LedCount})
+	if err != nil {
+		return err
+	}
+	if *pixel < 0 {
+		l.SetAll(c)
+	} else {
+		l.SetPixel(*pixel, c)
+	}
+	r.AddLayer(l)
+
+	// The ring is not closed, so the LEDs keep the colors after exiting.
+	return r.Render()
+}
+
+func testPattern(opt *ring.Options) error {
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: opt.LedCount})
+	if err != nil {
+		return err
+	}
+	r.AddLayer(l)
+
+	for _, c := range []color.Color{
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+		color.RGBA{0x00, 0xFF, 0x00, 0xFF},
+		color.RGBA{0x00, 0x00, 0xFF, 0xFF},
+		color.White,
+	} {
+		l.SetAll(c)
+		if err := r.Render(); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+	}
+
+	for i := 0; i < opt.LedCount; i++ {
+		l.SetAll(color.Transparent)
+		l.SetPixel(i, color.White)
+		if err := r.Render(); err != nil {
+			return err
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	return nil
+}
+
+func runDaemon(opt *ring.Options, args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	socket := fs.String("socket", "/run/ring.sock", "path of the Unix socket")
+	group := fs.String("group", "", "group allowed to use the socket, besides root")
+	fps := fs.Float64("fps", 60, "frames per second")
+	file := fs.String("presets", "", "file of the presets saved and recalled by clients")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var presets *ring.PresetStore
+	if *file != "" {
+		var err error
+		if presets, err = ring.OpenPresets(*file); err != nil {
+			return err
+		}
+	}
+
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s := daemon.NewServer(r)
+	if presets != nil {
+		s.SetPresets(presets)
+	}
+	if *group != "" {
+		g, err := user.LookupGroup(*group)
+		if err != nil {
+			return err
+		}
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			return err
+		}
+		s.SetGroup(gid)
+	}
+
+	ctx, cancel := interruptible()
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.ListenAndServe(*socket)
+		cancel()
+	}()
+	err = r.Run(ctx, *fps)
+	s.Close()
+	if lerr := <-errc; lerr != nil {
+		return lerr
+	}
+	if err != context.Canceled {
+		return err
+	}
+
+	return nil
+}
+
+func runDesigner(opt *ring.Options, args []string) error {
+	fs := flag.NewFlagSet("designer", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address of the web server")
+	scenes := fs.String("scenes", ".", "directory where scenes are saved")
+	fps := fs.Float64("fps", 60, "frames per second")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	r, err := ring.New(opt)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	fmt.Printf("designer at http://%s/\n", l.Addr())
+	go http.Serve(l, web.NewDesigner(r, *scenes))
+
+	ctx, cancel := interruptible()
+	defer cancel()
+	if err := r.Run(ctx, *fps); err != context.Canceled {
+		return err
+	}
+
+	return nil
+}
@@ -1,58 +1,154 @@
 package ring
 
 import (
+	"fmt"
 	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cgxeiji/ring/blend"
 )
 
+// ParseColor parses a color written as a CSS named color, such as "orange"
+// (see ColorByName), or in hexadecimal as "rgb", "rrggbb" or "rrggbbaa", with
+// or without a leading "#". Names are not case sensitive.
+func ParseColor(s string) (color.Color, error) {
+	if c, ok := ColorByName(s); ok {
+		return c, nil
+	}
+
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return nil, fmt.Errorf("ring: invalid color %q", s)
+	}
+
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// toRGBA64 converts any color to the alpha pre-multiplied 16-bit color used
+// internally for blending.
+func toRGBA64(c color.Color) color.RGBA64 {
+	r, g, b, a := c.RGBA()
+
+	return color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)}
+}
+
 // serialize transforms color information to uint32 with the shape 0x00RRGGBB
 func serialize(c color.Color) uint32 {
-	r, g, b, _ := c.RGBA()
+	return serialize64(toRGBA64(c))
+}
 
-	return ((r >> 8) << 16) |
-		((g >> 8) << 8) |
-		(b >> 8)
+// serialize64 is like serialize, but avoids the overhead of the color.Color
+// interface.
+func serialize64(c color.RGBA64) uint32 {
+	return (uint32(c.R>>8) << 16) |
+		(uint32(c.G>>8) << 8) |
+		uint32(c.B>>8)
 }
 
-// blendOver blends multiple colors using the over operator and returns an
-// alpha pre-multiplied color. The first color is considered to be at the
-// bottom and the last color is considered to be at the top.
-func blendOver(cs ...color.Color) (blend *color.RGBA) {
-	over := func(a, b, delta uint32) uint8 {
-		return uint8((a + b*delta/0xFFFF) >> 8)
+// deserialize transforms a color with the shape 0x00RRGGBB to an opaque color.
+func deserialize(c uint32) color.RGBA64 {
+	return color.RGBA64{
+		R: uint16(c>>16&0xFF) * 0x101,
+		G: uint16(c>>8&0xFF) * 0x101,
+		B: uint16(c&0xFF) * 0x101,
+		A: 0xFFFF,
 	}
-	blend = &color.RGBA{0, 0, 0, 0}
-	for _, c := range cs {
-		r, g, b, a := c.RGBA()
-		bR, bG, bB, bA := blend.RGBA()
-		delta := (0xFFFF - a)
+}
+
+// dither is like serialize64, but carries the quantization error of each color
+// channel over to the next frame in errs, so that on average the output
+// matches the 16-bit color.
+func dither(c color.RGBA64, errs []uint16) uint32 {
+	quantize := func(v uint16, err *uint16) uint32 {
+		out := uint32(v >> 8)
+		sum := *err + v&0xFF
+		if sum >= 0x100 && out < 0xFF {
+			out++
+			sum -= 0x100
+		}
+		*err = sum
 
-		blend.R = over(r, bR, delta)
-		blend.G = over(g, bG, delta)
-		blend.B = over(b, bB, delta)
-		blend.A = over(a, bA, delta)
+		return out
 	}
 
-	return blend
+	return quantize(c.R, &errs[0])<<16 |
+		quantize(c.G, &errs[1])<<8 |
+		quantize(c.B, &errs[2])
 }
 
-// blendLerp blends two colors by linearly interpolating between them given the
-// amount l: (0.0 to 1.0) -> (a to b).
-func blendLerp(a, b color.Color, l float64) (blend *color.RGBA) {
-	lerp := func(a, b, l uint32) uint8 {
-		return uint8((a - (a-b)*l/0xFFFF) >> 8)
+// scaleBrightness maps the channels of an opaque color from the full range to
+// the range between min and max, which go from 0 to 255. Transparency is
+// treated as black.
+func scaleBrightness(c color.RGBA64, min, max int) color.RGBA64 {
+	scale := func(v uint16) uint16 {
+		return uint16(min*0x101 + int(v)*(max-min)/0xFF)
 	}
 
-	aR, aG, aB, aA := a.RGBA()
-	bR, bG, bB, bA := b.RGBA()
+	return color.RGBA64{
+		R: scale(c.R),
+		G: scale(c.G),
+		B: scale(c.B),
+		A: 0xFFFF,
+	}
+}
+
+// ColorScale scales each color channel of an LED, from 0.0 (off) to 1.0 (no
+// change).
+type ColorScale struct {
+	R, G, B float64
+}
 
-	l16 := uint32(l * 0xFFFF)
+// calibrate scales the color channels of an opaque color.
+func calibrate(c color.RGBA64, s ColorScale) color.RGBA64 {
+	scale := func(v uint16, f float64) uint16 {
+		return uint16(math.Max(0, math.Min(0xFFFF, float64(v)*f)))
+	}
 
-	blend = &color.RGBA{
-		R: lerp(aR, bR, l16),
-		G: lerp(aG, bG, l16),
-		B: lerp(aB, bB, l16),
-		A: lerp(aA, bA, l16),
+	return color.RGBA64{
+		R: scale(c.R, s.R),
+		G: scale(c.G, s.G),
+		B: scale(c.B, s.B),
+		A: c.A,
 	}
+}
+
+// fade scales the alpha of a color by opacity, from 0.0 to 1.0.
+func fade(c color.RGBA64, opacity float64) color.RGBA64 {
+	o := uint32(opacity * 0xFFFF)
+	scale := func(v uint16) uint16 {
+		return uint16(uint32(v) * o / 0xFFFF)
+	}
+
+	return color.RGBA64{
+		R: scale(c.R),
+		G: scale(c.G),
+		B: scale(c.B),
+		A: scale(c.A),
+	}
+}
 
-	return blend
+// blendOver blends multiple colors using the over operator and returns an
+// alpha pre-multiplied color. The first color is considered to be at the
+// bottom and the last color is considered to be at the top.
+func blendOver(cs ...color.RGBA64) (out color.RGBA64) {
+	for _, c := range cs {
+		out = blend.Over(out, c)
+	}
+
+	return out
+}
+
+// blendLerp blends two colors by linearly interpolating between them given the
+// amount l: (0.0 to 1.0) -> (a to b).
+func blendLerp(a, b color.RGBA64, l float64) color.RGBA64 {
+	return blend.Lerp(a, b, l)
 }
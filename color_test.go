@@ -119,7 +119,11 @@ func TestBlendOver(t *testing.T) {
 
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
-			got := *blendOver(ts.colors...)
+			cs := make([]color.RGBA64, len(ts.colors))
+			for i, c := range ts.colors {
+				cs[i] = toRGBA64(c)
+			}
+			got := color.RGBAModel.Convert(blendOver(cs...))
 			if got != ts.want {
 				t.Errorf("got: %#v, want: %#v", got, ts.want)
 			}
@@ -167,7 +171,117 @@ func TestBlendLerp(t *testing.T) {
 
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
-			got := *blendLerp(ts.colorA, ts.colorB, ts.l)
+			got := color.RGBAModel.Convert(blendLerp(toRGBA64(ts.colorA), toRGBA64(ts.colorB), ts.l))
+			if got != ts.want {
+				t.Errorf("got: %v, want: %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestScaleBrightness(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    color.Color
+		min, max int
+		want     uint32
+	}{
+		{
+			"full range",
+			color.RGBA{0xFF, 0x80, 0x00, 0xFF},
+			0, 255,
+			0xFF8000,
+		},
+		{
+			"max",
+			color.White,
+			0, 128,
+			0x808080,
+		},
+		{
+			"min",
+			color.Transparent,
+			10, 128,
+			0x0A0A0A,
+		},
+		{
+			"range",
+			color.RGBA{0xFF, 0x80, 0x00, 0xFF},
+			10, 138,
+			0x8A4A0A,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			got := serialize64(scaleBrightness(toRGBA64(ts.color), ts.min, ts.max))
+			if got != ts.want {
+				t.Errorf("got: %#x, want: %#x", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestDither(t *testing.T) {
+	c := color.RGBA64{0x1040, 0x0080, 0x0000, 0xFFFF}
+	errs := make([]uint16, 3)
+	want := []uint32{0x100000, 0x100100, 0x100000, 0x110100}
+	for i, w := range want {
+		if got := dither(c, errs); got != w {
+			t.Errorf("frame %d got: %#x, want: %#x", i, got, w)
+		}
+	}
+}
+
+func TestBlendPrecision(t *testing.T) {
+	black := color.RGBA64{0x0000, 0x0000, 0x0000, 0xFFFF}
+	dim := color.RGBA64{0x0100, 0x0100, 0x0100, 0xFFFF}
+
+	// 8-bit math would truncate this to zero.
+	if got := blendLerp(black, dim, 0.5); got.R < 0x007F || got.R > 0x0080 {
+		t.Errorf("lerp got: %#04x, want: ~0x0080", got.R)
+	}
+
+	// Blending many translucent layers should not lose the fractional part
+	// of each step.
+	veil := color.RGBA64{0x0100, 0x0100, 0x0100, 0x0100}
+	got := blendOver(black, veil, veil, veil, veil)
+	if got.R < 0x03F0 || got.R > 0x0400 {
+		t.Errorf("over got: %#04x, want: ~0x0400", got.R)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want color.Color
+	}{
+		{"#f80", color.NRGBA{0xFF, 0x88, 0x00, 0xFF}},
+		{"#ff8800", color.NRGBA{0xFF, 0x88, 0x00, 0xFF}},
+		{"#ff880080", color.NRGBA{0xFF, 0x88, 0x00, 0x80}},
+		{"ff8800", color.NRGBA{0xFF, 0x88, 0x00, 0xFF}},
+		{" F80 ", color.NRGBA{0xFF, 0x88, 0x00, 0xFF}},
+		{"##ff8800", nil},
+		{"#ff88", nil},
+		{"#gg8800", nil},
+		{"orange", color.RGBA{0xFF, 0xA5, 0x00, 0xFF}},
+		{" RebeccaPurple", color.RGBA{0x66, 0x33, 0x99, 0xFF}},
+		{"transparent", color.RGBA{}},
+		{"blurple", nil},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.hex, func(t *testing.T) {
+			got, err := ParseColor(ts.hex)
+			if ts.want == nil {
+				if err == nil {
+					t.Errorf("got: %v, want: error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got != ts.want {
 				t.Errorf("got: %v, want: %v", got, ts.want)
 			}
@@ -0,0 +1,166 @@
+package ring
+
+import (
+	"image/color"
+	"strings"
+)
+
+// ColorByName returns the CSS named color, including transparent, with a
+// name, which is not case sensitive (see ParseColor).
+func ColorByName(name string) (color.Color, bool) {
+	c, ok := colors[strings.ToLower(strings.TrimSpace(name))]
+	return c, ok
+}
+
+// colors are the CSS named colors, including transparent, by lowercase name.
+var colors = map[string]color.Color{
+	"aliceblue":            color.RGBA{0xF0, 0xF8, 0xFF, 0xFF},
+	"antiquewhite":         color.RGBA{0xFA, 0xEB, 0xD7, 0xFF},
+	"aqua":                 color.RGBA{0x00, 0xFF, 0xFF, 0xFF},
+	"aquamarine":           color.RGBA{0x7F, 0xFF, 0xD4, 0xFF},
+	"azure":                color.RGBA{0xF0, 0xFF, 0xFF, 0xFF},
+	"beige":                color.RGBA{0xF5, 0xF5, 0xDC, 0xFF},
+	"bisque":               color.RGBA{0xFF, 0xE4, 0xC4, 0xFF},
+	"black":                color.RGBA{0x00, 0x00, 0x00, 0xFF},
+	"blanchedalmond":       color.RGBA{0xFF, 0xEB, 0xCD, 0xFF},
+	"blue":                 color.RGBA{0x00, 0x00, 0xFF, 0xFF},
+	"blueviolet":           color.RGBA{0x8A, 0x2B, 0xE2, 0xFF},
+	"brown":                color.RGBA{0xA5, 0x2A, 0x2A, 0xFF},
+	"burlywood":            color.RGBA{0xDE, 0xB8, 0x87, 0xFF},
+	"cadetblue":            color.RGBA{0x5F, 0x9E, 0xA0, 0xFF},
+	"chartreuse":           color.RGBA{0x7F, 0xFF, 0x00, 0xFF},
+	"chocolate":            color.RGBA{0xD2, 0x69, 0x1E, 0xFF},
+	"coral":                color.RGBA{0xFF, 0x7F, 0x50, 0xFF},
+	"cornflowerblue":       color.RGBA{0x64, 0x95, 0xED, 0xFF},
+	"cornsilk":             color.RGBA{0xFF, 0xF8, 0xDC, 0xFF},
+	"crimson":              color.RGBA{0xDC, 0x14, 0x3C, 0xFF},
+	"cyan":                 color.RGBA{0x00, 0xFF, 0xFF, 0xFF},
+	"darkblue":             color.RGBA{0x00, 0x00, 0x8B, 0xFF},
+	"darkcyan":             color.RGBA{0x00, 0x8B, 0x8B, 0xFF},
+	"darkgoldenrod":        color.RGBA{0xB8, 0x86, 0x0B, 0xFF},
+	"darkgray":             color.RGBA{0xA9, 0xA9, 0xA9, 0xFF},
+	"darkgreen":            color.RGBA{0x00, 0x64, 0x00, 0xFF},
+	"darkgrey":             color.RGBA{0xA9, 0xA9, 0xA9, 0xFF},
+	"darkkhaki":            color.RGBA{0xBD, 0xB7, 0x6B, 0xFF},
+	"darkmagenta":          color.RGBA{0x8B, 0x00, 0x8B, 0xFF},
+	"darkolivegreen":       color.RGBA{0x55, 0x6B, 0x2F, 0xFF},
+	"darkorange":           color.RGBA{0xFF, 0x8C, 0x00, 0xFF},
+	"darkorchid":           color.RGBA{0x99, 0x32, 0xCC, 0xFF},
+	"darkred":              color.RGBA{0x8B, 0x00, 0x00, 0xFF},
+	"darksalmon":           color.RGBA{0xE9, 0x96, 0x7A, 0xFF},
+	"darkseagreen":         color.RGBA{0x8F, 0xBC, 0x8F, 0xFF},
+	"darkslateblue":        color.RGBA{0x48, 0x3D, 0x8B, 0xFF},
+	"darkslategray":        color.RGBA{0x2F, 0x4F, 0x4F, 0xFF},
+	"darkslategrey":        color.RGBA{0x2F, 0x4F, 0x4F, 0xFF},
+	"darkturquoise":        color.RGBA{0x00, 0xCE, 0xD1, 0xFF},
+	"darkviolet":           color.RGBA{0x94, 0x00, 0xD3, 0xFF},
+	"deeppink":             color.RGBA{0xFF, 0x14, 0x93, 0xFF},
+	"deepskyblue":          color.RGBA{0x00, 0xBF, 0xFF, 0xFF},
+	"dimgray":              color.RGBA{0x69, 0x69, 0x69, 0xFF},
+	"dimgrey":              color.RGBA{0x69, 0x69, 0x69, 0xFF},
+	"dodgerblue":           color.RGBA{0x1E, 0x90, 0xFF, 0xFF},
+	"firebrick":            color.RGBA{0xB2, 0x22, 0x22, 0xFF},
+	"floralwhite":          color.RGBA{0xFF, 0xFA, 0xF0, 0xFF},
+	"forestgreen":          color.RGBA{0x22, 0x8B, 0x22, 0xFF},
+	"fuchsia":              color.RGBA{0xFF, 0x00, 0xFF, 0xFF},
+	"gainsboro":            color.RGBA{0xDC, 0xDC, 0xDC, 0xFF},
+	"ghostwhite":           color.RGBA{0xF8, 0xF8, 0xFF, 0xFF},
+	"gold":                 color.RGBA{0xFF, 0xD7, 0x00, 0xFF},
+	"goldenrod":            color.RGBA{0xDA, 0xA5, 0x20, 0xFF},
+	"gray":                 color.RGBA{0x80, 0x80, 0x80, 0xFF},
+	"green":                color.RGBA{0x00, 0x80, 0x00, 0xFF},
+	"greenyellow":          color.RGBA{0xAD, 0xFF, 0x2F, 0xFF},
+	"grey":                 color.RGBA{0x80, 0x80, 0x80, 0xFF},
+	"honeydew":             color.RGBA{0xF0, 0xFF, 0xF0, 0xFF},
+	"hotpink":              color.RGBA{0xFF, 0x69, 0xB4, 0xFF},
+	"indianred":            color.RGBA{0xCD, 0x5C, 0x5C, 0xFF},
+	"indigo":               color.RGBA{0x4B, 0x00, 0x82, 0xFF},
+	"ivory":                color.RGBA{0xFF, 0xFF, 0xF0, 0xFF},
+	"khaki":                color.RGBA{0xF0, 0xE6, 0x8C, 0xFF},
+	"lavender":             color.RGBA{0xE6, 0xE6, 0xFA, 0xFF},
+	"lavenderblush":        color.RGBA{0xFF, 0xF0, 0xF5, 0xFF},
+	"lawngreen":            color.RGBA{0x7C, 0xFC, 0x00, 0xFF},
+	"lemonchiffon":         color.RGBA{0xFF, 0xFA, 0xCD, 0xFF},
+	"lightblue":            color.RGBA{0xAD, 0xD8, 0xE6, 0xFF},
+	"lightcoral":           color.RGBA{0xF0, 0x80, 0x80, 0xFF},
+	"lightcyan":            color.RGBA{0xE0, 0xFF, 0xFF, 0xFF},
+	"lightgoldenrodyellow": color.RGBA{0xFA, 0xFA, 0xD2, 0xFF},
+	"lightgray":            color.RGBA{0xD3, 0xD3, 0xD3, 0xFF},
+	"lightgreen":           color.RGBA{0x90, 0xEE, 0x90, 0xFF},
+	"lightgrey":            color.RGBA{0xD3, 0xD3, 0xD3, 0xFF},
+	"lightpink":            color.RGBA{0xFF, 0xB6, 0xC1, 0xFF},
+	"lightsalmon":          color.RGBA{0xFF, 0xA0, 0x7A, 0xFF},
+	"lightseagreen":        color.RGBA{0x20, 0xB2, 0xAA, 0xFF},
+	"lightskyblue":         color.RGBA{0x87, 0xCE, 0xFA, 0xFF},
+	"lightslategray":       color.RGBA{0x77, 0x88, 0x99, 0xFF},
+	"lightslategrey":       color.RGBA{0x77, 0x88, 0x99, 0xFF},
+	"lightsteelblue":       color.RGBA{0xB0, 0xC4, 0xDE, 0xFF},
+	"lightyellow":          color.RGBA{0xFF, 0xFF, 0xE0, 0xFF},
+	"lime":                 color.RGBA{0x00, 0xFF, 0x00, 0xFF},
+	"limegreen":            color.RGBA{0x32, 0xCD, 0x32, 0xFF},
+	"linen":                color.RGBA{0xFA, 0xF0, 0xE6, 0xFF},
+	"magenta":              color.RGBA{0xFF, 0x00, 0xFF, 0xFF},
+	"maroon":               color.RGBA{0x80, 0x00, 0x00, 0xFF},
+	"mediumaquamarine":     color.RGBA{0x66, 0xCD, 0xAA, 0xFF},
+	"mediumblue":           color.RGBA{0x00, 0x00, 0xCD, 0xFF},
+	"mediumorchid":         color.RGBA{0xBA, 0x55, 0xD3, 0xFF},
+	"mediumpurple":         color.RGBA{0x93, 0x70, 0xDB, 0xFF},
+	"mediumseagreen":       color.RGBA{0x3C, 0xB3, 0x71, 0xFF},
+	"mediumslateblue":      color.RGBA{0x7B, 0x68, 0xEE, 0xFF},
+	"mediumspringgreen":    color.RGBA{0x00, 0xFA, 0x9A, 0xFF},
+	"mediumturquoise":      color.RGBA{0x48, 0xD1, 0xCC, 0xFF},
+	"mediumvioletred":      color.RGBA{0xC7, 0x15, 0x85, 0xFF},
+	"midnightblue":         color.RGBA{0x19, 0x19, 0x70, 0xFF},
+	"mintcream":            color.RGBA{0xF5, 0xFF, 0xFA, 0xFF},
+	"mistyrose":            color.RGBA{0xFF, 0xE4, 0xE1, 0xFF},
+	"moccasin":             color.RGBA{0xFF, 0xE4, 0xB5, 0xFF},
+	"navajowhite":          color.RGBA{0xFF, 0xDE, 0xAD, 0xFF},
+	"navy":                 color.RGBA{0x00, 0x00, 0x80, 0xFF},
+	"oldlace":              color.RGBA{0xFD, 0xF5, 0xE6, 0xFF},
+	"olive":                color.RGBA{0x80, 0x80, 0x00, 0xFF},
+	"olivedrab":            color.RGBA{0x6B, 0x8E, 0x23, 0xFF},
+	"orange":               color.RGBA{0xFF, 0xA5, 0x00, 0xFF},
+	"orangered":            color.RGBA{0xFF, 0x45, 0x00, 0xFF},
+	"orchid":               color.RGBA{0xDA, 0x70, 0xD6, 0xFF},
+	"palegoldenrod":        color.RGBA{0xEE, 0xE8, 0xAA, 0xFF},
+	"palegreen":            color.RGBA{0x98, 0xFB, 0x98, 0xFF},
+	"paleturquoise":        color.RGBA{0xAF, 0xEE, 0xEE, 0xFF},
+	"palevioletred":        color.RGBA{0xDB, 0x70, 0x93, 0xFF},
+	"papayawhip":           color.RGBA{0xFF, 0xEF, 0xD5, 0xFF},
+	"peachpuff":            color.RGBA{0xFF, 0xDA, 0xB9, 0xFF},
+	"peru":                 color.RGBA{0xCD, 0x85, 0x3F, 0xFF},
+	"pink":                 color.RGBA{0xFF, 0xC0, 0xCB, 0xFF},
+	"plum":                 color.RGBA{0xDD, 0xA0, 0xDD, 0xFF},
+	"powderblue":           color.RGBA{0xB0, 0xE0, 0xE6, 0xFF},
+	"purple":               color.RGBA{0x80, 0x00, 0x80, 0xFF},
+	"rebeccapurple":        color.RGBA{0x66, 0x33, 0x99, 0xFF},
+	"red":                  color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+	"rosybrown":            color.RGBA{0xBC, 0x8F, 0x8F, 0xFF},
+	"royalblue":            color.RGBA{0x41, 0x69, 0xE1, 0xFF},
+	"saddlebrown":          color.RGBA{0x8B, 0x45, 0x13, 0xFF},
+	"salmon":               color.RGBA{0xFA, 0x80, 0x72, 0xFF},
+	"sandybrown":           color.RGBA{0xF4, 0xA4, 0x60, 0xFF},
+	"seagreen":             color.RGBA{0x2E, 0x8B, 0x57, 0xFF},
+	"seashell":             color.RGBA{0xFF, 0xF5, 0xEE, 0xFF},
+	"sienna":               color.RGBA{0xA0, 0x52, 0x2D, 0xFF},
+	"silver":               color.RGBA{0xC0, 0xC0, 0xC0, 0xFF},
+	"skyblue":              color.RGBA{0x87, 0xCE, 0xEB, 0xFF},
+	"slateblue":            color.RGBA{0x6A, 0x5A, 0xCD, 0xFF},
+	"slategray":            color.RGBA{0x70, 0x80, 0x90, 0xFF},
+	"slategrey":            color.RGBA{0x70, 0x80, 0x90, 0xFF},
+	"snow":                 color.RGBA{0xFF, 0xFA, 0xFA, 0xFF},
+	"springgreen":          color.RGBA{0x00, 0xFF, 0x7F, 0xFF},
+	"steelblue":            color.RGBA{0x46, 0x82, 0xB4, 0xFF},
+	"tan":                  color.RGBA{0xD2, 0xB4, 0x8C, 0xFF},
+	"teal":                 color.RGBA{0x00, 0x80, 0x80, 0xFF},
+	"thistle":              color.RGBA{0xD8, 0xBF, 0xD8, 0xFF},
+	"tomato":               color.RGBA{0xFF, 0x63, 0x47, 0xFF},
+	"transparent":          color.RGBA{},
+	"turquoise":            color.RGBA{0x40, 0xE0, 0xD0, 0xFF},
+	"violet":               color.RGBA{0xEE, 0x82, 0xEE, 0xFF},
+	"wheat":                color.RGBA{0xF5, 0xDE, 0xB3, 0xFF},
+	"white":                color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	"whitesmoke":           color.RGBA{0xF5, 0xF5, 0xF5, 0xFF},
+	"yellow":               color.RGBA{0xFF, 0xFF, 0x00, 0xFF},
+	"yellowgreen":          color.RGBA{0x9A, 0xCD, 0x32, 0xFF},
+}
@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Comet is a layer with a comet that circles the ring, with a tail that fades
+// out behind it. Add it to a ring with AddLayer and run the ring (see Ring.Run)
+// to animate it. Its parameters are "color", and "speed" and "length" in
+// degrees (see Tunable).
+type Comet struct {
+	*Layer
+	params
+
+	mu     sync.Mutex
+	sprite *Sprite
+	color  color.Color
+	speed  float64 // angular velocity in radians per second
+	length float64 // angular length in radians
+}
+
+// NewComet creates a comet of a color, with a length of a quarter of the ring,
+// that circles the ring in 2 seconds.
+func NewComet(c color.Color, options *LayerOptions) (*Comet, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &Comet{
+		Layer:  l,
+		sprite: NewSprite(math.Pi/2, c),
+		color:  c,
+		length: math.Pi / 2,
+	}
+	cm.params = params{specs: cometParams, get: cm.param, set: cm.setParam}
+	cm.SetSpeed(math.Pi)
+
+	return cm, nil
+}
+
+// SetColor sets the color of the head of the comet.
+func (c *Comet) SetColor(col color.Color) {
+	c.mu.Lock()
+	c.color = col
+	c.tail()
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetLength sets the angular length of the comet, with its tail (in radians).
+func (c *Comet) SetLength(angle float64) {
+	c.mu.Lock()
+	c.length = angle
+	c.sprite.SetWidth(angle)
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// SetSpeed sets the angular velocity of the comet (in radians per second), in
+// the direction of the pixel indices.
+func (c *Comet) SetSpeed(velocity float64) {
+	c.mu.Lock()
+	c.speed = velocity
+	c.sprite.SetVelocity(velocity)
+	c.tail()
+	c.mu.Unlock()
+
+	c.paint()
+}
+
+// tail paints the sprite from transparent to the color of the comet, in the
+// direction of its motion. It must be called with mu held.
+func (c *Comet) tail() {
+	if c.speed < 0 {
+		c.sprite.SetPalette(NewPalette(c.color, color.Transparent))
+	} else {
+		c.sprite.SetPalette(NewPalette(color.Transparent, c.color))
+	}
+}
+
+var cometParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#0080ff", Doc: "color of the head"},
+	{Name: "speed", Type: ParamFloat, Default: "180", Doc: "degrees per second", Min: -720, Max: 720},
+	{Name: "length", Type: ParamFloat, Default: "90", Doc: "length with the tail, in degrees", Min: 0, Max: 360},
+}
+
+func (c *Comet) param(name string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch name {
+	case "color":
+		return c.color
+	case "speed":
+		return c.speed * 180 / math.Pi
+	case "length":
+		return c.length * 180 / math.Pi
+	}
+	return nil
+}
+
+func (c *Comet) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		c.SetColor(v.(color.Color))
+	case "speed":
+		c.SetSpeed(radians(v.(float64)))
+	case "length":
+		c.SetLength(radians(v.(float64)))
+	}
+}
+
+func (c *Comet) advance(dt time.Duration) {
+	c.sprite.Tick(dt)
+	c.paint()
+	c.Layer.advance(dt)
+}
+
+func (c *Comet) paint() {
+	c.SetAll(color.Transparent)
+	c.sprite.Draw(c.Layer)
+}
@@ -0,0 +1,232 @@
+package ring
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	ws2811 "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// defaultGpioPins are the default GPIO pins of each PWM channel.
+var defaultGpioPins = [ws2811.RpiPwmChannels]int{18, 13}
+
+// StripType is the model and color order of the LEDs of a channel.
+type StripType int
+
+// Strip types supported by the ws2811 driver.
+const (
+	StripRGB StripType = ws2811.WS2811StripRGB
+	StripRBG StripType = ws2811.WS2811StripRBG
+	StripGRB StripType = ws2811.WS2811StripGRB
+	StripGBR StripType = ws2811.WS2811StripGBR
+	StripBRG StripType = ws2811.WS2811StripBRG
+	StripBGR StripType = ws2811.WS2811StripBGR
+
+	// SK6812 LEDs with a white channel. The white channel is not used.
+	StripRGBW StripType = ws2811.SK6812StripRGBW
+	StripRBGW StripType = ws2811.SK6812StripRBGW
+	StripGRBW StripType = ws2811.SK6812StripGRBW
+	StripGBRW StripType = ws2811.SK6812StrioGBRW
+	StripBRGW StripType = ws2811.SK6812StrioBRGW
+	StripBGRW StripType = ws2811.SK6812StripBGRW
+
+	// StripWS2812 is the color order of WS2812 LEDs.
+	StripWS2812 = StripGRB
+	// StripSK6812 is the color order of SK6812 LEDs.
+	StripSK6812 = StripGRB
+	// StripSK6812W is the color order of SK6812 LEDs with a white channel.
+	StripSK6812W = StripGRBW
+)
+
+// Controller drives the LED rings connected to the PWM channels of a
+// Raspberry Pi, sharing a single ws2811 device. The LEDs of a channel can be
+// split into several rings wired in series (see Options.Segments).
+type Controller struct {
+	device   ws2811Device
+	rings    []*Ring
+	channels []*channel // device of each ring
+	budgets  []int      // MaxCurrentMilliamps of each channel
+
+	mu     sync.Mutex // serializes access to the device
+	frames [][]uint32 // frame of each channel, before the power budget
+	open   int        // number of rings not yet closed
+}
+
+// NewController creates a controller with a ring on each PWM channel. The
+// first options configure the ring on channel 0 (default: GPIO 18), and the
+// second options, if any, configure the ring on channel 1 (default: GPIO 13).
+// If the options of a channel have Segments, a ring is created for each
+// segment instead.
+func NewController(options ...*Options) (*Controller, error) {
+	if len(options) == 0 || len(options) > ws2811.RpiPwmChannels {
+		return nil, fmt.Errorf("ring: controller supports 1 to %d channels, got %d", ws2811.RpiPwmChannels, len(options))
+	}
+	for i, o := range options {
+		if o.Driver != nil {
+			return nil, fmt.Errorf("%w: channel %d has a driver, use New instead", ErrInvalidOptions, i)
+		}
+		if err := o.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if getuid() != 0 {
+		options[0].logger().Error("could not start ws2811 device", "err", ErrNeedRoot)
+		return nil, ErrNeedRoot
+	}
+
+	opt := ws2811.DefaultOptions
+	if f := options[0].Frequency; f != 0 {
+		opt.Frequency = f
+	}
+	if options[0].DmaChannel != 0 {
+		opt.DmaNum = options[0].DmaChannel
+	}
+	opt.Channels = make([]ws2811.ChannelOption, len(options))
+	for i, o := range options {
+		ch := ws2811.DefaultOptions.Channels[0]
+		ch.GpioPin = defaultGpioPins[i]
+		ch.LedCount = o.LedCount
+		if len(o.Segments) != 0 {
+			ch.LedCount = 0
+			for _, seg := range o.Segments {
+				ch.LedCount += seg.LedCount
+			}
+		}
+		// Brightness is scaled while rendering, so the device always outputs
+		// the full range.
+		ch.Brightness = 255
+		if o.GpioPin != 0 {
+			ch.GpioPin = o.GpioPin
+		}
+		ch.Invert = o.Invert
+		if o.StripType != 0 {
+			ch.StripeType = int(o.StripType)
+		}
+		opt.Channels[i] = ch
+	}
+
+	log := options[0].logger()
+	dev, err := makeWS2811(&opt)
+	if err != nil {
+		log.Error("could not create ws2811 device", "err", err)
+		return nil, &DeviceError{"create ws2811 device", err}
+	}
+
+	if err := dev.Init(); err != nil {
+		dev.Fini()
+		log.Error("could not start ws2811 device", "err", err)
+		return nil, &DeviceError{"start ws2811 device", err}
+	}
+	log.Info("ws2811 device started", "channels", len(opt.Channels), "frequency", opt.Frequency, "dma", opt.DmaNum)
+
+	c := &Controller{
+		device: dev,
+	}
+	for i, o := range options {
+		c.budgets = append(c.budgets, o.MaxCurrentMilliamps)
+		c.frames = append(c.frames, make([]uint32, opt.Channels[i].LedCount))
+		if len(o.Segments) == 0 {
+			c.addRing(&channel{ctrl: c, index: i}, o)
+			continue
+		}
+		start := 0
+		for _, seg := range o.Segments {
+			c.addRing(&channel{ctrl: c, index: i, start: start}, seg)
+			start += seg.LedCount
+		}
+	}
+	c.open = len(c.rings)
+
+	return c, nil
+}
+
+// addRing adds a ring on a channel of the controller.
+func (c *Controller) addRing(ch *channel, o *Options) {
+	c.channels = append(c.channels, ch)
+	c.rings = append(c.rings, newRing(ch, o))
+}
+
+// ws2811Device is the ws2811 device shared by the rings of a controller.
+type ws2811Device interface {
+	Init() error
+	Render() error
+	Leds(channel int) []uint32
+	Fini()
+}
+
+// makeWS2811 and getuid are replaced by tests to run without a Raspberry Pi.
+var (
+	makeWS2811 = func(opt *ws2811.Option) (ws2811Device, error) {
+		return ws2811.MakeWS2811(opt)
+	}
+	getuid = os.Getuid
+)
+
+// Ring returns the i-th ring of the controller, ordered by channel and by
+// position in the chain of segments.
+func (c *Controller) Ring(i int) *Ring {
+	return c.rings[i]
+}
+
+// Rings returns all the rings of the controller, ordered by channel and by
+// position in the chain of segments.
+func (c *Controller) Rings() []*Ring {
+	return c.rings
+}
+
+// Close turns off the rings that are still open and closes the device.
+func (c *Controller) Close() {
+	for i, r := range c.rings {
+		c.mu.Lock()
+		closed := c.channels[i].closed
+		c.mu.Unlock()
+		if !closed {
+			r.Close()
+		}
+	}
+}
+
+// channel is the device of a ring connected to a PWM channel of a controller.
+type channel struct {
+	ctrl   *Controller
+	index  int  // PWM channel
+	start  int  // first LED of the ring in the chain
+	closed bool // guarded by the mutex of the controller
+}
+
+// Render renders the frame of the ring in its segment of the channel, and
+// dims the whole channel to fit its power budget. It fails once the ring is
+// closed, as the device may already be closed.
+func (ch *channel) Render(frame []uint32) error {
+	c := ch.ctrl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ch.closed {
+		return fmt.Errorf("ring: channel %d is closed", ch.index)
+	}
+
+	copy(c.frames[ch.index][ch.start:], frame)
+	leds := c.device.Leds(ch.index)
+	copy(leds, c.frames[ch.index])
+	limitCurrent(leds, c.budgets[ch.index])
+
+	return c.device.Render()
+}
+
+// Close closes the device once all the rings of the controller are closed.
+func (ch *channel) Close() {
+	c := ch.ctrl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ch.closed {
+		return
+	}
+	ch.closed = true
+	c.open--
+	if c.open == 0 {
+		c.device.Fini()
+	}
+}
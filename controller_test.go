@@ -0,0 +1,219 @@
+package ring
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	ws2811 "github.com/rpi-ws281x/rpi-ws281x-go"
+)
+
+// fakeWS2811 is a ws2811 device without hardware.
+type fakeWS2811 struct {
+	leds    [][]uint32
+	initErr error
+	fini    int
+	late    int // renders after Fini
+}
+
+func (d *fakeWS2811) Init() error { return d.initErr }
+func (d *fakeWS2811) Render() error {
+	if d.fini > 0 {
+		d.late++
+	}
+	return nil
+}
+func (d *fakeWS2811) Leds(channel int) []uint32 { return d.leds[channel] }
+func (d *fakeWS2811) Fini()                     { d.fini++ }
+
+// useFakeWS2811 makes controllers use a fake device until the test ends.
+func useFakeWS2811(t *testing.T, dev *fakeWS2811) {
+	makeDev, uid := makeWS2811, getuid
+	makeWS2811 = func(opt *ws2811.Option) (ws2811Device, error) {
+		dev.leds = make([][]uint32, len(opt.Channels))
+		for i, ch := range opt.Channels {
+			dev.leds[i] = make([]uint32, ch.LedCount)
+		}
+		return dev, nil
+	}
+	getuid = func() int { return 0 }
+	t.Cleanup(func() {
+		makeWS2811, getuid = makeDev, uid
+	})
+}
+
+func TestController(t *testing.T) {
+	dev := &fakeWS2811{}
+	useFakeWS2811(t, dev)
+
+	c, err := NewController(
+		&Options{LedCount: 2, MaxBrightness: 255},
+		&Options{LedCount: 3, MaxBrightness: 255},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range c.Rings() {
+		l, err := NewLayer(&LayerOptions{Resolution: r.Size()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetAll(color.RGBA{uint8(i + 1), 0, 0, 0xFF})
+		r.AddLayer(l)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := [][]uint32{{0x010000, 0x010000}, {0x020000, 0x020000, 0x020000}}
+	for i := range want {
+		for j := range want[i] {
+			if got := dev.leds[i][j]; got != want[i][j] {
+				t.Errorf("channel %d LED %d got: %#x, want: %#x", i, j, got, want[i][j])
+			}
+		}
+	}
+
+	c.Ring(0).Close()
+	if dev.fini != 0 {
+		t.Errorf("got: device closed with a ring open, want: open")
+	}
+	c.Close()
+	if dev.fini != 1 {
+		t.Errorf("got: device closed %d times, want: 1", dev.fini)
+	}
+}
+
+func TestControllerCloseClosed(t *testing.T) {
+	dev := &fakeWS2811{}
+	useFakeWS2811(t, dev)
+
+	c, err := NewController(
+		&Options{LedCount: 2, MaxBrightness: 255},
+		&Options{LedCount: 3, MaxBrightness: 255},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range c.Rings() {
+		r.Close()
+	}
+	c.Close()
+	c.Ring(0).Close()
+
+	if dev.fini != 1 {
+		t.Errorf("got: device closed %d times, want: 1", dev.fini)
+	}
+	if dev.late != 0 {
+		t.Errorf("got: %d renders after the device was closed, want: 0", dev.late)
+	}
+}
+
+func TestControllerInitError(t *testing.T) {
+	initErr := errors.New("no DMA")
+	dev := &fakeWS2811{initErr: initErr}
+	useFakeWS2811(t, dev)
+
+	_, err := NewController(&Options{LedCount: 2})
+	var devErr *DeviceError
+	if !errors.As(err, &devErr) || !errors.Is(err, initErr) {
+		t.Errorf("got: %v, want: DeviceError wrapping %v", err, initErr)
+	}
+	if dev.fini != 1 {
+		t.Errorf("got: device closed %d times, want: 1", dev.fini)
+	}
+}
+
+func TestControllerSegments(t *testing.T) {
+	dev := &fakeWS2811{}
+	useFakeWS2811(t, dev)
+
+	c, err := NewController(&Options{
+		MaxCurrentMilliamps: 120,
+		Segments: []*Options{
+			{LedCount: 2, MaxBrightness: 255},
+			{LedCount: 2, MaxBrightness: 255},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		ring int
+		want []uint32
+	}{
+		{0, []uint32{0xFFFFFF, 0xFFFFFF, 0, 0}},
+		// Both segments lit draw 240mA, so the channel is dimmed by half.
+		{1, []uint32{0x7F7F7F, 0x7F7F7F, 0x7F7F7F, 0x7F7F7F}},
+	}
+
+	for _, ts := range tests {
+		r := c.Ring(ts.ring)
+		l, err := NewLayer(&LayerOptions{Resolution: r.Size()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetAll(color.White)
+		r.AddLayer(l)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range ts.want {
+			if got := dev.leds[0][i]; got != want {
+				t.Errorf("ring %d LED %d got: %#x, want: %#x", ts.ring, i, got, want)
+			}
+		}
+	}
+
+	if _, err := New(&Options{Segments: []*Options{{LedCount: 2}}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("New with segments got: %v, want: %v", err, ErrInvalidOptions)
+	}
+}
+
+func TestControllerCloseSegment(t *testing.T) {
+	dev := &fakeWS2811{}
+	useFakeWS2811(t, dev)
+
+	c, err := NewController(&Options{
+		Segments: []*Options{
+			{LedCount: 2, MaxBrightness: 255},
+			{LedCount: 2, MaxBrightness: 255},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range c.Rings() {
+		l, err := NewLayer(&LayerOptions{Resolution: r.Size()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetAll(color.White)
+		r.AddLayer(l)
+	}
+
+	c.Ring(0).Close()
+	if err := c.Ring(0).Render(); err == nil {
+		t.Errorf("closed segment got: nil, want: error")
+	}
+	if err := c.Ring(1).Render(); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{0, 0, 0xFFFFFF, 0xFFFFFF}
+	for i := range want {
+		if got := dev.leds[0][i]; got != want[i] {
+			t.Errorf("LED %d got: %#x, want: %#x", i, got, want[i])
+		}
+	}
+
+	c.Ring(1).Close()
+	c.Close()
+	if dev.fini != 1 {
+		t.Errorf("got: device closed %d times, want: 1", dev.fini)
+	}
+	if dev.late != 0 {
+		t.Errorf("got: %d renders after the device was closed, want: 0", dev.late)
+	}
+}
@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Client sends requests to a daemon.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to the daemon listening on the Unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("daemon: could not connect: %w", err)
+	}
+
+	return &Client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(bufio.NewReader(conn)),
+	}, nil
+}
+
+// Do sends a request to the daemon and waits for its response.
+func (c *Client) Do(req Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(req); err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	var resp Response
+	if err := c.dec.Decode(&resp); err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New("daemon: " + resp.Error)
+	}
+
+	return nil
+}
+
+// SetPixel sets the color, as #rrggbb or a name such as orange (see
+// ring.ParseColor), of a pixel of a layer.
+func (c *Client) SetPixel(layer string, i int, color string) error {
+	return c.Do(Request{Command: "set", Layer: layer, Pixel: &i, Color: color})
+}
+
+// SetAll sets the color, as #rrggbb or a name, of all the pixels of a layer.
+func (c *Client) SetAll(layer string, color string) error {
+	return c.Do(Request{Command: "set", Layer: layer, Color: color})
+}
+
+// Rotate rotates a layer to an angle in degrees.
+func (c *Client) Rotate(layer string, degrees float64) error {
+	return c.Do(Request{Command: "rotate", Layer: layer, Value: degrees})
+}
+
+// Spin spins a layer at an angular velocity in degrees per second.
+func (c *Client) Spin(layer string, velocity float64) error {
+	return c.Do(Request{Command: "spin", Layer: layer, Value: velocity})
+}
+
+// SetOpacity sets the opacity of a layer, from 0.0 to 1.0.
+func (c *Client) SetOpacity(layer string, opacity float64) error {
+	return c.Do(Request{Command: "opacity", Layer: layer, Value: opacity})
+}
+
+// SetEffect replaces a layer with an effect painted through a palette (see
+// Request).
+func (c *Client) SetEffect(layer, effect, palette string) error {
+	return c.Do(Request{Command: "effect", Layer: layer, Effect: effect, Palette: palette})
+}
+
+// StartEffect replaces a layer with an effect of ring.DefaultRegistry, with
+// the values of its parameters as text.
+func (c *Client) StartEffect(layer, effect string, params map[string]string) error {
+	return c.Do(Request{Command: "effect", Layer: layer, Effect: effect, Params: params})
+}
+
+// SetParams changes the parameters of the effect of a layer, with their values
+// as text.
+func (c *Client) SetParams(layer string, params map[string]string) error {
+	return c.Do(Request{Command: "params", Layer: layer, Params: params})
+}
+
+// SavePreset saves the effects of the layers as a preset.
+func (c *Client) SavePreset(name string) error {
+	return c.Do(Request{Command: "save", Preset: name})
+}
+
+// RecallPreset replaces all the layers with the effects of a preset, by name
+// or by index, such as "0".
+func (c *Client) RecallPreset(nameOrIndex string) error {
+	return c.Do(Request{Command: "preset", Preset: nameOrIndex})
+}
+
+// RemoveLayer removes a layer.
+func (c *Client) RemoveLayer(layer string) error {
+	return c.Do(Request{Command: "remove", Layer: layer})
+}
+
+// SetBrightness sets the brightness of the ring, from 0 to 255.
+func (c *Client) SetBrightness(level int) error {
+	return c.Do(Request{Command: "brightness", Value: float64(level)})
+}
+
+// Off removes all the layers.
+func (c *Client) Off() error {
+	return c.Do(Request{Command: "off"})
+}
+
+// Close closes the connection to the daemon.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,411 @@
+// Package daemon lets unprivileged programs control a ring through a daemon
+// that owns the device, so only the daemon needs root permissions. Run the
+// daemon with "ring daemon" (see cmd/ring), or with ListenAndServe, and
+// connect to it with Dial.
+//
+// Clients send requests to the Unix socket of the daemon as JSON objects, one
+// per line, and the daemon answers each one with a JSON object with an "error"
+// field, empty on success. For example:
+//
+//	{"command": "set", "layer": "status", "pixel": 3, "color": "#ff0000"}
+//	{"command": "spin", "layer": "status", "value": 90}
+//	{"command": "effect", "layer": "background", "effect": "noise", "params": {"palette": "ocean"}}
+//
+// Layers are created the first time they are named, on top of the previous
+// layers, with the resolution of the ring.
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Request is a command sent to the daemon.
+type Request struct {
+	// Command is one of:
+	//  - "set": set the color of a pixel of a layer, or of all its pixels
+	//    if Pixel is nil.
+	//  - "rotate": rotate a layer to Value degrees.
+	//  - "spin": spin a layer at Value degrees per second.
+	//  - "opacity": set the opacity of a layer to Value.
+	//  - "effect": replace a layer with an effect of ring.DefaultRegistry,
+	//    such as "noise" or "comet", with the values of its parameters in
+	//    Params. Palette sets the "palette" parameter.
+	//  - "params": change the parameters of the effect of a layer to the
+	//    values in Params, while it plays (see ring.Tunable).
+	//  - "save": save the effects of the layers, with the current values of
+	//    their parameters, as the preset named Preset (see Server.SetPresets).
+	//    Layers that are not effects are not saved.
+	//  - "preset": replace all the layers with the effects of a preset, by
+	//    name or by index, such as "0".
+	//  - "remove": remove a layer.
+	//  - "brightness": set the brightness of the ring to Value, from 0 to
+	//    255.
+	//  - "off": remove all the layers.
+	Command string  `json:"command"`
+	Layer   string  `json:"layer,omitempty"`
+	Pixel   *int    `json:"pixel,omitempty"`
+	Color   string  `json:"color,omitempty"`
+	Value   float64 `json:"value,omitempty"`
+	Effect  string  `json:"effect,omitempty"`
+	Palette string  `json:"palette,omitempty"`
+	Preset  string  `json:"preset,omitempty"`
+
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Response is the answer of the daemon to a request.
+type Response struct {
+	// Error describes why the request failed, or is empty on success.
+	Error string `json:"error,omitempty"`
+}
+
+// Server runs the requests of clients on a ring.
+type Server struct {
+	ring *ring.Ring
+
+	mu      sync.Mutex
+	layers  map[string]*layer
+	presets *ring.PresetStore
+	gid     int // group of the socket, or -1 for the group of the process
+
+	listeners []net.Listener // served by Serve
+	closed    bool           // Close was called
+}
+
+// layer is a named layer of the ring.
+type layer struct {
+	pixeler ring.Pixeler // added to the ring
+	layer   drawer       // drawable layer of the pixeler, or nil
+	effect  string       // name of the effect of the pixeler, if any
+}
+
+// drawer is a layer that can be drawn on, such as a ring.Layer or an effect
+// built on one.
+type drawer interface {
+	SetAll(c color.Color)
+	SetPixel(i int, c color.Color)
+	RotateDegrees(angle float64)
+	Spin(velocity float64)
+	SetOpacity(opacity float64)
+}
+
+// NewServer creates a server that controls a ring. Run the ring (see
+// Ring.Run) to show the changes and animations.
+func NewServer(r *ring.Ring) *Server {
+	return &Server{
+		ring:   r,
+		layers: make(map[string]*layer),
+		gid:    -1,
+	}
+}
+
+// SetPresets sets where the "save" and "preset" commands keep the presets.
+func (s *Server) SetPresets(p *ring.PresetStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.presets = p
+}
+
+// SetGroup sets the group, by ID, of the socket created by ListenAndServe,
+// whose members can use the daemon besides its owner.
+func (s *Server) SetGroup(gid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gid = gid
+}
+
+// ListenAndServe listens on a Unix socket at path and serves the requests of
+// clients on a ring. A stale socket at path is removed, but any other file at
+// path is left alone and reported as an error. The socket can be used by the
+// owner and the group of the file (see os.Chown).
+func ListenAndServe(path string, r *ring.Ring) error {
+	return NewServer(r).ListenAndServe(path)
+}
+
+// ListenAndServe is like the function ListenAndServe, with the presets and
+// the group of the server.
+func (s *Server) ListenAndServe(path string) error {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("daemon: %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("daemon: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	defer l.Close()
+	if err := os.Chmod(path, 0660); err != nil {
+		return fmt.Errorf("daemon: %w", err)
+	}
+	s.mu.Lock()
+	gid := s.gid
+	s.mu.Unlock()
+	if gid >= 0 {
+		if err := os.Chown(path, -1, gid); err != nil {
+			return fmt.Errorf("daemon: %w", err)
+		}
+	}
+
+	return s.Serve(l)
+}
+
+// Serve accepts connections on the listener and serves their requests. It
+// returns when the listener fails, or nil once the server is closed.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	s.listeners = append(s.listeners, l)
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.closed {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes the listeners of the server, which removes the socket created
+// by ListenAndServe. It does not close the ring.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	var err error
+	for _, l := range s.listeners {
+		if e := l.Close(); err == nil {
+			err = e
+		}
+	}
+	s.listeners = nil
+
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	sc := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for sc.Scan() {
+		var req Request
+		var resp Response
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %v", err)
+		} else if err := s.Do(req); err != nil {
+			resp.Error = err.Error()
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// Do runs a request on the ring.
+func (s *Server) Do(req Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Command {
+	case "set":
+		c, err := ring.ParseColor(req.Color)
+		if err != nil {
+			return err
+		}
+		l, err := s.layer(req.Layer)
+		if err != nil {
+			return err
+		}
+		if req.Pixel == nil {
+			l.SetAll(c)
+			return nil
+		}
+		if *req.Pixel < 0 || *req.Pixel >= s.ring.Size() {
+			return fmt.Errorf("pixel %d out of range", *req.Pixel)
+		}
+		l.SetPixel(*req.Pixel, c)
+	case "rotate":
+		l, err := s.layer(req.Layer)
+		if err != nil {
+			return err
+		}
+		l.RotateDegrees(req.Value)
+	case "spin":
+		l, err := s.layer(req.Layer)
+		if err != nil {
+			return err
+		}
+		l.Spin(req.Value * math.Pi / 180)
+	case "opacity":
+		l, err := s.layer(req.Layer)
+		if err != nil {
+			return err
+		}
+		l.SetOpacity(req.Value)
+	case "effect":
+		return s.effect(req)
+	case "params":
+		l, ok := s.layers[req.Layer]
+		if !ok {
+			return fmt.Errorf("unknown layer %q", req.Layer)
+		}
+		t, ok := l.pixeler.(ring.Tunable)
+		if !ok {
+			return fmt.Errorf("layer %q has no parameters", req.Layer)
+		}
+		for name, v := range req.Params {
+			if err := t.SetParam(name, v); err != nil {
+				return err
+			}
+		}
+	case "save":
+		return s.save(req.Preset)
+	case "preset":
+		return s.recall(req.Preset)
+	case "remove":
+		if l, ok := s.layers[req.Layer]; ok {
+			s.ring.RemoveEffect(l.pixeler)
+			delete(s.layers, req.Layer)
+		}
+	case "brightness":
+		s.ring.SetBrightness(int(req.Value))
+	case "off":
+		s.off()
+	default:
+		return fmt.Errorf("unknown command %q", req.Command)
+	}
+
+	return nil
+}
+
+// layer returns the named layer, creating it if needed.
+func (s *Server) layer(name string) (drawer, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing layer name")
+	}
+	if l, ok := s.layers[name]; ok {
+		if l.layer == nil {
+			return nil, fmt.Errorf("layer %q cannot be drawn on", name)
+		}
+		return l.layer, nil
+	}
+
+	l, err := ring.NewLayer(&ring.LayerOptions{Name: name, Resolution: s.ring.Size()})
+	if err != nil {
+		return nil, err
+	}
+	s.add(name, &layer{pixeler: l, layer: l})
+
+	return l, nil
+}
+
+// add adds a named layer to the ring, replacing the layer with the same name.
+func (s *Server) add(name string, l *layer) {
+	if old, ok := s.layers[name]; ok {
+		s.ring.RemoveEffect(old.pixeler)
+	}
+	s.layers[name] = l
+	s.ring.AddEffect(l.pixeler)
+}
+
+func (s *Server) effect(req Request) error {
+	if req.Layer == "" {
+		return fmt.Errorf("missing layer name")
+	}
+	args := make(map[string]string, len(req.Params)+1)
+	for k, v := range req.Params {
+		args[k] = v
+	}
+	if req.Palette != "" {
+		args["palette"] = req.Palette
+	}
+
+	e, err := ring.NewEffect(req.Effect, args, &ring.LayerOptions{Name: req.Layer, Resolution: s.ring.Size()})
+	if err != nil {
+		return err
+	}
+	d, _ := e.(drawer)
+	s.add(req.Layer, &layer{pixeler: e, layer: d, effect: req.Effect})
+
+	return nil
+}
+
+// off removes all the layers.
+func (s *Server) off() {
+	for name, l := range s.layers {
+		s.ring.RemoveEffect(l.pixeler)
+		delete(s.layers, name)
+	}
+}
+
+// save saves the effects of the layers, from bottom to top, as a preset.
+func (s *Server) save(name string) error {
+	if s.presets == nil {
+		return fmt.Errorf("presets are not enabled")
+	}
+
+	p := &ring.Preset{Name: name}
+	for _, px := range s.ring.Layers() {
+		for lname, l := range s.layers {
+			if l.pixeler == px && l.effect != "" {
+				p.Layers = append(p.Layers, ring.CaptureLayer(lname, l.effect, px))
+			}
+		}
+	}
+
+	return s.presets.Save(p)
+}
+
+// recall replaces all the layers with the effects of a preset.
+func (s *Server) recall(nameOrIndex string) error {
+	if s.presets == nil {
+		return fmt.Errorf("presets are not enabled")
+	}
+	p, err := s.presets.Get(nameOrIndex)
+	if err != nil {
+		return err
+	}
+	es, err := p.Effects(ring.DefaultRegistry, s.ring.Size())
+	if err != nil {
+		return err
+	}
+
+	s.off()
+	for i, e := range es {
+		pl := p.Layers[i]
+		name := pl.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", pl.Effect, i)
+		}
+		d, _ := e.(drawer)
+		s.add(name, &layer{pixeler: e, layer: d, effect: pl.Effect})
+	}
+
+	return nil
+}
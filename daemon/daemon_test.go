@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestDaemon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring.sock")
+
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	go ListenAndServe(path, r)
+
+	var c *Client
+	for deadline := time.Now().Add(time.Second); c == nil; time.Sleep(time.Millisecond) {
+		c, err = Dial(path)
+		if err != nil && time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+	}
+	defer c.Close()
+
+	tests := []struct {
+		name    string
+		do      func() error
+		want    []uint32
+		wantErr bool
+	}{
+		{
+			"set all",
+			func() error { return c.SetAll("bg", "#000010") },
+			[]uint32{0x000010, 0x000010, 0x000010, 0x000010},
+			false,
+		},
+		{
+			"set pixel over",
+			func() error { return c.SetPixel("fg", 2, "#f00") },
+			[]uint32{0x000010, 0x000010, 0xFF0000, 0x000010},
+			false,
+		},
+		{
+			"remove",
+			func() error { return c.RemoveLayer("bg") },
+			[]uint32{0, 0, 0xFF0000, 0},
+			false,
+		},
+		{
+			"invalid color",
+			func() error { return c.SetAll("bg", "#red") },
+			[]uint32{0, 0, 0xFF0000, 0},
+			true,
+		},
+		{
+			"effect",
+			func() error { return c.StartEffect("bg", "solid", map[string]string{"color": "#00ff00"}) },
+			[]uint32{0x00FF00, 0x00FF00, 0x00FF00, 0x00FF00},
+			false,
+		},
+		{
+			"draw on effect",
+			func() error { return c.SetPixel("bg", 0, "#0000ff") },
+			[]uint32{0x0000FF, 0x00FF00, 0x00FF00, 0x00FF00},
+			false,
+		},
+		{
+			"params",
+			func() error { return c.SetParams("bg", map[string]string{"color": "#ff0000"}) },
+			[]uint32{0xFF0000, 0xFF0000, 0xFF0000, 0xFF0000},
+			false,
+		},
+		{
+			"unknown effect parameter",
+			func() error { return c.StartEffect("bg", "solid", map[string]string{"speed": "1"}) },
+			[]uint32{0xFF0000, 0xFF0000, 0xFF0000, 0xFF0000},
+			true,
+		},
+		{
+			"off",
+			func() error { return c.Off() },
+			[]uint32{0, 0, 0, 0},
+			false,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			if err := ts.do(); (err != nil) != ts.wantErr {
+				t.Fatalf("got: %v, want error: %v", err, ts.wantErr)
+			}
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+			got := dev.Frame()
+			for i := range ts.want {
+				if got[i] != ts.want[i] {
+					t.Errorf("got: %#x, want: %#x", got, ts.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 2})
+	s := NewServer(r)
+	if err := s.Do(Request{Command: "save", Preset: "green"}); err == nil {
+		t.Errorf("without presets got: nil, want: error")
+	}
+	presets, err := ring.OpenPresets(filepath.Join(dir, "presets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetPresets(presets)
+
+	tests := []struct {
+		name    string
+		req     Request
+		want    []uint32
+		wantErr bool
+	}{
+		{"effect", Request{Command: "effect", Layer: "bg", Effect: "solid", Params: map[string]string{"color": "#00ff00"}}, []uint32{0x00FF00, 0x00FF00}, false},
+		{"draw", Request{Command: "set", Layer: "fg", Color: "#ff0000"}, []uint32{0xFF0000, 0xFF0000}, false},
+		{"save", Request{Command: "save", Preset: "green"}, []uint32{0xFF0000, 0xFF0000}, false},
+		{"tune", Request{Command: "params", Layer: "bg", Params: map[string]string{"color": "#0000ff"}}, []uint32{0xFF0000, 0xFF0000}, false},
+		{"save again", Request{Command: "save", Preset: "blue"}, []uint32{0xFF0000, 0xFF0000}, false},
+		{"recall by name", Request{Command: "preset", Preset: "green"}, []uint32{0x00FF00, 0x00FF00}, false},
+		{"recall by index", Request{Command: "preset", Preset: "1"}, []uint32{0x0000FF, 0x0000FF}, false},
+		{"unknown preset", Request{Command: "preset", Preset: "red"}, []uint32{0x0000FF, 0x0000FF}, true},
+		{"tune recalled", Request{Command: "params", Layer: "bg", Params: map[string]string{"color": "#ffffff"}}, []uint32{0xFFFFFF, 0xFFFFFF}, false},
+	}
+
+	for _, ts := range tests {
+		if err := s.Do(ts.req); (err != nil) != ts.wantErr {
+			t.Fatalf("%s got: %v, want error: %v", ts.name, err, ts.wantErr)
+		}
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		got := dev.Frame()
+		for i := range ts.want {
+			if got[i] != ts.want[i] {
+				t.Errorf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+				break
+			}
+		}
+	}
+}
+
+func TestListenAndServeError(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	s := NewServer(r)
+	s.SetGroup(os.Getgid())
+
+	err := s.ListenAndServe(filepath.Join("no", "such", "dir", "ring.sock"))
+	if err == nil || !strings.HasPrefix(err.Error(), "daemon: ") {
+		t.Errorf("got: %v, want: daemon error", err)
+	}
+}
+
+func TestListenAndServeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring.sock")
+	if err := ioutil.WriteFile(path, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	if err := NewServer(r).ListenAndServe(path); err == nil {
+		t.Errorf("got: nil, want: error")
+	}
+	if data, err := ioutil.ReadFile(path); err != nil || string(data) != "keep" {
+		t.Errorf("got: %q, %v, want: file left alone", data, err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ring.sock")
+
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	s := NewServer(r)
+	errc := make(chan error, 1)
+	go func() { errc <- s.ListenAndServe(path) }()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		if _, err := os.Lstat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("socket not created")
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("got: %v, want: nil", err)
+	}
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Errorf("got: %v, want: socket removed", err)
+	}
+}
@@ -0,0 +1,50 @@
+package ring
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// FrameDump writes rendered frames as PNG images to a directory, drawn as a
+// circle of LEDs (see Ring.CircleImage), to inspect glitches of animations
+// frame by frame. Encoding the images slows down rendering, so it is meant for
+// debugging.
+type FrameDump struct {
+	// Dir is the directory where the images are written, as
+	// frame-000001.png, frame-000002.png, and so on, numbered by rendered
+	// frame. It must exist.
+	Dir string
+	// Every writes one of every Every rendered frames (default: 1, all the
+	// frames).
+	Every int
+	// Size is the width and height of the images, in pixels (default: 128).
+	Size int
+}
+
+// dump writes a frame of colors as an image, if it is one of every Every
+// frames.
+func (d *FrameDump) dump(r *Ring, frame int) error {
+	every, size := d.Every, d.Size
+	if every == 0 {
+		every = 1
+	}
+	if size == 0 {
+		size = 128
+	}
+	if frame%every != 0 {
+		return nil
+	}
+
+	f, err := os.Create(filepath.Join(d.Dir, fmt.Sprintf("frame-%06d.png", frame)))
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, circleImage(r.shown, size)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,54 @@
+package ring
+
+import (
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFrameDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "frames")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dev := &fakeDevice{leds: make([]uint32, 4)}
+	r := newRing(dev, &Options{LedCount: 4, FrameDump: &FrameDump{Dir: dir, Every: 2, Size: 32}})
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	r.AddLayer(l)
+	for i := 0; i < 4; i++ {
+		l.SetPixel(i, color.White)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	if got, want := names, []string{"frame-000002.png", "frame-000004.png"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got: %v, want: %v", got, want)
+	}
+
+	f, err := os.Open(filepath.Join(dir, names[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds().Dx(), 32; got != want {
+		t.Errorf("got: %d pixels wide, want: %d", got, want)
+	}
+}
@@ -0,0 +1,430 @@
+package ring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EffectSpec describes an effect that can be created by name (see Registry).
+type EffectSpec struct {
+	// Name is the name of the effect, such as "comet".
+	Name string
+	// Doc describes the effect.
+	Doc string
+	// Params are the parameters of the effect.
+	Params []ParamSpec
+	// New creates the effect on a layer with the given options. Effects that
+	// are also an Animation are played by Ring.AddEffect.
+	New func(p Params, options *LayerOptions) (Pixeler, error)
+}
+
+// Registry maps the names of effects to their constructors, so effects can be
+// started by name, such as from the command line or a remote API, without
+// recompiling. The built-in effects are in DefaultRegistry.
+type Registry struct {
+	mu      sync.RWMutex
+	effects map[string]EffectSpec
+}
+
+// NewRegistry creates a registry without effects.
+func NewRegistry() *Registry {
+	return &Registry{effects: make(map[string]EffectSpec)}
+}
+
+// Register adds an effect to the registry. It returns an error if the name is
+// already registered or the defaults of the parameters are invalid.
+func (g *Registry) Register(spec EffectSpec) error {
+	if spec.Name == "" || spec.New == nil {
+		return fmt.Errorf("ring: effect needs a name and a constructor")
+	}
+	for _, ps := range spec.Params {
+		if _, err := ps.value(ps.Default); err != nil {
+			return fmt.Errorf("ring: effect %q: parameter %q: %w", spec.Name, ps.Name, err)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.effects[spec.Name]; ok {
+		return fmt.Errorf("ring: effect %q is already registered", spec.Name)
+	}
+	g.effects[spec.Name] = spec
+
+	return nil
+}
+
+// Lookup returns the effect registered with a name.
+func (g *Registry) Lookup(name string) (EffectSpec, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	spec, ok := g.effects[name]
+	return spec, ok
+}
+
+// Effects returns the registered effects, sorted by name.
+func (g *Registry) Effects() []EffectSpec {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	specs := make([]EffectSpec, 0, len(g.effects))
+	for _, spec := range g.effects {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+
+	return specs
+}
+
+// New creates an effect by name, with the values of its parameters as text.
+// Parameters that are not given take their default values.
+func (g *Registry) New(name string, args map[string]string, options *LayerOptions) (Pixeler, error) {
+	spec, ok := g.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("ring: unknown effect %q", name)
+	}
+
+	p := make(Params, len(spec.Params))
+	for _, ps := range spec.Params {
+		s, ok := args[ps.Name]
+		if !ok {
+			s = ps.Default
+		}
+		v, err := ps.value(s)
+		if err != nil {
+			return nil, fmt.Errorf("ring: effect %q: parameter %q: %w", name, ps.Name, err)
+		}
+		p[ps.Name] = v
+	}
+	for k := range args {
+		if _, ok := p[k]; !ok {
+			return nil, fmt.Errorf("ring: effect %q has no parameter %q", name, k)
+		}
+	}
+
+	return spec.New(p, options)
+}
+
+// DefaultRegistry is the registry of the built-in effects, used by
+// RegisterEffect and NewEffect.
+var DefaultRegistry = NewRegistry()
+
+// RegisterEffect adds an effect to DefaultRegistry. It panics if the effect
+// cannot be registered, so it is meant to be called from init functions.
+func RegisterEffect(spec EffectSpec) {
+	if err := DefaultRegistry.Register(spec); err != nil {
+		panic(err)
+	}
+}
+
+// NewEffect creates an effect of DefaultRegistry by name (see Registry.New):
+//
+//	e, err := ring.NewEffect("comet", map[string]string{"speed": "360"}, &ring.LayerOptions{Resolution: r.Size()})
+//	...
+//	r.AddEffect(e)
+func NewEffect(name string, args map[string]string, options *LayerOptions) (Pixeler, error) {
+	return DefaultRegistry.New(name, args, options)
+}
+
+// ParseEffect splits the text of an effect with its parameters, such as
+// "comet speed=360 color=red", into the name of the effect and the values of
+// its parameters.
+func ParseEffect(s string) (name string, args map[string]string, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("ring: missing effect name")
+	}
+	args = make(map[string]string, len(fields)-1)
+	for _, f := range fields[1:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return "", nil, fmt.Errorf("ring: invalid effect parameter %q, want name=value", f)
+		}
+		args[kv[0]] = kv[1]
+	}
+
+	return fields[0], args, nil
+}
+
+// AddEffect adds an effect on top of the layers of the ring, and plays it with
+// the animator of the ring if it is an Animation.
+func (r *Ring) AddEffect(e Pixeler) {
+	r.syncEffect(e)
+	r.AddLayer(e)
+	if a, ok := e.(Animation); ok {
+		r.Animator().Play(a)
+	}
+}
+
+// AdvanceEffect moves an effect forward by dt, as if the ring ran for dt, such
+// as to start it at a later point of its animation.
+func AdvanceEffect(e Pixeler, dt time.Duration) {
+	advanceEffect(e, dt)
+}
+
+// advanceEffect advances an animated layer or an animation by dt.
+func advanceEffect(e interface{}, dt time.Duration) {
+	updateLayer(e, dt)
+	if a, ok := e.(Animation); ok {
+		a.Tick(dt)
+	}
+}
+
+// RemoveEffect removes an effect added with AddEffect.
+func (r *Ring) RemoveEffect(e Pixeler) {
+	if a, ok := e.(Animation); ok {
+		r.Animator().Stop(a)
+	}
+	r.RemoveLayer(e)
+}
+
+// layerEffect is a layer painted from the values of its parameters, for
+// effects without a type of their own.
+type layerEffect struct {
+	*Layer
+	params
+
+	mu     sync.Mutex
+	values Params
+	paint  func(l *Layer, p Params)
+}
+
+// newLayerEffect creates an effect painted by paint whenever its parameters
+// change.
+func newLayerEffect(specs []ParamSpec, p Params, options *LayerOptions, paint func(l *Layer, p Params)) (*layerEffect, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &layerEffect{
+		Layer:  l,
+		values: p,
+		paint:  paint,
+	}
+	e.params = params{
+		specs: specs,
+		get: func(name string) interface{} {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			return e.values[name]
+		},
+		set: func(name string, v interface{}) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+
+			e.values[name] = v
+			e.paint(e.Layer, e.values)
+		},
+	}
+	paint(l, p)
+
+	return e, nil
+}
+
+var (
+	solidParams = []ParamSpec{
+		{Name: "color", Type: ParamColor, Default: "white", Doc: "color of the ring"},
+	}
+	rainbowParams = []ParamSpec{
+		{Name: "palette", Type: ParamPalette, Default: "rainbow", Doc: "palette around the ring"},
+		{Name: "speed", Type: ParamFloat, Default: "0", Doc: "spin in degrees per second", Min: -720, Max: 720},
+	}
+)
+
+func init() {
+	RegisterEffect(EffectSpec{
+		Name:   "solid",
+		Doc:    "fills the ring with a color",
+		Params: solidParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			return newLayerEffect(solidParams, p, options, func(l *Layer, p Params) {
+				l.SetAll(p.Color("color"))
+			})
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "rainbow",
+		Doc:    "paints a palette around the ring",
+		Params: rainbowParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			return newLayerEffect(rainbowParams, p, options, func(l *Layer, p Params) {
+				n := l.Options().Resolution
+				for i := 0; i < n; i++ {
+					l.SetPixel(i, p.Palette("palette").Cycle(float64(i)/float64(n)))
+				}
+				l.Spin(radians(p.Float("speed")))
+			})
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "noise",
+		Doc:    "paints smooth noise through a palette",
+		Params: noiseParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			n, err := NewNoise(p.Palette("palette"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(n, p); err != nil {
+				return nil, err
+			}
+			return n, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "comet",
+		Doc:    "a comet with a fading tail that circles the ring",
+		Params: cometParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			c, err := NewComet(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(c, p); err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "scanner",
+		Doc:    "an eye that sweeps back and forth with a fading trail",
+		Params: scannerParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			s, err := NewScanner(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(s, p); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "chase",
+		Doc:    "blocks of pixels that step around the ring like theater lights",
+		Params: chaseParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			c, err := NewChase(p.Palette("palette"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(c, p); err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "wipe",
+		Doc:    "sweeps a color around the ring from an angle",
+		Params: wipeParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			w, err := NewColorWipe(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(w, p); err != nil {
+				return nil, err
+			}
+			return w, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "meteor",
+		Doc:    "meteors that orbit the ring with randomly fading trails",
+		Params: meteorParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			m, err := NewMeteor(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(m, p); err != nil {
+				return nil, err
+			}
+			return m, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "balls",
+		Doc:    "balls that bounce on the ring as if it stood upright",
+		Params: ballsParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			b, err := NewBalls(p.Int("balls"), p.Palette("palette"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(b, p); err != nil {
+				return nil, err
+			}
+			return b, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "candle",
+		Doc:    "a warm flicker like the flame of a candle",
+		Params: candleParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			c, err := NewCandle(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(c, p); err != nil {
+				return nil, err
+			}
+			return c, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "lightning",
+		Doc:    "random flashes of lightning with an afterglow",
+		Params: lightningParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			g, err := NewLightning(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(g, p); err != nil {
+				return nil, err
+			}
+			return g, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "strobe",
+		Doc:    "flashes a color at a regular, safe frequency",
+		Params: strobeParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			s, err := NewStrobe(p.Color("color"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(s, p); err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+	RegisterEffect(EffectSpec{
+		Name:   "plasma",
+		Doc:    "paints moving sine waves through a palette",
+		Params: plasmaParams,
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			pl, err := NewPlasma(p.Palette("palette"), options)
+			if err != nil {
+				return nil, err
+			}
+			if err := setParams(pl, p); err != nil {
+				return nil, err
+			}
+			return pl, nil
+		},
+	})
+}
@@ -0,0 +1,146 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	g := NewRegistry()
+	spec := EffectSpec{
+		Name: "fill",
+		Params: []ParamSpec{
+			{Name: "color", Type: ParamColor, Default: "red"},
+			{Name: "count", Type: ParamInt, Default: "2"},
+		},
+		New: func(p Params, options *LayerOptions) (Pixeler, error) {
+			l, err := NewLayer(options)
+			if err != nil {
+				return nil, err
+			}
+			l.SetRange(0, p.Int("count"), p.Color("color"))
+			return l, nil
+		},
+	}
+	if err := g.Register(spec); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Register(spec); err == nil {
+		t.Errorf("duplicate got: nil, want: error")
+	}
+	bad := spec
+	bad.Name = "bad"
+	bad.Params = []ParamSpec{{Name: "color", Type: ParamColor, Default: "nope"}}
+	if err := g.Register(bad); err == nil {
+		t.Errorf("invalid default got: nil, want: error")
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		want    []color.Color
+		wantErr string
+	}{
+		{"fill", nil, []color.Color{
+			color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+			color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x00, 0x00},
+		}, ""},
+		{"fill", map[string]string{"color": "#00f", "count": "1"}, []color.Color{
+			color.RGBA{0x00, 0x00, 0xFF, 0xFF},
+			color.RGBA{0x00, 0x00, 0x00, 0x00},
+			color.RGBA{0x00, 0x00, 0x00, 0x00},
+		}, ""},
+		{"fill", map[string]string{"count": "many"}, nil, `parameter "count"`},
+		{"fill", map[string]string{"speed": "1"}, nil, `no parameter "speed"`},
+		{"unknown", nil, nil, `unknown effect "unknown"`},
+	}
+
+	for _, ts := range tests {
+		e, err := g.New(ts.name, ts.args, &LayerOptions{Resolution: 3})
+		if ts.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), ts.wantErr) {
+				t.Errorf("%s %v got: %v, want: error with %q", ts.name, ts.args, err, ts.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range ts.want {
+			if got := color.RGBAModel.Convert(e.Pixel(i)); got != want {
+				t.Errorf("%s %v pixel %d got: %#v, want: %#v", ts.name, ts.args, i, got, want)
+			}
+		}
+	}
+}
+
+func TestBuiltinEffects(t *testing.T) {
+	for _, spec := range DefaultRegistry.Effects() {
+		e, err := NewEffect(spec.Name, nil, &LayerOptions{Resolution: 12})
+		if err != nil {
+			t.Errorf("%s: %v", spec.Name, err)
+			continue
+		}
+		if a, ok := e.(advancer); ok {
+			a.advance(100 * time.Millisecond)
+		}
+		if a, ok := e.(Animation); ok {
+			a.Tick(100 * time.Millisecond)
+		}
+	}
+}
+
+func TestParseEffect(t *testing.T) {
+	tests := []struct {
+		s        string
+		wantName string
+		wantArgs map[string]string
+		wantErr  bool
+	}{
+		{"comet", "comet", map[string]string{}, false},
+		{" comet  speed=3 color=#f00 ", "comet", map[string]string{"speed": "3", "color": "#f00"}, false},
+		{"comet speed", "", nil, true},
+		{"comet =3", "", nil, true},
+		{"", "", nil, true},
+	}
+
+	for _, ts := range tests {
+		name, args, err := ParseEffect(ts.s)
+		if (err != nil) != ts.wantErr {
+			t.Errorf("%q got: %v, want error: %v", ts.s, err, ts.wantErr)
+			continue
+		}
+		if name != ts.wantName || !reflect.DeepEqual(args, ts.wantArgs) {
+			t.Errorf("%q got: %q %#v, want: %q %#v", ts.s, name, args, ts.wantName, ts.wantArgs)
+		}
+	}
+}
+
+func TestComet(t *testing.T) {
+	c, err := NewComet(color.White, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetLength(math.Pi / 2)
+	c.SetSpeed(math.Pi / 2)
+
+	head := func() int {
+		brightest, max := -1, uint32(0)
+		for i := 0; i < 4; i++ {
+			if _, _, _, a := c.Pixel(i).RGBA(); a > max {
+				brightest, max = i, a
+			}
+		}
+		return brightest
+	}
+	before := head()
+	c.advance(time.Second)
+	if got, want := head(), (before+1)%4; got != want {
+		t.Errorf("got: head at %d, want: %d", got, want)
+	}
+}
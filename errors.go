@@ -0,0 +1,43 @@
+package ring
+
+import "errors"
+
+var (
+	// ErrNeedRoot is returned when the PWM output is opened without root
+	// permissions.
+	ErrNeedRoot = errors.New("ring: rpi-ws281x needs root permissions (try running as sudo)")
+	// ErrZeroResolution is returned when a layer is created with a resolution
+	// of 0 or less.
+	ErrZeroResolution = errors.New("ring: resolution of new layer is not positive")
+	// ErrDeviceInit is matched by the errors returned when the device that
+	// drives the LEDs cannot be opened or started. See DeviceError.
+	ErrDeviceInit = errors.New("ring: could not initialize device")
+	// ErrInvalidOptions is matched by the errors returned when the options of
+	// a ring or a layer are invalid.
+	ErrInvalidOptions = errors.New("ring: invalid options")
+	// ErrPixelOutOfRange is returned when a pixel or an LED position is
+	// outside of a layer or a ring.
+	ErrPixelOutOfRange = errors.New("ring: pixel out of range")
+)
+
+// DeviceError is returned when the device that drives the LEDs cannot be
+// opened or started. It matches ErrDeviceInit with errors.Is.
+type DeviceError struct {
+	// Op is the operation that failed, such as "start ws2811 device".
+	Op string
+	// Err is the error returned by the driver.
+	Err error
+}
+
+func (e *DeviceError) Error() string {
+	return "ring: could not " + e.Op + ": " + e.Err.Error()
+}
+
+func (e *DeviceError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDeviceInit.
+func (e *DeviceError) Is(target error) bool {
+	return target == ErrDeviceInit
+}
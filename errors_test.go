@@ -0,0 +1,60 @@
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	_, errLayer := NewLayer(&LayerOptions{})
+	_, errNegative := NewLayer(&LayerOptions{Resolution: -3})
+	_, errMap := NewWithDevice(&fakeDevice{}, &Options{LedCount: 2, PixelMap: []int{0, 2}})
+	errPixel := newTestLayer(t, &LayerOptions{Resolution: 2}).SetPixelChecked(2, color.White)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			"zero resolution",
+			errLayer,
+			ErrZeroResolution,
+		},
+		{
+			"negative resolution",
+			errNegative,
+			ErrZeroResolution,
+		},
+		{
+			"pixel map",
+			errMap,
+			ErrPixelOutOfRange,
+		},
+		{
+			"pixel",
+			errPixel,
+			ErrPixelOutOfRange,
+		},
+		{
+			"device",
+			fmt.Errorf("wrapped: %w", &DeviceError{"open device", errors.New("busy")}),
+			ErrDeviceInit,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			if !errors.Is(ts.err, ts.want) {
+				t.Errorf("got: %v, want: %v", ts.err, ts.want)
+			}
+		})
+	}
+
+	var devErr *DeviceError
+	if err := error(&DeviceError{"open device", ErrNeedRoot}); !errors.As(err, &devErr) || !errors.Is(err, ErrNeedRoot) {
+		t.Errorf("got: %v, want: DeviceError wrapping %v", err, ErrNeedRoot)
+	}
+}
@@ -16,10 +16,10 @@ import (
 func Example() {
 	// Initialize the ring.
 	r, err := ring.New(&ring.Options{
-		LedCount:      12,  // adjust this to the number of LEDs you have
-		MaxBrightness: 180, // value from 0 to 255
+		LedCount:       12,           // adjust this to the number of LEDs you have
+		MaxBrightness:  180,          // value from 0 to 255
+		RotationOffset: -math.Pi / 3, // you can set a rotation offset for the ring
 	})
-	r.Offset(-math.Pi / 3) // you can set a rotation offset for the ring
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -216,3 +216,49 @@ func Example() {
 	// ring before exiting. Otherwise, the ring will stay on with the latest
 	// render.
 }
+
+// nopDevice is a device without LEDs, to run examples without hardware.
+type nopDevice struct{}
+
+func (nopDevice) Render(frame []uint32) error { return nil }
+func (nopDevice) Close()                      {}
+
+func ExampleLayer_Spin() {
+	r, err := ring.NewWithDevice(nopDevice{}, &ring.Options{
+		LedCount: 12,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer r.Close()
+
+	l, err := ring.NewLayer(&ring.LayerOptions{
+		Resolution: 12,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	l.SetPixel(0, color.White)
+	// Make a full turn every 2 seconds.
+	l.Spin(math.Pi)
+	r.AddLayer(l)
+
+	// Ring.Run spins the layer as the ring runs. Here, the layer is moved by
+	// half a second, a quarter of a turn, between frames by hand.
+	for frame := 0; frame < 4; frame++ {
+		if err := r.Render(); err != nil {
+			log.Fatal(err)
+		}
+		for i, c := range r.Snapshot() {
+			if _, _, _, a := c.RGBA(); a > 0 {
+				fmt.Printf("frame %d: LED %d\n", frame, i)
+			}
+		}
+		ring.AdvanceEffect(l, 500*time.Millisecond)
+	}
+	// Output:
+	// frame 0: LED 0
+	// frame 1: LED 9
+	// frame 2: LED 6
+	// frame 3: LED 3
+}
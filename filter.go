@@ -0,0 +1,35 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+)
+
+// Filter transforms the color of each LED after the layers of a ring are
+// blended, before brightness and calibration are applied. The color is
+// premultiplied by its alpha, as in color.RGBA64. Filters should not change
+// over time: set the filter again to apply a change.
+type Filter func(led int, c color.RGBA64) color.RGBA64
+
+// SetTint blends the color of every LED towards a color by an amount, from
+// 0.0 (no tint, default) to 1.0 (only the tint color), after the layers are
+// blended. For example, a dim orange tint shifts the ring to warmer colors at
+// night, and a white tint flashes a notification without changing the layers.
+func (r *Ring) SetTint(c color.Color, amount float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tint = toRGBA64(c)
+	r.tintAmount = math.Max(0, math.Min(1, amount))
+	r.dirty = true
+}
+
+// SetFilter sets a transform applied to the color of every LED after the
+// layers are blended and tinted. A nil filter removes it.
+func (r *Ring) SetFilter(f Filter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.filter = f
+	r.dirty = true
+}
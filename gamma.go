@@ -0,0 +1,61 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+)
+
+// Gamma is the exponent of the response curve of each color channel of the
+// LEDs: a channel at level v, from 0.0 to 1.0, is output at v^gamma. LEDs are
+// much brighter at low levels than the eye expects, and green more than red
+// and blue, so separate exponents keep low-brightness mixes from turning
+// green. An exponent of 0 leaves the channel uncorrected.
+type Gamma struct {
+	R, G, B float64
+}
+
+// UniformGamma returns the same gamma for all the color channels.
+func UniformGamma(gamma float64) Gamma {
+	return Gamma{gamma, gamma, gamma}
+}
+
+// GammaTable maps each 8-bit level of the R, G and B color channels, in this
+// order, to the level output to the LEDs.
+type GammaTable [3][256]uint8
+
+// Table returns the lookup table of the gamma curves.
+func (g Gamma) Table() *GammaTable {
+	var t GammaTable
+	for ch, exp := range []float64{g.R, g.G, g.B} {
+		if exp == 0 {
+			exp = 1
+		}
+		for i := range t[ch] {
+			t[ch][i] = uint8(math.Round(255 * math.Pow(float64(i)/255, exp)))
+		}
+	}
+
+	return &t
+}
+
+// applyGamma maps the color channels of a color through a gamma table. Levels
+// between the 8-bit levels of the table are interpolated, so 16-bit colors keep
+// their precision.
+func applyGamma(c color.RGBA64, t *GammaTable) color.RGBA64 {
+	curve := func(v uint16, levels *[256]uint8) uint16 {
+		x := float64(v) / 0x101
+		i := int(x)
+		if i >= 255 {
+			return uint16(levels[255]) * 0x101
+		}
+		f := x - float64(i)
+		return uint16((float64(levels[i])*(1-f) + float64(levels[i+1])*f) * 0x101)
+	}
+
+	return color.RGBA64{
+		R: curve(c.R, &t[0]),
+		G: curve(c.G, &t[1]),
+		B: curve(c.B, &t[2]),
+		A: c.A,
+	}
+}
@@ -0,0 +1,274 @@
+package ring
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ValueSource provides the value shown by a gauge, such as the load of the
+// CPU or the temperature of the SoC.
+type ValueSource interface {
+	// Value returns the current value.
+	Value() (float64, error)
+}
+
+// ValueFunc is a function that is a ValueSource.
+type ValueFunc func() (float64, error)
+
+// Value returns f().
+func (f ValueFunc) Value() (float64, error) {
+	return f()
+}
+
+// CPULoad returns a source of the load average of the last minute, per CPU,
+// so 1.0 is a fully busy system. It is read from /proc/loadavg, on Linux.
+func CPULoad() ValueSource {
+	return ValueFunc(func() (float64, error) {
+		load, err := readLoad("/proc/loadavg")
+		if err != nil {
+			return 0, err
+		}
+
+		return load / float64(runtime.NumCPU()), nil
+	})
+}
+
+// MemoryUsage returns a source of the fraction of memory in use, from 0.0 to
+// 1.0. It is read from /proc/meminfo, on Linux.
+func MemoryUsage() ValueSource {
+	return ValueFunc(func() (float64, error) {
+		return readMemory("/proc/meminfo")
+	})
+}
+
+// Temperature returns a source of the temperature, in degrees Celsius, read
+// from a file in millidegrees Celsius, such as DefaultThermalZone.
+func Temperature(path string) ValueSource {
+	return ValueFunc(func() (float64, error) {
+		return readTemperature(path)
+	})
+}
+
+// GaugeOptions is the list of options of a gauge.
+type GaugeOptions struct {
+	// Min is the value shown as an empty arc (default: 0).
+	Min float64
+	// Max is the value shown as a full ring (default: Min+1).
+	Max float64
+	// Warning is the fraction of the range from Min to Max at or above which
+	// the arc has the WarningColor (default: 0.6).
+	Warning float64
+	// Critical is the fraction of the range from Min to Max at or above which
+	// the arc has the CriticalColor (default: 0.85).
+	Critical float64
+	// NormalColor is the color of the arc below Warning (default: green).
+	NormalColor color.Color
+	// WarningColor is the color of the arc from Warning to Critical (default:
+	// yellow).
+	WarningColor color.Color
+	// CriticalColor is the color of the arc from Critical up (default: red).
+	CriticalColor color.Color
+	// Interval is the time between readings of the source, timed by the
+	// animation of the ring (default: 1 second).
+	Interval time.Duration
+}
+
+// Gauge is a layer that shows a value read periodically from a source as an
+// arc from the first pixel, green, yellow or red by thresholds, such as a
+// status ring of the load of a headless server:
+//
+//	g, err := ring.NewGauge(ring.CPULoad(), nil, nil)
+//	...
+//	r.AddEffect(g)
+type Gauge struct {
+	*Layer
+
+	src ValueSource
+	opt GaugeOptions
+
+	mu      sync.Mutex
+	value   float64
+	err     error
+	elapsed time.Duration // since the last reading
+}
+
+// NewGauge creates a gauge of the values of a source, with the defaults of the
+// options, and reads the first value. Add it to a ring with AddEffect and run
+// the ring (see Ring.Run) to read the next values.
+func NewGauge(src ValueSource, options *GaugeOptions, layerOptions *LayerOptions) (*Gauge, error) {
+	l, err := NewLayer(layerOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Gauge{
+		Layer: l,
+		src:   src,
+	}
+	if options != nil {
+		g.opt = *options
+	}
+	if g.opt.Max == g.opt.Min {
+		g.opt.Max = g.opt.Min + 1
+	}
+	if g.opt.Warning == 0 {
+		g.opt.Warning = 0.6
+	}
+	if g.opt.Critical == 0 {
+		g.opt.Critical = 0.85
+	}
+	if g.opt.NormalColor == nil {
+		g.opt.NormalColor = color.RGBA{0x00, 0xFF, 0x00, 0xFF}
+	}
+	if g.opt.WarningColor == nil {
+		g.opt.WarningColor = color.RGBA{0xFF, 0xFF, 0x00, 0xFF}
+	}
+	if g.opt.CriticalColor == nil {
+		g.opt.CriticalColor = color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	}
+	if g.opt.Interval <= 0 {
+		g.opt.Interval = time.Second
+	}
+
+	g.Update()
+
+	return g, nil
+}
+
+// Value returns the last value read.
+func (g *Gauge) Value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.value
+}
+
+// Err returns the error of the last reading, if it failed. The gauge keeps
+// showing the last value read until a reading succeeds.
+func (g *Gauge) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.err
+}
+
+// Update reads the source now and shows its value.
+func (g *Gauge) Update() error {
+	v, err := g.src.Value()
+
+	g.mu.Lock()
+	g.elapsed = 0
+	g.err = err
+	if err == nil {
+		g.value = v
+	}
+	g.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	g.paint()
+
+	return nil
+}
+
+// advance reads the source once every interval.
+func (g *Gauge) advance(dt time.Duration) {
+	g.mu.Lock()
+	g.elapsed += dt
+	due := g.elapsed >= g.opt.Interval
+	g.mu.Unlock()
+
+	if due {
+		g.Update()
+	}
+	g.Layer.advance(dt)
+}
+
+func (g *Gauge) paint() {
+	g.mu.Lock()
+	frac := (g.value - g.opt.Min) / (g.opt.Max - g.opt.Min)
+	g.mu.Unlock()
+
+	c := g.opt.NormalColor
+	switch {
+	case frac >= g.opt.Critical:
+		c = g.opt.CriticalColor
+	case frac >= g.opt.Warning:
+		c = g.opt.WarningColor
+	}
+	if frac < 0 {
+		frac = 0
+	}
+	if frac > 1 {
+		frac = 1
+	}
+
+	n := float64(g.Layer.opt.Resolution)
+	g.SetAll(color.Transparent)
+	g.drawSpan(-0.5, frac*n-0.5, solid(c))
+}
+
+// readLoad returns the load average of the last minute from a file in the
+// format of /proc/loadavg.
+func readLoad(path string) (float64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("ring: invalid load average in %s", path)
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("ring: invalid load average in %s: %w", path, err)
+	}
+
+	return load, nil
+}
+
+// readMemory returns the fraction of memory in use from a file in the format
+// of /proc/meminfo.
+func readMemory(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var total, available float64
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb
+		case "MemAvailable:":
+			available = kb
+		}
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("ring: missing total memory in %s", path)
+	}
+
+	return 1 - available/total, nil
+}
@@ -0,0 +1,131 @@
+package ring
+
+import (
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGauge(t *testing.T) {
+	var value float64
+	var err error
+	src := ValueFunc(func() (float64, error) { return value, err })
+	green := toRGBA64(color.RGBA{0x00, 0xFF, 0x00, 0xFF})
+	yellow := toRGBA64(color.RGBA{0xFF, 0xFF, 0x00, 0xFF})
+	red := toRGBA64(color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+
+	value = 50
+	g, gerr := NewGauge(src, &GaugeOptions{Min: 40, Max: 80}, &LayerOptions{Resolution: 4})
+	if gerr != nil {
+		t.Fatal(gerr)
+	}
+
+	tests := []struct {
+		name  string
+		value float64
+		err   error
+		dt    time.Duration
+		want  []color.RGBA64 // colors of the pixels
+	}{
+		{"start", 50, nil, 0, []color.RGBA64{green, {}, {}, {}}},
+		{"before interval", 70, nil, 500 * time.Millisecond, []color.RGBA64{green, {}, {}, {}}},
+		{"warning", 70, nil, 500 * time.Millisecond, []color.RGBA64{yellow, yellow, yellow, {}}},
+		{"critical", 76, nil, time.Second, []color.RGBA64{red, red, red, fade(red, 0.6)}},
+		{"above max", 100, nil, time.Second, []color.RGBA64{red, red, red, red}},
+		{"below min", 0, nil, time.Second, []color.RGBA64{{}, {}, {}, {}}},
+		{"error", 70, errors.New("fail"), time.Second, []color.RGBA64{{}, {}, {}, {}}},
+	}
+
+	for _, ts := range tests {
+		value, err = ts.value, ts.err
+		g.advance(ts.dt)
+		for i, want := range ts.want {
+			if got := g.pixel64(i); got != want {
+				t.Errorf("%s pixel %d got: %#v, want: %#v", ts.name, i, got, want)
+			}
+		}
+	}
+	if got := g.Err(); got == nil {
+		t.Errorf("got: %v, want: error", got)
+	}
+	if got, want := g.Value(), 0.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
+
+func TestGaugeOnRing(t *testing.T) {
+	value := 50.0
+	src := ValueFunc(func() (float64, error) { return value, nil })
+	g, err := NewGauge(src, &GaugeOptions{Min: 40, Max: 80}, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, dev := newTestRing(t, 4)
+	r.AddEffect(g)
+	if got, want := len(r.Layers()), 1; got != want {
+		t.Errorf("got: %d layers, want: %d", got, want)
+	}
+
+	tests := []struct {
+		value float64
+		want  int // lit LEDs
+	}{
+		{50, 1},
+		{80, 4},
+	}
+
+	for _, ts := range tests {
+		value = ts.value
+		r.advance(time.Second)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		lit := 0
+		for _, c := range dev.leds {
+			if c != 0 {
+				lit++
+			}
+		}
+		if lit != ts.want {
+			t.Errorf("value %v got: %d LEDs lit, want: %d", ts.value, lit, ts.want)
+		}
+	}
+}
+
+func TestSystemValues(t *testing.T) {
+	f, err := ioutil.TempFile("", "proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+
+	tests := []struct {
+		name string
+		data string
+		read func(path string) (float64, error)
+		want float64
+	}{
+		{"load", "0.52 0.58 0.59 1/467 12345\n", readLoad, 0.52},
+		{"memory", "MemTotal:        4000 kB\nMemFree:          500 kB\nMemAvailable:    1000 kB\n", readMemory, 0.75},
+		{"temperature", "48312\n", readTemperature, 48.312},
+	}
+
+	for _, ts := range tests {
+		if err := ioutil.WriteFile(f.Name(), []byte(ts.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ts.read(f.Name())
+		if err != nil {
+			t.Errorf("%s: %v", ts.name, err)
+			continue
+		}
+		if math.Abs(got-ts.want) > 1e-9 {
+			t.Errorf("%s got: %v, want: %v", ts.name, got, ts.want)
+		}
+	}
+}
@@ -1,5 +1,25 @@
 module github.com/cgxeiji/ring
 
-go 1.14
+go 1.17
 
-require github.com/rpi-ws281x/rpi-ws281x-go v1.0.5
+require (
+	github.com/gorilla/websocket v1.4.2
+	github.com/prometheus/client_golang v1.10.0
+	github.com/rpi-ws281x/rpi-ws281x-go v1.0.5
+	go.starlark.net v0.0.0-20230302034142-4b1e35fe2254
+	gopkg.in/yaml.v2 v2.4.0
+	periph.io/x/conn/v3 v3.7.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/golang/protobuf v1.4.3 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/prometheus/client_model v0.2.0 // indirect
+	github.com/prometheus/common v0.18.0 // indirect
+	github.com/prometheus/procfs v0.6.0 // indirect
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
@@ -0,0 +1,156 @@
+package ring
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Layer can be drawn with the image/draw package.
+var _ draw.Image = (*Layer)(nil)
+
+// NewImageLayer creates a new layer with its pixels sampled from an image.
+//
+// If the image is a single row of pixels, it is sampled as a strip, from left
+// to right. Otherwise, it is sampled along the largest circle that fits in the
+// image, starting at the top and going clockwise, so the first pixel of the
+// layer shows the top of the image.
+func NewImageLayer(img image.Image, options *LayerOptions) (*Layer, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := img.Bounds()
+	n := options.Resolution
+	for i := range l.pixels {
+		var x, y int
+		if b.Dy() == 1 {
+			x = b.Min.X + int((float64(i)+0.5)*float64(b.Dx())/float64(n))
+			y = b.Min.Y
+		} else {
+			cx := float64(b.Min.X) + float64(b.Dx())/2
+			cy := float64(b.Min.Y) + float64(b.Dy())/2
+			radius := math.Min(float64(b.Dx()), float64(b.Dy()))/2 - 0.5
+			angle := 2 * math.Pi * float64(i) / float64(n)
+			x = int(math.Floor(cx + radius*math.Sin(angle)))
+			y = int(math.Floor(cy - radius*math.Cos(angle)))
+		}
+		l.pixels[i] = toRGBA64(img.At(x, y))
+	}
+	l.update()
+
+	return l, nil
+}
+
+// ColorModel returns the color model of the layer, so it can be used as an
+// image.Image.
+func (l *Layer) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+// Bounds returns the bounds of the layer as a 1-pixel-high image, with a
+// column per pixel of the layer.
+func (l *Layer) Bounds() image.Rectangle {
+	return image.Rect(0, 0, l.opt.Resolution, 1)
+}
+
+// At returns the color of the pixel at column x, before layer
+// transformations. Points outside of the bounds are transparent.
+func (l *Layer) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(l.Bounds())) {
+		return color.RGBA64{}
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.pixels[x]
+}
+
+// Set sets the color of the pixel at column x, so the layer can be used as a
+// draw.Image. Points outside of the bounds are ignored.
+func (l *Layer) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(l.Bounds())) {
+		return
+	}
+	l.SetPixel(x, c)
+}
+
+// Ring can be encoded with the image packages, such as image/png.
+var _ image.Image = (*Ring)(nil)
+
+// ColorModel returns the color model of the ring, so it can be used as an
+// image.Image.
+func (r *Ring) ColorModel() color.Model {
+	return color.RGBA64Model
+}
+
+// Bounds returns the bounds of the ring as a 1-pixel-high image, with a column
+// per LED, by physical position.
+func (r *Ring) Bounds() image.Rectangle {
+	return image.Rect(0, 0, r.Size(), 1)
+}
+
+// At returns the color of the LED at column x in the last rendered frame (see
+// Snapshot), as an opaque color, since transparent pixels are shown as black.
+// Points outside of the bounds are transparent.
+func (r *Ring) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(r.Bounds())) {
+		return color.RGBA64{}
+	}
+
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	return opaque(r.shown[x])
+}
+
+// CircleImage draws the last rendered frame (see Snapshot) as a circle of LEDs
+// on a black square image of a size, in pixels. The first LED is at the top,
+// and the others follow clockwise, the way NewImageLayer samples images.
+func (r *Ring) CircleImage(size int) *image.RGBA {
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	return circleImage(r.shown, size)
+}
+
+// circleImage draws the colors of the LEDs of a ring as a circle on a square
+// image (see Ring.CircleImage).
+func circleImage(shown []color.RGBA64, size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.Black, image.Point{}, draw.Src)
+
+	n := len(shown)
+	arc := 2 * math.Pi / float64(n)
+	center := float64(size) / 2
+	radius := 0.85 * center
+	dot := math.Min(0.8*radius*math.Sin(arc/2), 0.12*center)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-center, float64(y)+0.5-center
+			angle := math.Atan2(dx, -dy)
+			i := mod(int(math.Round(angle/arc)), n)
+			ledX := center + radius*math.Sin(float64(i)*arc)
+			ledY := center - radius*math.Cos(float64(i)*arc)
+			cover := dot - math.Hypot(float64(x)+0.5-ledX, float64(y)+0.5-ledY) + 0.5
+			if cover <= 0 {
+				continue
+			}
+			img.Set(x, y, opaque(fade(shown[i], math.Min(cover, 1))))
+		}
+	}
+
+	return img
+}
+
+// opaque returns an alpha pre-multiplied color blended over black.
+func opaque(c color.RGBA64) color.RGBA64 {
+	c.A = 0xFFFF
+	return c
+}
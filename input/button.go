@@ -0,0 +1,261 @@
+package input
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+
+	"github.com/cgxeiji/ring"
+)
+
+// ButtonOptions is the list of options of a button.
+type ButtonOptions struct {
+	// Debounce is how long the level of the pin must be stable to change
+	// the state of the button, so the bounces of its contacts are ignored
+	// (default: 20 milliseconds).
+	Debounce time.Duration
+	// LongPress is how long the button must be held for a long press
+	// (default: 1 second).
+	LongPress time.Duration
+	// ActiveHigh is set for buttons wired to the supply voltage, read with a
+	// pull-down resistor (default: false, buttons wired to ground, read with
+	// a pull-up resistor).
+	ActiveHigh bool
+	// Clock times the presses (default: nil, the clock of the system). Set
+	// it to the clock of the ring (see ring.Ring.Clock) to test with a fake
+	// clock.
+	Clock ring.Clock
+}
+
+// Button reads a push button wired to a GPIO pin, and calls functions when it
+// is pressed, such as the actions NextPreset, TogglePower and Flash:
+//
+//	b, err := input.NewButton(gpioreg.ByName("GPIO22"), nil)
+//	...
+//	b.OnPress(input.NextPreset(r, presets))
+//	b.OnLongPress(input.TogglePower(r))
+type Button struct {
+	p   Pin
+	opt ButtonOptions
+
+	mu      sync.Mutex
+	raw     bool      // last level read, true if pressed
+	changed time.Time // time of the last change of raw
+	pressed bool      // debounced state
+	since   time.Time // time when the button was pressed
+	long    bool      // the current press is a long press
+	onPress []func()
+	onLong  []func()
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewButton sets up the pin of a button as an input, and starts reading it.
+// Call Close to stop.
+func NewButton(p Pin, options *ButtonOptions) (*Button, error) {
+	b := &Button{
+		p:    p,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if options != nil {
+		b.opt = *options
+	}
+	if b.opt.Debounce <= 0 {
+		b.opt.Debounce = 20 * time.Millisecond
+	}
+	if b.opt.LongPress <= 0 {
+		b.opt.LongPress = time.Second
+	}
+	if b.opt.Clock == nil {
+		b.opt.Clock = ring.SystemClock()
+	}
+
+	pull := gpio.PullUp
+	if b.opt.ActiveHigh {
+		pull = gpio.PullDown
+	}
+	if err := p.In(pull, gpio.BothEdges); err != nil {
+		return nil, fmt.Errorf("input: could not set up button: %w", err)
+	}
+	b.raw = b.read()
+	b.pressed = b.raw
+	b.long = b.raw // a button held at start is not a press
+
+	go b.run()
+
+	return b, nil
+}
+
+// OnPress calls f when the button is released after a short press. f is
+// called from the goroutine reading the pin, so it must not block.
+func (b *Button) OnPress(f func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onPress = append(b.onPress, f)
+}
+
+// OnLongPress calls f once the button is held for ButtonOptions.LongPress,
+// without waiting for it to be released. The release of a long press is not a
+// press.
+func (b *Button) OnLongPress(f func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onLong = append(b.onLong, f)
+}
+
+// Close stops reading the button.
+func (b *Button) Close() {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	<-b.done
+}
+
+func (b *Button) read() bool {
+	return (b.p.Read() == gpio.High) == b.opt.ActiveHigh
+}
+
+func (b *Button) run() {
+	defer close(b.done)
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		default:
+		}
+		b.p.WaitForEdge(b.timeout(b.opt.Clock.Now()))
+		b.update(b.read(), b.opt.Clock.Now())
+	}
+}
+
+// timeout returns how long to wait for an edge before the state of the button
+// must be updated at time now.
+func (b *Button) timeout(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	d := pollTimeout
+	if b.raw != b.pressed {
+		if settle := b.changed.Add(b.opt.Debounce).Sub(now); settle < d {
+			d = settle
+		}
+	}
+	if b.pressed && !b.long {
+		if hold := b.since.Add(b.opt.LongPress).Sub(now); hold < d {
+			d = hold
+		}
+	}
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+
+	return d
+}
+
+// update changes the state of the button with the level read at time now,
+// and calls the functions of the presses.
+func (b *Button) update(pressed bool, now time.Time) {
+	b.mu.Lock()
+	if pressed != b.raw {
+		b.raw = pressed
+		b.changed = now
+	}
+
+	var fs []func()
+	if b.raw != b.pressed && now.Sub(b.changed) >= b.opt.Debounce {
+		b.pressed = b.raw
+		if b.pressed {
+			b.since = b.changed
+			b.long = false
+		} else if !b.long {
+			fs = b.onPress
+		}
+	}
+	if b.pressed && !b.long && now.Sub(b.since) >= b.opt.LongPress {
+		b.long = true
+		fs = b.onLong
+	}
+	b.mu.Unlock()
+
+	for _, f := range fs {
+		f()
+	}
+}
+
+// NextPreset returns an action that replaces the effects it added to a ring
+// with the effects of the next preset of a store, in order, starting with the
+// first preset.
+func NextPreset(r *ring.Ring, presets *ring.PresetStore) func() {
+	var mu sync.Mutex
+	var effects []ring.Pixeler
+	next := 0
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ps := presets.Presets()
+		if len(ps) == 0 {
+			return
+		}
+		if next >= len(ps) {
+			next = 0
+		}
+		es, err := ps[next].Effects(ring.DefaultRegistry, r.Size())
+		next++
+		if err != nil {
+			return
+		}
+		for _, e := range effects {
+			r.RemoveEffect(e)
+		}
+		for _, e := range es {
+			r.AddEffect(e)
+		}
+		effects = es
+	}
+}
+
+// TogglePower returns an action that turns the LEDs of a ring off, by setting
+// its brightness to 0, and back on to the previous brightness.
+func TogglePower(r *ring.Ring) func() {
+	var mu sync.Mutex
+	bright := 0
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if b := r.Brightness(); b > 0 {
+			bright = b
+			r.SetBrightness(0)
+			return
+		}
+		if bright == 0 {
+			bright = 255
+		}
+		r.SetBrightness(bright)
+	}
+}
+
+// Flash returns an action that flashes a ring with a color for a duration,
+// by tinting it (see ring.Ring.SetTint), as a notification that the button
+// was pressed. The tint of the ring is cleared after the flash, timed by the
+// clock of the ring (see ring.Ring.Clock).
+func Flash(r *ring.Ring, c color.Color, d time.Duration) func() {
+	return func() {
+		r.SetTint(c, 1)
+		done := r.Clock().After(d)
+		go func() {
+			<-done
+			r.SetTint(color.Transparent, 0)
+		}()
+	}
+}
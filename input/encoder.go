@@ -0,0 +1,227 @@
+// Package input reads physical controls wired to the GPIO pins of the
+// computer driving a ring, such as rotary encoders and push buttons, and binds
+// them to the ring, since rings are often used as the feedback of a knob.
+//
+// Pins are periph.io GPIO inputs, such as from gpioreg.ByName once the host
+// drivers are initialized with host.Init from periph.io/x/host:
+//
+//	e, err := input.NewEncoder(gpioreg.ByName("GPIO17"), gpioreg.ByName("GPIO27"), nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer e.Close()
+//	input.BindBrightness(e, r, 8)
+package input
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Pin is a GPIO input, such as a gpio.PinIn of periph.io.
+type Pin interface {
+	// In sets up the pin as an input, with a pull resistor and the edges
+	// detected by WaitForEdge.
+	In(pull gpio.Pull, edge gpio.Edge) error
+	// Read returns the level of the pin.
+	Read() gpio.Level
+	// WaitForEdge waits for the next edge, or for timeout, and reports
+	// whether there was an edge.
+	WaitForEdge(timeout time.Duration) bool
+}
+
+// pollTimeout is how long the pins wait for an edge before checking whether
+// they were closed.
+const pollTimeout = 100 * time.Millisecond
+
+// accelPeriod is the time between detents below which turns are accelerated.
+const accelPeriod = 100 * time.Millisecond
+
+// transitions maps the previous and current levels of the pins of an encoder,
+// as prev<<2 | cur with a bit set for each low pin and A in the high bit, to
+// the step they make. A leads B when turning clockwise. Invalid transitions,
+// such as bounces that skip a state, make no step.
+var transitions = [16]int{0, -1, 1, 0, 1, 0, 0, -1, -1, 0, 0, 1, 0, 1, -1, 0}
+
+// EncoderOptions is the list of options of a rotary encoder.
+type EncoderOptions struct {
+	// StepsPerDetent is the number of quadrature steps between the detents,
+	// or clicks, of the encoder (default: 4).
+	StepsPerDetent int
+	// Acceleration multiplies the detents of fast turns, faster than 10
+	// detents per second, by up to this factor, so a quick spin covers a
+	// long range while slow turns stay precise (default: 1, no
+	// acceleration).
+	Acceleration float64
+	// Clock times the turns for the acceleration (default: nil, the clock
+	// of the system). Set it to the clock of the ring (see ring.Ring.Clock)
+	// to test with a fake clock.
+	Clock ring.Clock
+}
+
+// Encoder reads a quadrature rotary encoder wired to two GPIO pins, with the
+// common pin to ground. Swap the pins to reverse the direction.
+type Encoder struct {
+	a, b Pin
+	opt  EncoderOptions
+
+	mu       sync.Mutex
+	state    uint8 // pins that are low, A in the high bit
+	steps    int   // steps since the last detent
+	last     time.Time
+	handlers []func(detents int)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewEncoder sets up the pins of an encoder as inputs with pull-up resistors,
+// and starts reading them. Call Close to stop.
+func NewEncoder(a, b Pin, options *EncoderOptions) (*Encoder, error) {
+	e := &Encoder{
+		a:    a,
+		b:    b,
+		stop: make(chan struct{}),
+	}
+	if options != nil {
+		e.opt = *options
+	}
+	if e.opt.StepsPerDetent <= 0 {
+		e.opt.StepsPerDetent = 4
+	}
+	if e.opt.Acceleration < 1 {
+		e.opt.Acceleration = 1
+	}
+	if e.opt.Clock == nil {
+		e.opt.Clock = ring.SystemClock()
+	}
+
+	for _, p := range []Pin{a, b} {
+		if err := p.In(gpio.PullUp, gpio.BothEdges); err != nil {
+			return nil, fmt.Errorf("input: could not set up encoder: %w", err)
+		}
+	}
+	e.state = e.read()
+
+	for _, p := range []Pin{a, b} {
+		e.wg.Add(1)
+		go e.watch(p)
+	}
+
+	return e, nil
+}
+
+// OnTurn calls f after each turn of the encoder, with the number of detents
+// turned: positive clockwise and negative counter-clockwise, accelerated for
+// fast turns. f is called from the goroutine reading the pins, so it must not
+// block.
+func (e *Encoder) OnTurn(f func(detents int)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.handlers = append(e.handlers, f)
+}
+
+// Close stops reading the encoder.
+func (e *Encoder) Close() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	e.wg.Wait()
+}
+
+// watch updates the encoder on each edge of a pin, until the encoder is
+// closed.
+func (e *Encoder) watch(p Pin) {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		default:
+		}
+		if p.WaitForEdge(pollTimeout) {
+			e.update(e.opt.Clock.Now())
+		}
+	}
+}
+
+func (e *Encoder) read() uint8 {
+	var s uint8
+	if e.a.Read() == gpio.Low {
+		s |= 2
+	}
+	if e.b.Read() == gpio.Low {
+		s |= 1
+	}
+	return s
+}
+
+// update reads the pins at time now, and calls the handlers if the encoder
+// reached a detent.
+func (e *Encoder) update(now time.Time) {
+	e.mu.Lock()
+	cur := e.read()
+	e.steps += transitions[e.state<<2|cur]
+	e.state = cur
+
+	detents := e.steps / e.opt.StepsPerDetent
+	if detents == 0 {
+		e.mu.Unlock()
+		return
+	}
+	e.steps -= detents * e.opt.StepsPerDetent
+
+	if !e.last.IsZero() {
+		if dt := now.Sub(e.last); dt < accelPeriod {
+			factor := e.opt.Acceleration
+			if dt > 0 {
+				factor = math.Min(factor, float64(accelPeriod)/float64(dt))
+			}
+			detents = int(math.Round(float64(detents) * factor))
+		}
+	}
+	e.last = now
+	handlers := e.handlers
+	e.mu.Unlock()
+
+	for _, f := range handlers {
+		f(detents)
+	}
+}
+
+// BindOffset turns the ring with the encoder by step radians per detent, by
+// changing its offset (see ring.Ring.Offset), starting from no offset.
+// Clockwise turns rotate the ring clockwise.
+func BindOffset(e *Encoder, r *ring.Ring, step float64) {
+	var mu sync.Mutex
+	var offset float64
+	r.Offset(0)
+	e.OnTurn(func(detents int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		offset = math.Mod(offset-step*float64(detents), 2*math.Pi)
+		r.Offset(offset)
+	})
+}
+
+// BindBrightness changes the brightness of the ring by step levels per
+// detent, brighter clockwise, from 0 to 255 (see ring.Ring.SetBrightness).
+func BindBrightness(e *Encoder, r *ring.Ring, step int) {
+	var mu sync.Mutex
+	e.OnTurn(func(detents int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		r.SetBrightness(r.Brightness() + step*detents)
+	})
+}
@@ -0,0 +1,140 @@
+package input
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// HeadingProvider tells which way a device faces, such as from an IMU or a
+// magnetometer.
+type HeadingProvider interface {
+	// Heading returns the heading of the device, in degrees clockwise from
+	// north.
+	Heading() (float64, error)
+}
+
+// HeadingOptions is the list of options of a heading lock.
+type HeadingOptions struct {
+	// Interval is the time between readings of the heading (default: 20
+	// milliseconds).
+	Interval time.Duration
+	// Smoothing filters the noise of the readings, from 0.0 (no filter,
+	// default) to just below 1.0 (heavy filter, slow to follow turns).
+	Smoothing float64
+	// Mount is the heading of the first LED of the ring when the device
+	// faces north, in degrees clockwise, to correct how the ring is mounted
+	// (default: 0).
+	Mount float64
+}
+
+// HeadingLock keeps the layers of a ring pointing the same way while the
+// device turns, by offsetting the ring by the heading of the device (see
+// ring.Ring.Offset), so a marker on the first pixel of a layer always points
+// north, such as on a robot or a wearable.
+type HeadingLock struct {
+	r   *ring.Ring
+	h   HeadingProvider
+	opt HeadingOptions
+
+	mu      sync.Mutex
+	heading float64 // filtered heading, in degrees
+	started bool
+	err     error
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// LockHeading starts reading the heading of a device and offsetting the ring
+// by it, at the intervals of the clock of the ring (see ring.Ring.Clock). Call
+// Stop to stop.
+func LockHeading(r *ring.Ring, h HeadingProvider, options *HeadingOptions) *HeadingLock {
+	l := &HeadingLock{
+		r:    r,
+		h:    h,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if options != nil {
+		l.opt = *options
+	}
+	if l.opt.Interval <= 0 {
+		l.opt.Interval = 20 * time.Millisecond
+	}
+	l.opt.Smoothing = math.Max(0, math.Min(0.99, l.opt.Smoothing))
+
+	go l.run()
+
+	return l
+}
+
+// Heading returns the filtered heading of the device, in degrees clockwise
+// from north, from 0 to 360.
+func (l *HeadingLock) Heading() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.heading
+}
+
+// Err returns the error of the last reading of the heading, if it failed.
+// Failed readings leave the offset of the ring unchanged.
+func (l *HeadingLock) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.err
+}
+
+// Stop stops offsetting the ring, leaving its current offset.
+func (l *HeadingLock) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+}
+
+func (l *HeadingLock) run() {
+	defer close(l.done)
+
+	ticker := l.r.Clock().NewTicker(l.opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		l.update()
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// update reads the heading and offsets the ring.
+func (l *HeadingLock) update() {
+	h, err := l.h.Heading()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.err = err
+	if err != nil {
+		return
+	}
+	if !l.started {
+		l.heading = h
+		l.started = true
+	} else {
+		// Filter along the shortest turn, so 359 to 1 degrees is a turn of
+		// 2 degrees.
+		diff := math.Mod(h-l.heading+540, 360) - 180
+		l.heading += diff * (1 - l.opt.Smoothing)
+	}
+	l.heading = math.Mod(l.heading+360, 360)
+
+	l.r.OffsetDegrees(l.heading + l.opt.Mount)
+}
@@ -0,0 +1,332 @@
+package input
+
+import (
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+// fakePin is a pin whose level is set by tests.
+type fakePin struct {
+	mu    sync.Mutex
+	level gpio.Level
+	edges chan struct{}
+}
+
+func newFakePin() *fakePin {
+	return &fakePin{level: gpio.High, edges: make(chan struct{}, 16)}
+}
+
+func (p *fakePin) In(pull gpio.Pull, edge gpio.Edge) error {
+	return nil
+}
+
+func (p *fakePin) Read() gpio.Level {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.level
+}
+
+func (p *fakePin) WaitForEdge(timeout time.Duration) bool {
+	select {
+	case <-p.edges:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (p *fakePin) set(l gpio.Level) {
+	p.mu.Lock()
+	p.level = l
+	p.mu.Unlock()
+}
+
+// turn sets the levels of the pins of an encoder through a quadrature cycle,
+// clockwise or counter-clockwise, calling update after each change.
+func turn(a, b *fakePin, clockwise bool, update func()) {
+	first, second := a, b
+	if !clockwise {
+		first, second = b, a
+	}
+	for _, step := range []struct {
+		p *fakePin
+		l gpio.Level
+	}{{first, gpio.Low}, {second, gpio.Low}, {first, gpio.High}, {second, gpio.High}} {
+		step.p.set(step.l)
+		update()
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	a, b := newFakePin(), newFakePin()
+	e, err := NewEncoder(a, b, &EncoderOptions{Acceleration: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Close()
+
+	var got []int
+	e.OnTurn(func(detents int) { got = append(got, detents) })
+
+	now := time.Now()
+	tests := []struct {
+		clockwise bool
+		after     time.Duration
+		want      int
+	}{
+		{true, time.Second, 1},
+		{true, time.Second, 1},
+		{false, time.Second, -1},
+		{false, 50 * time.Millisecond, -2},
+		{false, 10 * time.Millisecond, -4},
+		{true, 200 * time.Millisecond, 1},
+	}
+
+	for i, ts := range tests {
+		now = now.Add(ts.after)
+		turn(a, b, ts.clockwise, func() { e.update(now) })
+		if len(got) != i+1 || got[i] != ts.want {
+			t.Errorf("turn %d got: %v, want: %d", i, got, ts.want)
+		}
+	}
+
+	// Bounces of a pin make no turns.
+	n := len(got)
+	for i := 0; i < 5; i++ {
+		a.set(gpio.Low)
+		e.update(now)
+		a.set(gpio.High)
+		e.update(now)
+	}
+	if len(got) != n {
+		t.Errorf("bounces got: %v, want: no turns", got[n:])
+	}
+}
+
+func TestBindings(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4, MaxBrightness: 100})
+	a, b := newFakePin(), newFakePin()
+	e, err := NewEncoder(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	BindBrightness(e, r, 10)
+
+	edge := func() {
+		a.edges <- struct{}{}
+		time.Sleep(5 * time.Millisecond)
+	}
+	turn(a, b, true, edge)
+	turn(a, b, true, edge)
+	turn(a, b, false, edge)
+
+	deadline := time.Now().Add(time.Second)
+	for r.Brightness() != 110 {
+		if time.Now().After(deadline) {
+			t.Fatalf("got: %d, want: 110", r.Brightness())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestButton(t *testing.T) {
+	p := newFakePin()
+	b, err := NewButton(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+
+	var got []string
+	b.OnPress(func() { got = append(got, "press") })
+	b.OnLongPress(func() { got = append(got, "long") })
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		pressed bool
+		after   time.Duration
+		want    []string
+	}{
+		{"press", true, 0, nil},
+		{"bounce", false, 5 * time.Millisecond, nil},
+		{"bounce back", true, 5 * time.Millisecond, nil},
+		{"settle", true, 20 * time.Millisecond, nil},
+		{"release", false, 100 * time.Millisecond, nil},
+		{"released", false, 20 * time.Millisecond, []string{"press"}},
+		{"hold", true, time.Second, []string{"press"}},
+		{"held", true, 500 * time.Millisecond, []string{"press"}},
+		{"long", true, 500 * time.Millisecond, []string{"press", "long"}},
+		{"still held", true, time.Second, []string{"press", "long"}},
+		{"release long", false, 0, []string{"press", "long"}},
+		{"released long", false, 20 * time.Millisecond, []string{"press", "long"}},
+	}
+
+	for _, ts := range tests {
+		now = now.Add(ts.after)
+		b.update(ts.pressed, now)
+		if len(got) != len(ts.want) {
+			t.Errorf("%s got: %v, want: %v", ts.name, got, ts.want)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	presets, err := ring.OpenPresets(filepath.Join(dir, "presets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{"red", "blue"} {
+		p := &ring.Preset{Name: c, Layers: []ring.PresetLayer{{Effect: "solid", Params: map[string]string{"color": c}}}}
+		if err := presets.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock := ringtest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 2, MaxBrightness: 255, Clock: clock})
+	next := NextPreset(r, presets)
+	power := TogglePower(r)
+	flash := Flash(r, color.White, time.Minute)
+
+	tests := []struct {
+		name   string
+		action func()
+		want   uint32
+	}{
+		{"first preset", next, 0xFF0000},
+		{"next preset", next, 0x0000FF},
+		{"first again", next, 0xFF0000},
+		{"off", power, 0},
+		{"on", power, 0xFF0000},
+		{"flash", flash, 0xFFFFFF},
+		{"during flash", func() { clock.Advance(59 * time.Second) }, 0xFFFFFF},
+		{"after flash", func() { clock.Advance(time.Second) }, 0xFF0000},
+	}
+
+	for _, ts := range tests {
+		ts.action()
+		// The flash is cleared by another goroutine once the clock fires.
+		deadline := time.Now().Add(time.Second)
+		for {
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+			got := dev.Frame()
+			if got[0] == ts.want && got[1] == ts.want {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if got := len(r.Layers()); got != 1 {
+			t.Errorf("%s got: %d layers, want: 1", ts.name, got)
+		}
+	}
+}
+
+// headings is a heading provider that returns a list of readings.
+type headings struct {
+	mu       sync.Mutex
+	readings []float64
+}
+
+func (h *headings) Heading() (float64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.readings) == 0 {
+		return 0, errors.New("no reading")
+	}
+	v := h.readings[0]
+	h.readings = h.readings[1:]
+	return v, nil
+}
+
+func TestHeadingLock(t *testing.T) {
+	newRing := func() (*ring.Ring, *ringtest.Device) {
+		r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 4, MaxBrightness: 255})
+		l, err := ring.NewLayer(&ring.LayerOptions{Resolution: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.SetPixel(0, color.White)
+		r.AddLayer(l)
+		return r, dev
+	}
+
+	r, dev := newRing()
+	h := &headings{readings: []float64{350}}
+	l := LockHeading(r, h, &HeadingOptions{Smoothing: 0.5, Interval: time.Hour})
+	l.Stop()
+
+	tests := []struct {
+		reading float64
+		want    float64
+		wantErr bool
+	}{
+		{10, 0, false},
+		{90, 45, false},
+		{0, 45, true},
+	}
+
+	for _, ts := range tests {
+		if !ts.wantErr {
+			h.readings = append(h.readings, ts.reading)
+		}
+		l.update()
+		if (l.Err() != nil) != ts.wantErr {
+			t.Errorf("%v got: %v, want error: %v", ts.reading, l.Err(), ts.wantErr)
+		}
+		if got := l.Heading(); math.Abs(got-ts.want) > 1e-9 {
+			t.Errorf("%v got: %v, want: %v", ts.reading, got, ts.want)
+		}
+	}
+
+	want, wantDev := newRing()
+	want.OffsetDegrees(45)
+	for _, r := range []*ring.Ring{r, want} {
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := dev.Frame(), wantDev.Frame(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+}
+
+func TestHeadingLockClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4, Clock: clock})
+	h := &headings{readings: []float64{10, 90}}
+	l := LockHeading(r, h, nil)
+
+	clock.WaitForTickers(1)
+	clock.Advance(20 * time.Millisecond)
+	l.Stop()
+	if got, want := l.Heading(), 90.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+}
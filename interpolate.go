@@ -0,0 +1,114 @@
+package ring
+
+import (
+	"image/color"
+	"time"
+)
+
+// SetUpdateRate sets how many times per second Run advances the animations
+// and animated layers of the ring, for animations that are expensive to
+// update or that update at a low rate, such as a clock. If the ring renders
+// at a higher frame rate, frames between updates blend the last two updates
+// (see blendLerp), so slow animations move smoothly at high frame rates, one
+// update behind. Layers changed directly show at the next update. A rate of 0
+// (the default) updates the animations on every frame, without blending.
+func (r *Ring) SetUpdateRate(hz float64) {
+	var period time.Duration
+	if hz > 0 {
+		period = time.Duration(float64(time.Second) / hz)
+	}
+
+	r.mu.Lock()
+	r.updatePeriod = period
+	r.dirty = true
+	r.mu.Unlock()
+
+	r.renderMu.Lock()
+	r.sinceUpdate = 0
+	r.prevFrame, r.nextFrame = nil, nil
+	r.renderMu.Unlock()
+}
+
+// UpdateRate returns how many times per second Run advances the animations, or
+// 0 if they advance on every frame.
+func (r *Ring) UpdateRate() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.updatePeriod == 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(r.updatePeriod)
+}
+
+// tick advances the animations of the ring by dt, on every call or at the
+// update rate (see SetUpdateRate).
+func (r *Ring) tick(dt time.Duration) {
+	r.mu.Lock()
+	period, step := r.updatePeriod, r.timestep
+	r.mu.Unlock()
+
+	if period <= 0 {
+		r.advanceSteps(dt, step)
+		return
+	}
+
+	r.renderMu.Lock()
+	r.sinceUpdate += dt
+	updates := int(r.sinceUpdate / period)
+	r.sinceUpdate -= time.Duration(updates) * period
+	r.renderMu.Unlock()
+
+	for i := 0; i < updates; i++ {
+		r.advanceSteps(period, step)
+		r.snapshot()
+	}
+
+	r.renderMu.Lock()
+	r.alpha = float64(r.sinceUpdate) / float64(period)
+	moving := r.moving
+	r.renderMu.Unlock()
+
+	if moving || updates > 0 {
+		r.markDirty()
+	}
+}
+
+// snapshot blends the layers into the frame of the latest update, keeping the
+// previous one to blend from.
+func (r *Ring) snapshot() {
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	r.mu.Lock()
+	s := r.state()
+	r.mu.Unlock()
+
+	first := r.nextFrame == nil
+	if first {
+		r.prevFrame = make([]color.RGBA64, len(r.pixels))
+		r.nextFrame = make([]color.RGBA64, len(r.pixels))
+	}
+	r.prevFrame, r.nextFrame = r.nextFrame, r.prevFrame
+	r.moving = false
+	for i := range r.nextFrame {
+		r.nextFrame[i] = composite(s.layers, i, r.Size(), r.opt.LinearBlending)
+		if first {
+			r.prevFrame[i] = r.nextFrame[i]
+		}
+		r.moving = r.moving || r.prevFrame[i] != r.nextFrame[i]
+	}
+}
+
+// blendPixels blends the layers of a state into r.pixels, or the last two
+// updates if updating at a lower rate than rendering. It must be called with
+// r.renderMu held.
+func (r *Ring) blendPixels(s frameState) {
+	for i := range r.pixels {
+		if r.nextFrame != nil {
+			r.pixels[i] = blendLerp(r.prevFrame[i], r.nextFrame[i], r.alpha)
+		} else {
+			r.pixels[i] = composite(s.layers, i, r.Size(), r.opt.LinearBlending)
+		}
+	}
+}
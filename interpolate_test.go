@@ -0,0 +1,51 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestUpdateRate(t *testing.T) {
+	r, dev := newTestRing(t, 1)
+	l := newTestLayer(t, &LayerOptions{Resolution: 1})
+	l.SetAll(color.Black)
+	l.FadeTo(color.White, time.Second)
+	r.AddLayer(l)
+	r.SetUpdateRate(2)
+	if got, want := r.UpdateRate(), 2.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		dt   time.Duration
+		want uint32
+	}{
+		{"first update", 500 * time.Millisecond, 0x7F7F7F},
+		{"between still", 250 * time.Millisecond, 0x7F7F7F},
+		{"second update", 250 * time.Millisecond, 0x7F7F7F},
+		{"between moving", 250 * time.Millisecond, 0xBFBFBF},
+		{"third update", 250 * time.Millisecond, 0xFFFFFF},
+	}
+
+	for _, ts := range tests {
+		r.tick(ts.dt)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		if got := dev.leds[0]; got != ts.want {
+			t.Errorf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+		}
+	}
+
+	r.SetUpdateRate(0)
+	l.SetAll(color.Black)
+	r.tick(time.Millisecond)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.leds[0], uint32(0); got != want {
+		t.Errorf("every frame got: %#x, want: %#x", got, want)
+	}
+}
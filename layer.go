@@ -4,27 +4,57 @@ import (
 	"fmt"
 	"image/color"
 	"math"
+	"sync"
+	"time"
 )
 
 // Layer represents a drawable layer of the LED ring.
 type Layer struct {
-	pixels []color.Color
+	pixels []color.RGBA64
 
 	pixArc   float64 // pixel arc in radians
+	angle    float64 // rotation in radians
 	rotFloat float64 // float part of rotation in radians
 	rotInt   int     // integer part of rotation in radians
+	shift    int     // content shift in whole pixels
+	spin     float64 // angular velocity in radians per second
+	opacity  float64 // from 0.0 (transparent) to 1.0 (opaque)
 
 	opt    *LayerOptions
-	buffer []color.Color
+	buffer []color.RGBA64
+	kernel []float64      // weights of the blur, from -Blur to Blur
+	blur   []color.RGBA64 // scratch buffer of the blur
+	trans  *transition    // color transition in progress, if any
+
+	mu    sync.RWMutex // guards pixels, buffer, rotation, shift, stale and ver
+	ver   uint64       // incremented every time the buffer changes
+	stale bool         // the buffer needs to be updated from the pixels
 }
 
 // LayerOptions is the list of options of a layer.
 type LayerOptions struct {
+	// Name identifies the layer, to find it in a ring with Ring.Layer
+	// (default: "", no name).
+	Name string
 	// Resolution sets the number of pixels a layer has. Usually, this is set
 	// to the same number of LEDs the ring has.
 	Resolution int
 	// ContentMode sets how the layer will be rendered (default: Tile).
 	ContentMode ContentMode
+	// LinearBlending blends the layer over the layers below it, and its pixels
+	// when rotated, in linear light instead of in sRGB (default: false).
+	LinearBlending bool
+	// WrapIndices wraps the indices of SetPixel around the layer, like Pixel
+	// does, so -1 sets the last pixel and Resolution sets the first one.
+	// Otherwise, SetPixel panics on indices out of range (default: false).
+	WrapIndices bool
+	// Reverse renders the pixels of the layer in the opposite direction, for
+	// content drawn for a ring that runs the other way. Pixel 0 stays in place
+	// and pixel i is shown at -i (default: false).
+	Reverse bool
+	// Blur is the radius, in pixels, of a gaussian blur applied around the
+	// layer, which gives a soft glow to lit pixels (default: 0, no blur).
+	Blur int
 }
 
 // ContentMode defines how the layer will be rendered.
@@ -41,17 +71,24 @@ const (
 	ContentScale
 )
 
-// NewLayer creates a new drawable layer.
-func NewLayer(options *LayerOptions) (*Layer, error) {
-	if options.Resolution == 0 {
-		return nil, fmt.Errorf("ring: resolution of new layer is 0")
+// NewLayer creates a new drawable layer with given options, applied in order.
+// The options can be set at once with a *LayerOptions, or one by one with
+// functional options, such as WithResolution.
+func NewLayer(opts ...LayerOption) (*Layer, error) {
+	options, err := buildLayerOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if options.Resolution <= 0 {
+		return nil, ErrZeroResolution
 	}
 
 	l := &Layer{
-		pixels: make([]color.Color, options.Resolution),
-		buffer: make([]color.Color, options.Resolution),
-		pixArc: 2 * math.Pi / float64(options.Resolution),
-		opt:    options,
+		pixels:  make([]color.RGBA64, options.Resolution),
+		buffer:  make([]color.RGBA64, options.Resolution),
+		pixArc:  2 * math.Pi / float64(options.Resolution),
+		opacity: 1,
+		opt:     options,
 	}
 	l.SetAll(color.Transparent)
 	l.update()
@@ -61,21 +98,205 @@ func NewLayer(options *LayerOptions) (*Layer, error) {
 
 // SetAll sets all the pixels of a layer to an uniform color.
 func (l *Layer) SetAll(c color.Color) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c64 := toRGBA64(c)
 	for i := range l.pixels {
-		l.pixels[i] = c
+		l.pixels[i] = c64
 	}
 	l.update()
 }
 
-// SetPixel sets the color of a single pixel in the layer.
+// SetPixel sets the color of a single pixel in the layer. It panics if i is
+// out of range, unless the layer wraps its indices (see
+// LayerOptions.WrapIndices). Use SetPixelChecked for indices that may be out
+// of range.
 func (l *Layer) SetPixel(i int, c color.Color) {
-	l.pixels[i] = c
+	if err := l.SetPixelChecked(i, c); err != nil {
+		panic(err)
+	}
+}
+
+// SetPixelChecked is like SetPixel, but returns ErrPixelOutOfRange instead of
+// panicking if i is out of range.
+func (l *Layer) SetPixelChecked(i int, c color.Color) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.opt.WrapIndices {
+		i = mod(i, l.opt.Resolution)
+	}
+	if i < 0 || i >= len(l.pixels) {
+		return fmt.Errorf("%w: pixel %d of %d", ErrPixelOutOfRange, i, len(l.pixels))
+	}
+
+	l.pixels[i] = toRGBA64(c)
+	l.update()
+
+	return nil
+}
+
+// SetPixels sets the color of many pixels, indexed by position, updating the
+// layer once. If any index is out of range, unless the layer wraps its indices
+// (see LayerOptions.WrapIndices), it returns ErrPixelOutOfRange and leaves the
+// layer unchanged.
+func (l *Layer) SetPixels(pixels map[int]color.Color) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.opt.WrapIndices {
+		for i := range pixels {
+			if i < 0 || i >= len(l.pixels) {
+				return fmt.Errorf("%w: pixel %d of %d", ErrPixelOutOfRange, i, len(l.pixels))
+			}
+		}
+	}
+	for i, c := range pixels {
+		if l.opt.WrapIndices {
+			i = mod(i, l.opt.Resolution)
+		}
+		l.pixels[i] = toRGBA64(c)
+	}
+	l.update()
+
+	return nil
+}
+
+// SetRange sets the pixels from index from up to, but not including, index to
+// to an uniform color, updating the layer once. If the range is out of the
+// layer, unless the layer wraps its indices (see LayerOptions.WrapIndices), it
+// returns ErrPixelOutOfRange and leaves the layer unchanged.
+func (l *Layer) SetRange(from, to int, c color.Color) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.opt.WrapIndices && from < to && (from < 0 || to > len(l.pixels)) {
+		return fmt.Errorf("%w: pixels %d to %d of %d", ErrPixelOutOfRange, from, to, len(l.pixels))
+	}
+	c64 := toRGBA64(c)
+	for i := from; i < to; i++ {
+		if l.opt.WrapIndices {
+			l.pixels[mod(i, l.opt.Resolution)] = c64
+		} else {
+			l.pixels[i] = c64
+		}
+	}
+	l.update()
+
+	return nil
+}
+
+// Mirror copies the pixels of the first half of the layer onto the second
+// half, mirrored around pixel 0, so pixel -i has the color of pixel i. It
+// builds symmetric content, such as kaleidoscopes, by drawing only one half.
+func (l *Layer) Mirror() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.opt.Resolution
+	for i := 1; i < (n+1)/2; i++ {
+		l.pixels[n-i] = l.pixels[i]
+	}
 	l.update()
 }
 
+// SetPixelAt sets the color of the pixel closest to an angle (in radians),
+// measured from the first pixel in the direction of the pixel indices.
+func (l *Layer) SetPixelAt(angle float64, c color.Color) {
+	l.SetPixel(mod(int(math.Round(angle/l.pixArc)), l.opt.Resolution), c)
+}
+
+// SetPixelAtDegrees is like SetPixelAt, with the angle in degrees.
+func (l *Layer) SetPixelAtDegrees(angle float64, c color.Color) {
+	l.SetPixelAt(radians(angle), c)
+}
+
+// DrawAt paints a point at an angle (in radians), measured from the first
+// pixel in the direction of the pixel indices, over the current pixels. A
+// point between two pixels is anti-aliased across both, so it moves smoothly
+// when drawn at changing angles.
+func (l *Layer) DrawAt(angle float64, c color.Color) {
+	pos := angle / l.pixArc
+	l.drawSpan(pos-0.5, pos+0.5, solid(c))
+}
+
+// DrawAtDegrees is like DrawAt, with the angle in degrees.
+func (l *Layer) DrawAtDegrees(angle float64, c color.Color) {
+	l.DrawAt(radians(angle), c)
+}
+
+// DrawArc paints an arc from angle start to angle end (in radians), in the
+// direction of the pixel indices, over the current pixels. The pixels at the
+// ends of the arc are anti-aliased by how much of them the arc covers.
+func (l *Layer) DrawArc(start, end float64, c color.Color) {
+	if end < start {
+		end += 2 * math.Pi
+	}
+	l.drawSpan(start/l.pixArc, end/l.pixArc, solid(c))
+}
+
+// DrawArcDegrees is like DrawArc, with the angles in degrees.
+func (l *Layer) DrawArcDegrees(start, end float64, c color.Color) {
+	l.DrawArc(radians(start), radians(end), c)
+}
+
+// drawSpan paints the pixels between positions from and to, each pixel i
+// covering the positions from i-0.5 to i+0.5. The color of each pixel is
+// given by paint, at the relative position t of its center in the span, from
+// 0.0 to 1.0.
+func (l *Layer) drawSpan(from, to float64, paint func(t float64) color.RGBA64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.opt.Resolution
+	if to-from > float64(n) {
+		to = from + float64(n)
+	}
+	for i := int(math.Floor(from + 0.5)); float64(i)-0.5 < to; i++ {
+		cover := math.Min(to, float64(i)+0.5) - math.Max(from, float64(i)-0.5)
+		if cover <= 0 {
+			continue
+		}
+		t := 0.0
+		if to > from {
+			t = math.Max(0, math.Min(1, (float64(i)-from)/(to-from)))
+		}
+		p := mod(i, n)
+		l.pixels[p] = blendOver(l.pixels[p], fade(paint(t), math.Min(cover, 1)))
+	}
+	l.update()
+}
+
+// dim fades all the pixels of the layer towards transparent by a factor, from
+// 0.0 (transparent) to 1.0 (unchanged), such as for trails.
+func (l *Layer) dim(factor float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.pixels {
+		l.pixels[i] = fade(l.pixels[i], factor)
+	}
+	l.update()
+}
+
+// solid returns a paint function of a single color.
+func solid(c color.Color) func(float64) color.RGBA64 {
+	c64 := toRGBA64(c)
+	return func(float64) color.RGBA64 { return c64 }
+}
+
 // Rotate sets the rotation of the layer. A positive angle makes a counter-clockwise rotation.
 func (l *Layer) Rotate(angle float64) {
-	rotArc := angle / l.pixArc
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rotate(angle)
+}
+
+func (l *Layer) rotate(angle float64) {
+	l.angle = math.Mod(angle, 2*math.Pi)
+	rotArc := l.angle / l.pixArc
 	rotInt := math.Floor(rotArc)
 	l.rotFloat = rotArc - rotInt
 	l.rotInt = int(rotInt)
@@ -83,10 +304,132 @@ func (l *Layer) Rotate(angle float64) {
 	l.update()
 }
 
+// SetOpacity sets the opacity of the whole layer, from 0.0 (transparent) to
+// 1.0 (opaque, default). It is applied on top of the transparency of each
+// pixel.
+func (l *Layer) SetOpacity(opacity float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.opacity = math.Max(0, math.Min(1, opacity))
+	l.update()
+}
+
+// Opacity returns the opacity of the whole layer.
+func (l *Layer) Opacity() float64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.opacity
+}
+
+// Spin sets the layer to rotate continuously at an angular velocity (in
+// radians per second) while the ring runs (see Ring.Run). A positive velocity
+// makes a counter-clockwise rotation, and 0 stops the rotation.
+func (l *Layer) Spin(velocity float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.spin = velocity
+}
+
+// transition is a gradual change of the colors of the pixels of a layer.
+type transition struct {
+	from, to []color.RGBA64
+	elapsed  time.Duration
+	duration time.Duration
+}
+
+// FadeTo changes the color of all the pixels of the layer to a color
+// gradually over a duration, while the ring runs (see Ring.Run). Pixels drawn
+// while fading are overwritten by the fade.
+func (l *Layer) FadeTo(c color.Color, d time.Duration) {
+	c64 := toRGBA64(c)
+	to := make([]color.RGBA64, l.opt.Resolution)
+	for i := range to {
+		to[i] = c64
+	}
+	l.fadeTo(to, d)
+}
+
+// FadeAllTo is like FadeTo, with a color for each pixel of the layer.
+func (l *Layer) FadeAllTo(colors []color.Color, d time.Duration) {
+	to := make([]color.RGBA64, l.opt.Resolution)
+	for i := range to {
+		if i < len(colors) {
+			to[i] = toRGBA64(colors[i])
+		}
+	}
+	l.fadeTo(to, d)
+}
+
+func (l *Layer) fadeTo(to []color.RGBA64, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d <= 0 {
+		copy(l.pixels, to)
+		l.trans = nil
+		l.update()
+		return
+	}
+	l.trans = &transition{
+		from:     append([]color.RGBA64(nil), l.pixels...),
+		to:       to,
+		duration: d,
+	}
+}
+
+// advance rotates a spinning layer by the angle covered in dt, and moves its
+// color transition forward.
+func (l *Layer) advance(dt time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f := l.trans; f != nil {
+		f.elapsed += dt
+		t := math.Min(1, float64(f.elapsed)/float64(f.duration))
+		for i := range l.pixels {
+			l.pixels[i] = blendLerp(f.from[i], f.to[i], t)
+		}
+		if t == 1 {
+			l.trans = nil
+		}
+		if l.spin == 0 {
+			l.update()
+			return
+		}
+	}
+	if l.spin == 0 {
+		return
+	}
+	l.rotate(l.angle + l.spin*dt.Seconds())
+}
+
+// Shift moves the content of the layer by n whole pixels in the direction of
+// the pixel indices, without interpolating between pixels, on top of its
+// rotation. Shifts add up, so calling Shift(1) on every frame scrolls the
+// content like a marquee.
+func (l *Layer) Shift(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.shift = mod(l.shift+n, l.opt.Resolution)
+	l.update()
+}
+
+// RotateDegrees is like Rotate, with the angle in degrees.
+func (l *Layer) RotateDegrees(angle float64) {
+	l.Rotate(radians(angle))
+}
+
 // pixelRotated returns the color of the pixel at position i adjusted for the
 // rotation of the layer.
-func (l *Layer) pixelRotated(i int) (c color.Color) {
-	i += l.rotInt
+func (l *Layer) pixelRotated(i int) (c color.RGBA64) {
+	i += l.rotInt - l.shift
+	if l.opt.LinearBlending {
+		return fromLinear(blendLerp(toLinear(l.pixelRaw(i)), toLinear(l.pixelRaw(i+1)), l.rotFloat))
+	}
 	c = blendLerp(l.pixelRaw(i), l.pixelRaw(i+1), l.rotFloat)
 
 	return c
@@ -95,6 +438,24 @@ func (l *Layer) pixelRotated(i int) (c color.Color) {
 // Pixel returns the color of the pixel at position i, with layer
 // transformations.
 func (l *Layer) Pixel(i int) (c color.Color) {
+	return l.pixel64(i)
+}
+
+// pixel64 is like Pixel, but returns the internal representation of the
+// color.
+func (l *Layer) pixel64(i int) color.RGBA64 {
+	l.mu.RLock()
+	if l.stale {
+		l.mu.RUnlock()
+		l.mu.Lock()
+		if l.stale {
+			l.refresh()
+		}
+		l.mu.Unlock()
+		l.mu.RLock()
+	}
+	defer l.mu.RUnlock()
+
 	return l.buffer[mod(i, l.opt.Resolution)]
 }
 
@@ -103,17 +464,100 @@ func (l *Layer) Options() *LayerOptions {
 	return l.opt
 }
 
+// update marks the buffer as changed. The buffer is updated once when it is
+// next read, so drawing many pixels one by one, such as with draw.Draw, costs
+// a single update. It must be called with mu held.
 func (l *Layer) update() {
+	l.stale = true
+	l.ver++
+}
+
+// refresh updates the buffer from the pixels, rotation, blur and opacity of
+// the layer. It must be called with mu held.
+func (l *Layer) refresh() {
 	for i := range l.pixels {
-		l.buffer[i] = l.pixelRotated(i)
+		if l.opt.Reverse {
+			l.buffer[i] = l.pixelRotated(-i)
+		} else {
+			l.buffer[i] = l.pixelRotated(i)
+		}
 	}
+	if l.opt.Blur > 0 {
+		l.applyBlur()
+	}
+	if l.opacity < 1 {
+		for i := range l.buffer {
+			l.buffer[i] = fade(l.buffer[i], l.opacity)
+		}
+	}
+	l.stale = false
+}
+
+// applyBlur convolves the buffer with a gaussian kernel, wrapping around the
+// layer.
+func (l *Layer) applyBlur() {
+	r := l.opt.Blur
+	if len(l.kernel) != 2*r+1 {
+		l.kernel = gaussianKernel(r)
+		l.blur = make([]color.RGBA64, len(l.buffer))
+	}
+
+	n := len(l.buffer)
+	for i := range l.buffer {
+		var cr, cg, cb, ca float64
+		for k, w := range l.kernel {
+			c := l.buffer[mod(i+k-r, n)]
+			cr += w * float64(c.R)
+			cg += w * float64(c.G)
+			cb += w * float64(c.B)
+			ca += w * float64(c.A)
+		}
+		l.blur[i] = color.RGBA64{
+			uint16(math.Round(cr)),
+			uint16(math.Round(cg)),
+			uint16(math.Round(cb)),
+			uint16(math.Round(ca)),
+		}
+	}
+	copy(l.buffer, l.blur)
+}
+
+// gaussianKernel returns the normalized weights of a gaussian blur of radius
+// r, with a standard deviation of half the radius.
+func gaussianKernel(r int) []float64 {
+	sigma := float64(r) / 2
+	k := make([]float64, 2*r+1)
+	sum := 0.0
+	for i := range k {
+		x := float64(i - r)
+		k[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		sum += k[i]
+	}
+	for i := range k {
+		k[i] /= sum
+	}
+
+	return k
+}
+
+// version returns the number of times the layer has changed.
+func (l *Layer) version() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.ver
 }
 
 // pixelRaw returns the color of the pixelRaw at position i.
-func (l *Layer) pixelRaw(i int) (c color.Color) {
+func (l *Layer) pixelRaw(i int) (c color.RGBA64) {
 	return l.pixels[mod(i, l.opt.Resolution)]
 }
 
+// radians converts an angle from degrees to radians.
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
 func mod(p, n int) (r int) {
 	r = p % n
 	if r < 0 {
@@ -0,0 +1,278 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MaxStrobeFrequency is the highest number of flashes per second of Strobe
+// and of Lightning, counting each flicker of its strikes. Faster flashing can
+// trigger seizures in people with photosensitive epilepsy (see WCAG 2.3.1,
+// three flashes).
+const MaxStrobeFrequency = 3.0
+
+// Lightning is a layer with flashes of lightning at random times and places,
+// each of one to three flickers of an arc of the ring that glows after them as
+// it fades out. Flickers, of the same or of different strikes, are never
+// closer than the safe limit of MaxStrobeFrequency. Add it to a ring with
+// AddLayer and run the ring (see Ring.Run) to animate it. Its parameters are
+// "color", "intensity" in strikes per second, and "decay" in seconds (see
+// Tunable).
+type Lightning struct {
+	*Layer
+	params
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	color     color.Color
+	intensity float64   // strikes per second
+	decay     float64   // seconds for the afterglow to fade out
+	since     float64   // seconds since the last flicker
+	flickers  []float64 // seconds between the next flickers of the strike
+	center    float64   // center of the strike in pixels
+	width     float64   // width of the strike in pixels
+}
+
+// NewLightning creates a storm of lightning of a color, with a strike every 2
+// seconds on average, and an afterglow that fades out in 0.3 seconds.
+func NewLightning(c color.Color, options *LayerOptions) (*Lightning, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Lightning{
+		Layer:     l,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		color:     c,
+		intensity: 0.5,
+		decay:     0.3,
+		since:     1 / MaxStrobeFrequency,
+	}
+	g.params = params{specs: lightningParams, get: g.param, set: g.setParam}
+
+	return g, nil
+}
+
+// SetColor sets the color of the flashes.
+func (g *Lightning) SetColor(c color.Color) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.color = c
+}
+
+// SetIntensity sets how many strikes there are per second on average, up to
+// MaxStrobeFrequency.
+func (g *Lightning) SetIntensity(perSecond float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.intensity = math.Min(perSecond, MaxStrobeFrequency)
+}
+
+// SetDecay sets the time for the afterglow of the flashes to fade out.
+func (g *Lightning) SetDecay(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.decay = d.Seconds()
+}
+
+var lightningParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#e0e8ff", Doc: "color of the flashes"},
+	{Name: "intensity", Type: ParamFloat, Default: "0.5", Doc: "strikes per second of the storm", Min: 0, Max: MaxStrobeFrequency},
+	{Name: "decay", Type: ParamFloat, Default: "0.3", Doc: "seconds for the afterglow to fade out", Min: 0, Max: 5},
+}
+
+func (g *Lightning) param(name string) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch name {
+	case "color":
+		return g.color
+	case "intensity":
+		return g.intensity
+	case "decay":
+		return g.decay
+	}
+	return nil
+}
+
+func (g *Lightning) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		g.SetColor(v.(color.Color))
+	case "intensity":
+		g.SetIntensity(v.(float64))
+	case "decay":
+		g.SetDecay(time.Duration(v.(float64) * float64(time.Second)))
+	}
+}
+
+func (g *Lightning) advance(dt time.Duration) {
+	g.mu.Lock()
+	s := dt.Seconds()
+	factor := 0.0
+	if g.decay > 0 {
+		factor = math.Pow(trailFloor, s/g.decay)
+	}
+	g.dim(factor)
+
+	g.since += s
+	// Strikes come at random, as a Poisson process, but never closer to the
+	// last flicker than the safe limit.
+	if len(g.flickers) == 0 && g.since >= 1/MaxStrobeFrequency && g.rand.Float64() < 1-math.Exp(-g.intensity*s) {
+		g.strike()
+	}
+	flash := false
+	if len(g.flickers) > 0 && g.since >= g.flickers[0] {
+		g.flickers = g.flickers[1:]
+		g.since = 0
+		flash = true
+	}
+	var c color.RGBA64
+	if flash {
+		c = fade(toRGBA64(g.color), 0.5+0.5*g.rand.Float64())
+	}
+	from, to := g.center-g.width/2, g.center+g.width/2
+	g.mu.Unlock()
+
+	if flash {
+		g.drawSpan(from, to, func(float64) color.RGBA64 { return c })
+	}
+	g.Layer.advance(dt)
+}
+
+// strike starts a strike of lightning, at a random place of the ring, with one
+// to three flickers, the first one right away. It must be called with mu held.
+func (g *Lightning) strike() {
+	n := float64(g.opt.Resolution)
+	g.center = g.rand.Float64() * n
+	g.width = n * (0.125 + 0.375*g.rand.Float64())
+	g.flickers = append(g.flickers[:0], 0)
+	for i := g.rand.Intn(3); i > 0; i-- {
+		g.flickers = append(g.flickers, (1+0.5*g.rand.Float64())/MaxStrobeFrequency)
+	}
+}
+
+// Strobe is a layer that flashes a color at a regular frequency, up to
+// MaxStrobeFrequency. Add it to a ring with AddLayer and run the ring (see
+// Ring.Run) to animate it. Its parameters are "color", "frequency" in flashes
+// per second, and "duty", the fraction of each period that it is lit (see
+// Tunable).
+type Strobe struct {
+	*Layer
+	params
+
+	mu        sync.Mutex
+	color     color.Color
+	frequency float64 // flashes per second
+	duty      float64 // fraction of the period lit
+	phase     float64 // fraction of the current period elapsed
+}
+
+// NewStrobe creates a strobe of a color that flashes twice per second, lit a
+// tenth of the time.
+func NewStrobe(c color.Color, options *LayerOptions) (*Strobe, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Strobe{
+		Layer:     l,
+		color:     c,
+		frequency: 2,
+		duty:      0.1,
+	}
+	s.params = params{specs: strobeParams, get: s.param, set: s.setParam}
+	s.paint()
+
+	return s, nil
+}
+
+// SetColor sets the color of the flashes.
+func (s *Strobe) SetColor(c color.Color) {
+	s.mu.Lock()
+	s.color = c
+	s.mu.Unlock()
+
+	s.paint()
+}
+
+// SetFrequency sets how many times per second the strobe flashes, up to
+// MaxStrobeFrequency.
+func (s *Strobe) SetFrequency(hz float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.frequency = math.Max(0, math.Min(hz, MaxStrobeFrequency))
+}
+
+// SetDuty sets the fraction of each period that the strobe is lit, from 0.0
+// to 1.0.
+func (s *Strobe) SetDuty(duty float64) {
+	s.mu.Lock()
+	s.duty = duty
+	s.mu.Unlock()
+
+	s.paint()
+}
+
+var strobeParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#ffffff", Doc: "color of the flashes"},
+	{Name: "frequency", Type: ParamFloat, Default: "2", Doc: "flashes per second", Min: 0, Max: MaxStrobeFrequency},
+	{Name: "duty", Type: ParamFloat, Default: "0.1", Doc: "fraction of each period lit", Min: 0, Max: 1},
+}
+
+func (s *Strobe) param(name string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "color":
+		return s.color
+	case "frequency":
+		return s.frequency
+	case "duty":
+		return s.duty
+	}
+	return nil
+}
+
+func (s *Strobe) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		s.SetColor(v.(color.Color))
+	case "frequency":
+		s.SetFrequency(v.(float64))
+	case "duty":
+		s.SetDuty(v.(float64))
+	}
+}
+
+func (s *Strobe) advance(dt time.Duration) {
+	s.mu.Lock()
+	s.phase += s.frequency * dt.Seconds()
+	s.phase -= math.Floor(s.phase)
+	s.mu.Unlock()
+
+	s.paint()
+	s.Layer.advance(dt)
+}
+
+func (s *Strobe) paint() {
+	s.mu.Lock()
+	c := s.color
+	if s.phase >= s.duty {
+		c = color.Transparent
+	}
+	s.mu.Unlock()
+
+	s.SetAll(c)
+}
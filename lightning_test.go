@@ -0,0 +1,75 @@
+package ring
+
+import (
+	"image/color"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestLightning(t *testing.T) {
+	g, err := NewLightning(color.White, &LayerOptions{Resolution: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.rand = rand.New(rand.NewSource(1))
+	g.SetIntensity(100)
+	if got, want := g.Params()[1].Value, MaxStrobeFrequency; got != want {
+		t.Errorf("got: intensity %v, want: %v", got, want)
+	}
+
+	// Count the flashes, as the frames where a pixel gets brighter, and check
+	// the time between them.
+	prev := make([]uint32, 16)
+	flashes, last := 0, -1
+	for i := 0; i < 1000; i++ {
+		g.advance(10 * time.Millisecond)
+		flash := false
+		for j := 0; j < 16; j++ {
+			_, _, _, a := g.Pixel(j).RGBA()
+			if a > prev[j] {
+				flash = true
+			}
+			prev[j] = a
+		}
+		if !flash {
+			continue
+		}
+		if last >= 0 && float64(i-last)*0.01 < 1/MaxStrobeFrequency {
+			t.Errorf("got: flashes %dms apart, want: at least %.0fms", 10*(i-last), 1000/MaxStrobeFrequency)
+		}
+		flashes++
+		last = i
+	}
+	if flashes == 0 || flashes > 10*MaxStrobeFrequency {
+		t.Errorf("got: %d flashes in 10s, want: 1 to %v", flashes, 10*MaxStrobeFrequency)
+	}
+}
+
+func TestStrobe(t *testing.T) {
+	s, err := NewStrobe(color.White, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetFrequency(10)
+	s.SetDuty(0.5)
+
+	tests := []struct {
+		dt   time.Duration
+		want bool // lit
+	}{
+		{0, true},
+		{100 * time.Millisecond, true},
+		{100 * time.Millisecond, false},
+		{100 * time.Millisecond, false},
+		{50 * time.Millisecond, true},
+	}
+
+	for i, ts := range tests {
+		s.advance(ts.dt)
+		_, _, _, a := s.Pixel(0).RGBA()
+		if got := a > 0; got != ts.want {
+			t.Errorf("step %d got: lit %v, want: %v", i, got, ts.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+)
+
+var (
+	linearOnce   sync.Once
+	decodeLinear []uint16 // sRGB to linear light, by 16-bit value
+	encodeLinear []uint16 // linear light to sRGB, by 16-bit value
+)
+
+// initLinear builds the sRGB conversion tables the first time they are needed.
+func initLinear() {
+	linearOnce.Do(func() {
+		decodeLinear = make([]uint16, 0x10000)
+		encodeLinear = make([]uint16, 0x10000)
+		for i := range decodeLinear {
+			v := float64(i) / 0xFFFF
+			var dec, enc float64
+			if v <= 0.04045 {
+				dec = v / 12.92
+			} else {
+				dec = math.Pow((v+0.055)/1.055, 2.4)
+			}
+			if v <= 0.0031308 {
+				enc = v * 12.92
+			} else {
+				enc = 1.055*math.Pow(v, 1/2.4) - 0.055
+			}
+			decodeLinear[i] = uint16(math.Round(dec * 0xFFFF))
+			encodeLinear[i] = uint16(math.Round(enc * 0xFFFF))
+		}
+	})
+}
+
+// toLinear converts an alpha pre-multiplied sRGB color to linear light.
+func toLinear(c color.RGBA64) color.RGBA64 {
+	initLinear()
+	return convertPremultiplied(c, decodeLinear)
+}
+
+// fromLinear converts an alpha pre-multiplied color in linear light to sRGB.
+func fromLinear(c color.RGBA64) color.RGBA64 {
+	initLinear()
+	return convertPremultiplied(c, encodeLinear)
+}
+
+// convertPremultiplied applies a conversion table to the color channels of an
+// alpha pre-multiplied color.
+func convertPremultiplied(c color.RGBA64, table []uint16) color.RGBA64 {
+	if c.A == 0 {
+		return c
+	}
+	a := uint32(c.A)
+	convert := func(v uint16) uint16 {
+		straight := uint32(v) * 0xFFFF / a
+		if straight > 0xFFFF {
+			straight = 0xFFFF
+		}
+		return uint16(uint32(table[straight]) * a / 0xFFFF)
+	}
+
+	return color.RGBA64{
+		R: convert(c.R),
+		G: convert(c.G),
+		B: convert(c.B),
+		A: c.A,
+	}
+}
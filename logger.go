@@ -0,0 +1,26 @@
+package ring
+
+// Logger logs the events of a ring, such as the start of the device and
+// render errors, as a message followed by alternating keys and values. A
+// *slog.Logger of the log/slog package is a Logger.
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger is a logger that discards all the events.
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// logger returns the logger of the options, or a logger that discards all the
+// events if there is none.
+func (o *Options) logger() Logger {
+	if o.Logger == nil {
+		return nopLogger{}
+	}
+	return o.Logger
+}
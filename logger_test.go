@@ -0,0 +1,40 @@
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type testLogger struct {
+	logs []string
+}
+
+func (l *testLogger) log(level, msg string, args ...interface{}) {
+	l.logs = append(l.logs, fmt.Sprint(level, " ", msg, " ", args))
+}
+
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("INFO", msg, args...) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("WARN", msg, args...) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args...) }
+
+type errDevice struct{}
+
+func (errDevice) Render([]uint32) error { return errors.New("disconnected") }
+func (errDevice) Close()                {}
+
+func TestLogger(t *testing.T) {
+	log := &testLogger{}
+	r, err := NewWithDevice(errDevice{}, &Options{LedCount: 1, Logger: log})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Render(); err == nil {
+		t.Fatal("got: nil, want: error")
+	}
+
+	want := []string{"ERROR could not render frame [err disconnected]"}
+	if fmt.Sprint(log.logs) != fmt.Sprint(want) {
+		t.Errorf("got: %q, want: %q", log.logs, want)
+	}
+}
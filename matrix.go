@@ -0,0 +1,173 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Matrix represents a 2D LED panel. It uses the same blending as a Ring, with
+// 2D layers.
+type Matrix struct {
+	ring *Ring
+	opt  *MatrixOptions
+}
+
+// MatrixOptions is the list of matrix options.
+type MatrixOptions struct {
+	// Options configures the device. LedCount is set from the size of the
+	// matrix.
+	Options
+	// Width and Height are the number of LEDs of each row and column of the
+	// matrix.
+	Width, Height int
+	// Wiring sets the order in which the LEDs are connected (default:
+	// Progressive).
+	Wiring Wiring
+}
+
+// Wiring defines the order in which the LEDs of a matrix are connected. Rows
+// always start at the top-left corner of the matrix.
+type Wiring uint8
+
+const (
+	// WiringProgressive sets every row to be wired from left to right.
+	WiringProgressive Wiring = iota
+	// WiringSerpentine sets even rows to be wired from left to right and odd
+	// rows from right to left.
+	WiringSerpentine
+)
+
+// NewMatrix creates a new LED matrix with given options.
+func NewMatrix(options *MatrixOptions) (*Matrix, error) {
+	if options.Width <= 0 || options.Height <= 0 {
+		return nil, fmt.Errorf("ring: invalid matrix size %dx%d", options.Width, options.Height)
+	}
+	opt := *options
+	opt.LedCount = opt.Width * opt.Height
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	// The wiring of the matrix is applied before the pixel map of the device,
+	// and passed to New so the startup animation is wired too.
+	m := opt.pixelMap()
+	if opt.PixelMap != nil {
+		for i, led := range m {
+			m[i] = opt.PixelMap[led]
+		}
+	}
+	ropt := opt.Options
+	ropt.PixelMap = m
+
+	r, err := New(&ropt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Matrix{
+		ring: r,
+		opt:  &opt,
+	}, nil
+}
+
+// pixelMap returns the physical position of each pixel of the matrix, in
+// row-major order.
+func (o *MatrixOptions) pixelMap() []int {
+	m := make([]int, o.Width*o.Height)
+	for y := 0; y < o.Height; y++ {
+		for x := 0; x < o.Width; x++ {
+			led := y*o.Width + x
+			if o.Wiring == WiringSerpentine && y%2 == 1 {
+				led = y*o.Width + o.Width - 1 - x
+			}
+			m[y*o.Width+x] = led
+		}
+	}
+
+	return m
+}
+
+// Render updates the LED matrix.
+func (m *Matrix) Render() error {
+	return m.ring.Render()
+}
+
+// AddLayer adds a drawable layer to the matrix.
+func (m *Matrix) AddLayer(l *MatrixLayer) {
+	m.ring.AddLayer(l.layer)
+}
+
+// Close turns off the LED matrix and closes the device.
+func (m *Matrix) Close() {
+	m.ring.Close()
+}
+
+// TurnOff turns off the LED matrix without closing the device.
+func (m *Matrix) TurnOff() {
+	m.ring.TurnOff()
+}
+
+// SetBrightness sets the maximum output of the LEDs, from 0 to 255, without
+// reinitializing the device.
+func (m *Matrix) SetBrightness(level int) {
+	m.ring.SetBrightness(level)
+}
+
+// Brightness returns the maximum output of the LEDs.
+func (m *Matrix) Brightness() int {
+	return m.ring.Brightness()
+}
+
+// Width returns the number of LEDs of each row of the matrix.
+func (m *Matrix) Width() int {
+	return m.opt.Width
+}
+
+// Height returns the number of LEDs of each column of the matrix.
+func (m *Matrix) Height() int {
+	return m.opt.Height
+}
+
+// MatrixLayer represents a drawable layer of a LED matrix.
+type MatrixLayer struct {
+	layer         *Layer
+	width, height int
+}
+
+// NewMatrixLayer creates a new drawable layer for a matrix. The layer should
+// have the same size as the matrix, and is initialized with transparent
+// pixels.
+func NewMatrixLayer(width, height int) (*MatrixLayer, error) {
+	l, err := NewLayer(&LayerOptions{
+		Resolution:  width * height,
+		ContentMode: ContentCrop,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MatrixLayer{
+		layer:  l,
+		width:  width,
+		height: height,
+	}, nil
+}
+
+// SetAll sets all the pixels of a layer to an uniform color.
+func (l *MatrixLayer) SetAll(c color.Color) {
+	l.layer.SetAll(c)
+}
+
+// SetPixel sets the color of the pixel at column x and row y, starting at the
+// top-left corner. Pixels outside of the layer are ignored.
+func (l *MatrixLayer) SetPixel(x, y int, c color.Color) {
+	if x < 0 || x >= l.width || y < 0 || y >= l.height {
+		return
+	}
+	l.layer.SetPixel(y*l.width+x, c)
+}
+
+// Pixel returns the color of the pixel at column x and row y.
+func (l *MatrixLayer) Pixel(x, y int) color.Color {
+	return l.layer.Pixel(y*l.width + x)
+}
@@ -0,0 +1,84 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestMatrixPixelMap(t *testing.T) {
+	tests := []struct {
+		name   string
+		wiring Wiring
+		want   []int
+	}{
+		{
+			"progressive",
+			WiringProgressive,
+			[]int{0, 1, 2, 3, 4, 5},
+		},
+		{
+			"serpentine",
+			WiringSerpentine,
+			[]int{0, 1, 2, 5, 4, 3},
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			o := &MatrixOptions{Width: 3, Height: 2, Wiring: ts.wiring}
+			got := o.pixelMap()
+			for i := range ts.want {
+				if got[i] != ts.want[i] {
+					t.Errorf("got: %v, want: %v", got, ts.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewMatrixOptions(t *testing.T) {
+	useFakeWS2811(t, &fakeWS2811{})
+
+	options := &MatrixOptions{Width: 3, Height: 2}
+	m, err := NewMatrix(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if got := options.LedCount; got != 0 {
+		t.Errorf("got: LedCount %d, want: options unchanged", got)
+	}
+	if got, want := m.ring.Size(), 6; got != want {
+		t.Errorf("got: %d LEDs, want: %d", got, want)
+	}
+}
+
+func TestNewMatrixStartup(t *testing.T) {
+	dev := &fakeWS2811{}
+	useFakeWS2811(t, dev)
+
+	m, err := NewMatrix(&MatrixOptions{
+		Options: Options{
+			MaxBrightness: 255,
+			StartupAnimation: &PowerAnimation{Draw: func(l *Layer, t float64) {
+				l.SetPixel(3, color.White)
+			}},
+		},
+		Width:  3,
+		Height: 2,
+		Wiring: WiringSerpentine,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	// The first pixel of the second row is the last LED of the row.
+	want := []uint32{0, 0, 0, 0, 0, 0xFFFFFF}
+	for i := range want {
+		if got := dev.leds[0][i]; got != want[i] {
+			t.Errorf("LED %d got: %#x, want: %#x", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Meteor is a layer where meteors appear at random angles and orbit the ring
+// once, leaving trails that fade out pixel by pixel at random, like meteor
+// rain. Add it to a ring with AddLayer and run the ring (see Ring.Run) to
+// animate it. Its parameters are "color", "density" in meteors per second,
+// "speed" in degrees per second, "gravity" in degrees per second squared, and
+// "decay" in seconds (see Tunable).
+type Meteor struct {
+	*Layer
+	params
+
+	mu      sync.Mutex
+	rand    *rand.Rand
+	color   color.Color
+	density float64 // meteors per second
+	speed   float64 // initial angular velocity in radians per second
+	gravity float64 // angular acceleration in radians per second squared
+	decay   float64 // average seconds for a trail to fade out
+	spawn   float64 // meteors due
+	meteors []*meteor
+}
+
+// meteor is a meteor of a Meteor layer.
+type meteor struct {
+	pos      float64 // angle in radians
+	vel      float64 // angular velocity in radians per second
+	traveled float64 // angle traveled in radians
+}
+
+// NewMeteor creates a meteor rain of a color, with a meteor per second that
+// orbits the ring in 2 seconds, leaving trails that fade out in half a second
+// on average.
+func NewMeteor(c color.Color, options *LayerOptions) (*Meteor, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Meteor{
+		Layer:   l,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		color:   c,
+		density: 1,
+		speed:   math.Pi,
+		decay:   0.5,
+	}
+	m.params = params{specs: meteorParams, get: m.param, set: m.setParam}
+
+	return m, nil
+}
+
+// SetColor sets the color of the meteors.
+func (m *Meteor) SetColor(c color.Color) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.color = c
+}
+
+// SetDensity sets how many meteors appear per second.
+func (m *Meteor) SetDensity(perSecond float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.density = perSecond
+}
+
+// SetSpeed sets the angular velocity of new meteors (in radians per second),
+// in the direction of the pixel indices.
+func (m *Meteor) SetSpeed(velocity float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.speed = velocity
+}
+
+// SetGravity sets the angular acceleration of the meteors (in radians per
+// second squared), in the direction of their motion, so they speed up, or
+// slow down if negative.
+func (m *Meteor) SetGravity(acceleration float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.gravity = acceleration
+}
+
+// SetDecay sets the average time for the trails to fade out.
+func (m *Meteor) SetDecay(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decay = d.Seconds()
+}
+
+var meteorParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#ffffff", Doc: "color of the meteors"},
+	{Name: "density", Type: ParamFloat, Default: "1", Doc: "meteors per second", Min: 0, Max: 50},
+	{Name: "speed", Type: ParamFloat, Default: "180", Doc: "degrees per second", Min: -1440, Max: 1440},
+	{Name: "gravity", Type: ParamFloat, Default: "0", Doc: "degrees per second squared", Min: -1440, Max: 1440},
+	{Name: "decay", Type: ParamFloat, Default: "0.5", Doc: "average seconds for the trails to fade out", Min: 0, Max: 10},
+}
+
+func (m *Meteor) param(name string) interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch name {
+	case "color":
+		return m.color
+	case "density":
+		return m.density
+	case "speed":
+		return m.speed * 180 / math.Pi
+	case "gravity":
+		return m.gravity * 180 / math.Pi
+	case "decay":
+		return m.decay
+	}
+	return nil
+}
+
+func (m *Meteor) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		m.SetColor(v.(color.Color))
+	case "density":
+		m.SetDensity(v.(float64))
+	case "speed":
+		m.SetSpeed(radians(v.(float64)))
+	case "gravity":
+		m.SetGravity(radians(v.(float64)))
+	case "decay":
+		m.SetDecay(time.Duration(v.(float64) * float64(time.Second)))
+	}
+}
+
+func (m *Meteor) advance(dt time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := dt.Seconds()
+	m.fade(s)
+
+	m.spawn += m.density * s
+	for ; m.spawn >= 1; m.spawn-- {
+		m.meteors = append(m.meteors, &meteor{
+			pos: m.rand.Float64() * 2 * math.Pi,
+			vel: m.speed,
+		})
+	}
+
+	scale := float64(m.opt.Resolution) / (2 * math.Pi)
+	paint := solid(m.color)
+	alive := m.meteors[:0]
+	for _, x := range m.meteors {
+		from := x.pos
+		v := x.vel
+		if v < 0 {
+			x.vel -= m.gravity * s
+		} else {
+			x.vel += m.gravity * s
+		}
+		if v*x.vel < 0 {
+			x.vel = 0 // gravity does not turn meteors back
+		}
+		step := (v + x.vel) / 2 * s
+		x.pos += step
+		x.traveled += math.Abs(step)
+		lo, hi := math.Min(from, x.pos), math.Max(from, x.pos)
+		m.drawSpan(lo*scale-0.5, hi*scale+0.5, paint)
+		if x.traveled < 2*math.Pi && x.vel != 0 {
+			alive = append(alive, x)
+		}
+	}
+	for i := len(alive); i < len(m.meteors); i++ {
+		m.meteors[i] = nil
+	}
+	m.meteors = alive
+
+	m.Layer.advance(dt)
+}
+
+// fade fades each pixel by a random amount, so the trails fade out in decay
+// seconds on average and break up as they do. It must be called with mu held.
+func (m *Meteor) fade(s float64) {
+	factor := 0.0
+	if m.decay > 0 {
+		factor = math.Pow(trailFloor, s/m.decay)
+	}
+
+	l := m.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.pixels {
+		f := 0.0
+		if factor > 0 {
+			f = math.Pow(factor, 2*m.rand.Float64())
+		}
+		l.pixels[i] = fade(l.pixels[i], f)
+	}
+	l.update()
+}
@@ -0,0 +1,71 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestMeteor(t *testing.T) {
+	m, err := NewMeteor(color.White, &LayerOptions{Resolution: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.rand = rand.New(rand.NewSource(1))
+	m.SetDecay(0)
+	m.SetSpeed(math.Pi)
+
+	lit := func() int {
+		n := 0
+		for i := 0; i < 8; i++ {
+			if _, _, _, a := m.Pixel(i).RGBA(); a > 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	steps := 0
+	for lit() == 0 && steps < 20 {
+		m.advance(100 * time.Millisecond)
+		steps++
+	}
+	if steps < 10 || steps > 11 {
+		t.Errorf("got: first meteor after %d steps, want: 10 or 11", steps)
+	}
+	m.SetDensity(0)
+	if got := lit(); got > 3 {
+		t.Errorf("got: %d lit pixels, want: at most 3", got)
+	}
+	for i := 0; i < 25; i++ {
+		m.advance(100 * time.Millisecond)
+	}
+	if got := lit(); got != 0 {
+		t.Errorf("got: %d lit pixels after the orbit, want: 0", got)
+	}
+}
+
+func TestMeteorFade(t *testing.T) {
+	m, err := NewMeteor(color.White, &LayerOptions{Resolution: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.rand = rand.New(rand.NewSource(1))
+	m.SetDensity(0)
+	m.SetAll(color.White)
+	m.advance(100 * time.Millisecond)
+
+	seen := make(map[uint32]bool)
+	for i := 0; i < 8; i++ {
+		_, _, _, a := m.Pixel(i).RGBA()
+		if a == 0 || a == 0xFFFF {
+			t.Errorf("pixel %d got: alpha %#x, want: partly faded", i, a)
+		}
+		seen[a] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("got: %d different alphas, want: random fading", len(seen))
+	}
+}
@@ -0,0 +1,85 @@
+// Package metrics exports the statistics of rings as Prometheus metrics, for
+// rings installed as permanent displays.
+//
+//	r, err := ring.New(&ring.Options{LedCount: 12})
+//	...
+//	prometheus.MustRegister(metrics.NewCollector(r, "status"))
+//	http.Handle("/metrics", promhttp.Handler())
+//
+// The render metrics are measured by Ring.Run (see Ring.Stats).
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cgxeiji/ring"
+)
+
+var (
+	renderSeconds = prometheus.NewDesc(
+		"ring_render_seconds",
+		"Recent average duration of a frame.",
+		[]string{"ring"}, nil,
+	)
+	fps = prometheus.NewDesc(
+		"ring_frames_per_second",
+		"Recent number of frames rendered per second.",
+		[]string{"ring"}, nil,
+	)
+	frames = prometheus.NewDesc(
+		"ring_frames_total",
+		"Number of frames rendered.",
+		[]string{"ring"}, nil,
+	)
+	dropped = prometheus.NewDesc(
+		"ring_dropped_frames_total",
+		"Number of frames missed because previous frames took too long.",
+		[]string{"ring"}, nil,
+	)
+	current = prometheus.NewDesc(
+		"ring_current_amperes",
+		"Estimated current drawn by the LEDs.",
+		[]string{"ring"}, nil,
+	)
+	layers = prometheus.NewDesc(
+		"ring_layers",
+		"Number of layers of the ring.",
+		[]string{"ring"}, nil,
+	)
+)
+
+// Collector collects the metrics of a ring.
+type Collector struct {
+	ring *ring.Ring
+	name string
+}
+
+// NewCollector creates a collector of the metrics of a ring, labeled with a
+// name to tell rings apart.
+func NewCollector(r *ring.Ring, name string) *Collector {
+	return &Collector{
+		ring: r,
+		name: name,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- renderSeconds
+	ch <- fps
+	ch <- frames
+	ch <- dropped
+	ch <- current
+	ch <- layers
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.ring.Stats()
+	ch <- prometheus.MustNewConstMetric(renderSeconds, prometheus.GaugeValue, s.RenderTime.Seconds(), c.name)
+	ch <- prometheus.MustNewConstMetric(fps, prometheus.GaugeValue, s.FPS, c.name)
+	ch <- prometheus.MustNewConstMetric(frames, prometheus.CounterValue, float64(s.Frames), c.name)
+	ch <- prometheus.MustNewConstMetric(dropped, prometheus.CounterValue, float64(s.Dropped), c.name)
+	ch <- prometheus.MustNewConstMetric(current, prometheus.GaugeValue, float64(c.ring.EstimatedCurrent())/1000, c.name)
+	ch <- prometheus.MustNewConstMetric(layers, prometheus.GaugeValue, float64(len(c.ring.Layers())), c.name)
+}
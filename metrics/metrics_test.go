@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestCollector(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 1})
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetAll(color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `
+# HELP ring_current_amperes Estimated current drawn by the LEDs.
+# TYPE ring_current_amperes gauge
+ring_current_amperes{ring="test"} 0.06
+# HELP ring_layers Number of layers of the ring.
+# TYPE ring_layers gauge
+ring_layers{ring="test"} 1
+`
+	if err := testutil.CollectAndCompare(NewCollector(r, "test"), strings.NewReader(want), "ring_current_amperes", "ring_layers"); err != nil {
+		t.Error(err)
+	}
+}
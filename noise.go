@@ -0,0 +1,208 @@
+package ring
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Noise is a layer that paints smooth noise, changing over the angle and over
+// time, through a palette, for ambient effects such as lava or auroras. Add it
+// to a ring with AddLayer and run the ring (see Ring.Run) to animate it. Its
+// parameters are "palette", "scale" and "speed" (see Tunable).
+type Noise struct {
+	*Layer
+	params
+
+	mu      sync.Mutex
+	palette Palette
+	scale   float64 // radius of the ring in noise space
+	speed   float64 // noise units per second
+	t       float64 // time in noise space
+}
+
+// NewNoise creates a noise layer that paints through a palette, with a scale
+// of 1 and a speed of 0.5.
+func NewNoise(p Palette, options *LayerOptions) (*Noise, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Noise{
+		Layer:   l,
+		palette: p,
+		scale:   1,
+		speed:   0.5,
+	}
+	n.params = params{specs: noiseParams, get: n.param, set: n.setParam}
+	n.paint()
+
+	return n, nil
+}
+
+// SetScale sets the size of the features of the noise. Larger scales show
+// more, smaller blobs around the ring.
+func (n *Noise) SetScale(scale float64) {
+	n.mu.Lock()
+	n.scale = scale
+	n.mu.Unlock()
+
+	n.paint()
+}
+
+// SetSpeed sets how fast the noise changes over time.
+func (n *Noise) SetSpeed(speed float64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.speed = speed
+}
+
+// SetPalette sets the palette the noise is painted through.
+func (n *Noise) SetPalette(p Palette) {
+	n.mu.Lock()
+	n.palette = p
+	n.mu.Unlock()
+
+	n.paint()
+}
+
+var noiseParams = []ParamSpec{
+	{Name: "palette", Type: ParamPalette, Default: "ocean", Doc: "palette of the noise"},
+	{Name: "scale", Type: ParamFloat, Default: "1", Doc: "size of the features, larger is smaller", Min: 0.1, Max: 10},
+	{Name: "speed", Type: ParamFloat, Default: "0.5", Doc: "how fast the noise changes", Min: 0, Max: 5},
+}
+
+func (n *Noise) param(name string) interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch name {
+	case "palette":
+		return n.palette
+	case "scale":
+		return n.scale
+	case "speed":
+		return n.speed
+	}
+	return nil
+}
+
+func (n *Noise) setParam(name string, v interface{}) {
+	switch name {
+	case "palette":
+		n.SetPalette(v.(Palette))
+	case "scale":
+		n.SetScale(v.(float64))
+	case "speed":
+		n.SetSpeed(v.(float64))
+	}
+}
+
+func (n *Noise) advance(dt time.Duration) {
+	n.mu.Lock()
+	n.t += n.speed * dt.Seconds()
+	n.mu.Unlock()
+
+	n.paint()
+	n.Layer.advance(dt)
+}
+
+// paint samples the noise along a circle, so it wraps seamlessly around the
+// ring. The circle is offset from the lattice of the noise, where the noise is
+// always 0.
+func (n *Noise) paint() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	l := n.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.pixels {
+		a := float64(i) * l.pixArc
+		v := perlin(n.scale*math.Cos(a)+0.37, n.scale*math.Sin(a)+0.71, n.t)
+		l.pixels[i] = n.palette.at64((v + 1) / 2)
+	}
+	l.update()
+}
+
+// perm is the permutation table of Perlin's improved noise, repeated twice.
+var perm = func() [512]uint8 {
+	p := [256]uint8{151, 160, 137, 91, 90, 15, 131, 13, 201, 95, 96, 53, 194, 233, 7, 225,
+		140, 36, 103, 30, 69, 142, 8, 99, 37, 240, 21, 10, 23, 190, 6, 148,
+		247, 120, 234, 75, 0, 26, 197, 62, 94, 252, 219, 203, 117, 35, 11, 32,
+		57, 177, 33, 88, 237, 149, 56, 87, 174, 20, 125, 136, 171, 168, 68, 175,
+		74, 165, 71, 134, 139, 48, 27, 166, 77, 146, 158, 231, 83, 111, 229, 122,
+		60, 211, 133, 230, 220, 105, 92, 41, 55, 46, 245, 40, 244, 102, 143, 54,
+		65, 25, 63, 161, 1, 216, 80, 73, 209, 76, 132, 187, 208, 89, 18, 169,
+		200, 196, 135, 130, 116, 188, 159, 86, 164, 100, 109, 198, 173, 186, 3, 64,
+		52, 217, 226, 250, 124, 123, 5, 202, 38, 147, 118, 126, 255, 82, 85, 212,
+		207, 206, 59, 227, 47, 16, 58, 17, 182, 189, 28, 42, 223, 183, 170, 213,
+		119, 248, 152, 2, 44, 154, 163, 70, 221, 153, 101, 155, 167, 43, 172, 9,
+		129, 22, 39, 253, 19, 98, 108, 110, 79, 113, 224, 232, 178, 185, 112, 104,
+		218, 246, 97, 228, 251, 34, 242, 193, 238, 210, 144, 12, 191, 179, 162, 241,
+		81, 51, 145, 235, 249, 14, 239, 107, 49, 192, 214, 31, 181, 199, 106, 157,
+		184, 84, 204, 176, 115, 121, 50, 45, 127, 4, 150, 254, 138, 236, 205, 93,
+		222, 114, 67, 29, 24, 72, 243, 141, 128, 195, 78, 66, 215, 61, 156, 180,
+	}
+	var pp [512]uint8
+	for i := range pp {
+		pp[i] = p[i%256]
+	}
+	return pp
+}()
+
+// perlin returns Perlin's improved noise at a point, from about -1.0 to 1.0.
+func perlin(x, y, z float64) float64 {
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	xi, yi, zi := int(fx)&0xFF, int(fy)&0xFF, int(fz)&0xFF
+	x, y, z = x-fx, y-fy, z-fz
+	u, v, w := noiseFade(x), noiseFade(y), noiseFade(z)
+
+	a := int(perm[xi]) + yi
+	aa, ab := int(perm[a])+zi, int(perm[a+1])+zi
+	b := int(perm[xi+1]) + yi
+	ba, bb := int(perm[b])+zi, int(perm[b+1])+zi
+
+	return noiseLerp(w,
+		noiseLerp(v,
+			noiseLerp(u, grad(perm[aa], x, y, z), grad(perm[ba], x-1, y, z)),
+			noiseLerp(u, grad(perm[ab], x, y-1, z), grad(perm[bb], x-1, y-1, z))),
+		noiseLerp(v,
+			noiseLerp(u, grad(perm[aa+1], x, y, z-1), grad(perm[ba+1], x-1, y, z-1)),
+			noiseLerp(u, grad(perm[ab+1], x, y-1, z-1), grad(perm[bb+1], x-1, y-1, z-1))))
+}
+
+func noiseFade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func noiseLerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+// grad returns the dot product of a pseudorandom gradient, chosen by hash,
+// and the distance vector (x, y, z).
+func grad(hash uint8, x, y, z float64) float64 {
+	h := hash & 15
+	u := y
+	if h < 8 {
+		u = x
+	}
+	v := z
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+
+	return u + v
+}
@@ -0,0 +1,37 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPerlin(t *testing.T) {
+	if got := perlin(1, 2, 3); got != 0 {
+		t.Errorf("lattice point got: %v, want: 0", got)
+	}
+	for i := 0; i < 1000; i++ {
+		x := float64(i) * 0.137
+		v := perlin(x, x*0.5, x*0.25)
+		if v < -1 || v > 1 {
+			t.Fatalf("got: %v, want: value in [-1, 1]", v)
+		}
+		if d := math.Abs(perlin(x+0.001, x*0.5, x*0.25) - v); d > 0.01 {
+			t.Fatalf("got: step of %v, want: smooth noise", d)
+		}
+	}
+}
+
+func TestNoise(t *testing.T) {
+	n, err := NewNoise(NewPalette(color.Black, color.White), &LayerOptions{Resolution: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.SetScale(3)
+	before := n.pixel64(0)
+	n.advance(time.Second)
+	if after := n.pixel64(0); after == before {
+		t.Errorf("got: %v after advancing, want: changed color", after)
+	}
+}
@@ -0,0 +1,203 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NotifyPattern is the animation of a notification (see Ring.Notify). It is
+// drawn on a layer with one pixel per LED, transparent at the start of the
+// notification.
+type NotifyPattern struct {
+	// Duration is how long the notification plays.
+	Duration time.Duration
+	// Draw draws the frame of the notification at a progress t, from 0.0 to
+	// 1.0, on the layer.
+	Draw func(l *Layer, t float64)
+}
+
+// FlashPattern flashes all the LEDs with a color n times over a duration.
+func FlashPattern(c color.Color, n int, d time.Duration) *NotifyPattern {
+	return &NotifyPattern{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			if math.Mod(t*float64(n), 1) < 0.5 && t < 1 {
+				l.SetAll(c)
+			} else {
+				l.SetAll(color.Transparent)
+			}
+		},
+	}
+}
+
+// PulsePattern fades all the LEDs in and out of a color n times over a
+// duration.
+func PulsePattern(c color.Color, n int, d time.Duration) *NotifyPattern {
+	return &NotifyPattern{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			l.SetAll(c)
+			l.SetOpacity((1 - math.Cos(2*math.Pi*t*float64(n))) / 2)
+		},
+	}
+}
+
+// SpinPattern spins a quarter of the ring lit with a color around the ring n
+// times over a duration.
+func SpinPattern(c color.Color, n int, d time.Duration) *NotifyPattern {
+	return &NotifyPattern{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			size := float64(l.opt.Resolution)
+			head := t * float64(n) * size
+			l.SetAll(color.Transparent)
+			if t < 1 {
+				l.drawSpan(head-size/4-0.5, head-0.5, solid(c))
+			}
+		},
+	}
+}
+
+// notification is a pattern played or waiting to play.
+type notification struct {
+	pattern  *NotifyPattern
+	priority int
+	elapsed  time.Duration
+}
+
+// notifier plays the notifications of a ring, one at a time.
+type notifier struct {
+	mu      sync.Mutex
+	layer   *Layer
+	current *notification
+	queue   []*notification // by priority, highest first
+}
+
+// Notify plays a notification above all the layers of the ring, such as
+// FlashPattern, without changing the layers, so the ring shows them again once
+// the notification ends. Notifications play one at a time while the ring runs
+// (see Run), in real time even if the ring is paused or time scaled. A
+// notification with a higher priority than the one playing preempts it, and
+// the preempted notification plays again from the start afterwards; others
+// wait for their turn, by priority and then in order.
+func (r *Ring) Notify(p *NotifyPattern, priority int) {
+	if p == nil || p.Draw == nil {
+		return
+	}
+
+	n := &r.notices
+	n.mu.Lock()
+	if n.layer == nil {
+		l, err := NewLayer(&LayerOptions{Resolution: r.Size()})
+		if err != nil {
+			n.mu.Unlock()
+			r.opt.logger().Error("could not create notification layer", "err", err)
+			return
+		}
+		n.layer = l
+	}
+
+	next := &notification{pattern: p, priority: priority}
+	started := true
+	switch {
+	case n.current == nil:
+		n.start(next)
+	case priority > n.current.priority:
+		n.enqueue(n.current, true)
+		n.start(next)
+	default:
+		n.enqueue(next, false)
+		started = false
+	}
+	n.mu.Unlock()
+
+	if started {
+		r.markDirty()
+	}
+}
+
+// ClearNotifications stops the notification playing and discards the ones
+// waiting.
+func (r *Ring) ClearNotifications() {
+	n := &r.notices
+	n.mu.Lock()
+	n.current = nil
+	n.queue = nil
+	n.mu.Unlock()
+
+	r.markDirty()
+}
+
+// markDirty makes the next render draw a new frame.
+func (r *Ring) markDirty() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dirty = true
+}
+
+// start plays a notification from its start. It must be called with n.mu
+// held.
+func (n *notifier) start(x *notification) {
+	x.elapsed = 0
+	n.current = x
+	n.layer.SetAll(color.Transparent)
+	n.layer.SetOpacity(1)
+	x.pattern.Draw(n.layer, 0)
+}
+
+// enqueue adds a notification to the queue, after the notifications of higher
+// priority, and before or after the ones of the same priority. It must be
+// called with n.mu held.
+func (n *notifier) enqueue(x *notification, before bool) {
+	i := sort.Search(len(n.queue), func(i int) bool {
+		if before {
+			return n.queue[i].priority <= x.priority
+		}
+		return n.queue[i].priority < x.priority
+	})
+	n.queue = append(n.queue, nil)
+	copy(n.queue[i+1:], n.queue[i:])
+	n.queue[i] = x
+}
+
+// overlay returns the layer of the playing notification, or nil if there is
+// none.
+func (n *notifier) overlay() Pixeler {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.current == nil {
+		return nil
+	}
+	return n.layer
+}
+
+// advance plays the notifications by dt, and reports whether the frame
+// changed.
+func (n *notifier) advance(dt time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	x := n.current
+	if x == nil {
+		return false
+	}
+	x.elapsed += dt
+	if x.elapsed < x.pattern.Duration {
+		x.pattern.Draw(n.layer, float64(x.elapsed)/float64(x.pattern.Duration))
+		return true
+	}
+
+	n.current = nil
+	if len(n.queue) > 0 {
+		next := n.queue[0]
+		n.queue = n.queue[1:]
+		n.start(next)
+	}
+
+	return true
+}
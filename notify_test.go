@@ -0,0 +1,76 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	r, dev := newTestRing(t, 4)
+	bg := newTestLayer(t, &LayerOptions{Resolution: 4})
+	bg.SetAll(color.RGBA{0x00, 0x00, 0x10, 0xFF})
+	r.AddLayer(bg)
+	r.Pause()
+
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	green := color.RGBA{0x00, 0xFF, 0x00, 0xFF}
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	tests := []struct {
+		name string
+		do   func()
+		want uint32
+	}{
+		{"flash", func() { r.Notify(FlashPattern(red, 1, time.Second), 0) }, 0xFF0000},
+		{"queued", func() { r.Notify(FlashPattern(green, 1, time.Second), 0) }, 0xFF0000},
+		{"flash off", func() { r.advance(600 * time.Millisecond) }, 0x000010},
+		{"urgent", func() { r.Notify(FlashPattern(white, 1, time.Second), 1) }, 0xFFFFFF},
+		{"urgent done", func() { r.advance(time.Second) }, 0xFF0000},
+		{"preempted again", func() { r.advance(600 * time.Millisecond) }, 0x000010},
+		{"next", func() { r.advance(400 * time.Millisecond) }, 0x00FF00},
+		{"done", func() { r.advance(time.Second) }, 0x000010},
+		{"clear", func() {
+			r.Notify(FlashPattern(red, 1, time.Second), 0)
+			r.ClearNotifications()
+		}, 0x000010},
+	}
+
+	for _, ts := range tests {
+		ts.do()
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		if got := dev.leds[0]; got != ts.want {
+			t.Errorf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+		}
+	}
+	if got := len(r.Layers()); got != 1 {
+		t.Errorf("got: %d layers, want: 1", got)
+	}
+}
+
+func TestNotifyPatterns(t *testing.T) {
+	c := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	tests := []struct {
+		name    string
+		pattern *NotifyPattern
+		t       float64
+		want    []uint16 // alpha of each pixel
+	}{
+		{"flash on", FlashPattern(c, 2, time.Second), 0.6, []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}},
+		{"flash off", FlashPattern(c, 2, time.Second), 0.3, []uint16{0, 0, 0, 0}},
+		{"pulse peak", PulsePattern(c, 1, time.Second), 0.5, []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF}},
+		{"pulse start", PulsePattern(c, 1, time.Second), 0, []uint16{0, 0, 0, 0}},
+		{"spin", SpinPattern(c, 1, time.Second), 0.5, []uint16{0, 0xFFFF, 0, 0}},
+	}
+
+	for _, ts := range tests {
+		l := newTestLayer(t, &LayerOptions{Resolution: 4})
+		ts.pattern.Draw(l, ts.t)
+		for i, want := range ts.want {
+			if got := l.pixel64(i).A; got != want {
+				t.Errorf("%s pixel %d got: %#x, want: %#x", ts.name, i, got, want)
+			}
+		}
+	}
+}
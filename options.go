@@ -0,0 +1,374 @@
+package ring
+
+import "fmt"
+
+// Option configures a ring created with New. An *Options is an Option that
+// sets all the options at once, so it should come before other options. Nil
+// options are ignored.
+type Option interface {
+	apply(o *Options) error
+}
+
+type optionFunc func(o *Options) error
+
+func (f optionFunc) apply(o *Options) error {
+	return f(o)
+}
+
+func (o *Options) apply(dst *Options) error {
+	if o == nil {
+		return nil
+	}
+	*dst = *o
+	return nil
+}
+
+// WithLEDCount sets the number of LEDs of the ring (see Options.LedCount).
+func WithLEDCount(n int) Option {
+	return optionFunc(func(o *Options) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: invalid LED count %d", ErrInvalidOptions, n)
+		}
+		o.LedCount = n
+		return nil
+	})
+}
+
+// WithGPIO sets the GPIO pin of the PWM output (see Options.GpioPin).
+func WithGPIO(pin int) Option {
+	return optionFunc(func(o *Options) error {
+		if pin <= 0 {
+			return fmt.Errorf("%w: invalid GPIO pin %d", ErrInvalidOptions, pin)
+		}
+		o.GpioPin = pin
+		return nil
+	})
+}
+
+// WithBrightness sets the maximum brightness of the LEDs, from 0 to 255 (see
+// Options.MaxBrightness). Unlike a MaxBrightness of 0 in Options, which means
+// the default brightness, WithBrightness(0) starts the ring turned off.
+func WithBrightness(max int) Option {
+	return optionFunc(func(o *Options) error {
+		if max < 0 || max > 255 {
+			return fmt.Errorf("%w: brightness %d out of range [0, 255]", ErrInvalidOptions, max)
+		}
+		o.MaxBrightness = max
+		o.maxBrightnessSet = true
+		return nil
+	})
+}
+
+// WithMinBrightness sets the minimum brightness of the LEDs, from 0 to 255
+// (see Options.MinBrightness).
+func WithMinBrightness(min int) Option {
+	return optionFunc(func(o *Options) error {
+		if min < 0 || min > 255 {
+			return fmt.Errorf("%w: brightness %d out of range [0, 255]", ErrInvalidOptions, min)
+		}
+		o.MinBrightness = min
+		return nil
+	})
+}
+
+// WithStripType sets the model and color order of the LEDs (see
+// Options.StripType).
+func WithStripType(t StripType) Option {
+	return optionFunc(func(o *Options) error {
+		o.StripType = t
+		return nil
+	})
+}
+
+// WithMaxCurrent sets the power budget of the LEDs in milliamps (see
+// Options.MaxCurrentMilliamps).
+func WithMaxCurrent(milliamps int) Option {
+	return optionFunc(func(o *Options) error {
+		if milliamps < 0 {
+			return fmt.Errorf("%w: invalid current %dmA", ErrInvalidOptions, milliamps)
+		}
+		o.MaxCurrentMilliamps = milliamps
+		return nil
+	})
+}
+
+// WithRotationOffset sets the base angular offset of the ring in radians (see
+// Options.RotationOffset).
+func WithRotationOffset(angle float64) Option {
+	return optionFunc(func(o *Options) error {
+		o.RotationOffset = angle
+		return nil
+	})
+}
+
+// WithLinearBlending blends colors in linear light (see
+// Options.LinearBlending).
+func WithLinearBlending() Option {
+	return optionFunc(func(o *Options) error {
+		o.LinearBlending = true
+		return nil
+	})
+}
+
+// WithDithering enables temporal dithering (see Options.Dithering).
+func WithDithering() Option {
+	return optionFunc(func(o *Options) error {
+		o.Dithering = true
+		return nil
+	})
+}
+
+// WithDriver sets the driver of the LEDs (see Options.Driver).
+func WithDriver(d Driver) Option {
+	return optionFunc(func(o *Options) error {
+		o.Driver = d
+		return nil
+	})
+}
+
+// WithLogger sets the logger of the ring (see Options.Logger).
+func WithLogger(l Logger) Option {
+	return optionFunc(func(o *Options) error {
+		o.Logger = l
+		return nil
+	})
+}
+
+// WithGamma sets the same gamma correction for all the color channels of the
+// LEDs (see Options.Gamma).
+func WithGamma(gamma float64) Option {
+	return optionFunc(func(o *Options) error {
+		o.Gamma = UniformGamma(gamma)
+		return nil
+	})
+}
+
+// WithStartupAnimation sets the animation played when the ring is created
+// (see Options.StartupAnimation).
+func WithStartupAnimation(a *PowerAnimation) Option {
+	return optionFunc(func(o *Options) error {
+		o.StartupAnimation = a
+		return nil
+	})
+}
+
+// WithShutdownAnimation sets the animation played when the ring is closed
+// (see Options.ShutdownAnimation).
+func WithShutdownAnimation(a *PowerAnimation) Option {
+	return optionFunc(func(o *Options) error {
+		o.ShutdownAnimation = a
+		return nil
+	})
+}
+
+// validate checks that the options describe a valid ring. If the options are
+// a channel split in segments, the segments are validated too.
+func (o *Options) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidOptions}, args...)...)
+	}
+
+	if len(o.Segments) != 0 {
+		if o.Driver != nil {
+			return invalid("segments are only supported by the PWM output")
+		}
+		n := 0
+		for i, seg := range o.Segments {
+			if len(seg.Segments) != 0 {
+				return invalid("segment %d has segments", i)
+			}
+			if err := seg.validate(); err != nil {
+				return fmt.Errorf("segment %d: %w", i, err)
+			}
+			n += seg.LedCount
+		}
+		if o.LedCount != 0 && o.LedCount != n {
+			return invalid("LedCount is %d, but the segments have %d LEDs", o.LedCount, n)
+		}
+	} else if o.LedCount <= 0 {
+		return invalid("LedCount must be positive, got %d", o.LedCount)
+	}
+
+	for _, b := range []struct {
+		name  string
+		value int
+	}{
+		{"MinBrightness", o.MinBrightness},
+		{"MaxBrightness", o.MaxBrightness},
+	} {
+		if b.value < 0 || b.value > 255 {
+			return invalid("%s %d out of range [0, 255]", b.name, b.value)
+		}
+	}
+	if max := o.maxBrightness(); o.MinBrightness > max {
+		return invalid("MinBrightness %d is greater than MaxBrightness %d", o.MinBrightness, max)
+	}
+	if o.GpioPin < 0 {
+		return invalid("GpioPin must not be negative, got %d", o.GpioPin)
+	}
+	if o.Frequency != 0 && o.Frequency != 400000 && o.Frequency != 800000 {
+		return invalid("Frequency must be 400000 or 800000 Hz, got %d", o.Frequency)
+	}
+	if o.DmaChannel < 0 {
+		return invalid("DmaChannel must not be negative, got %d", o.DmaChannel)
+	}
+	if o.MaxCurrentMilliamps < 0 {
+		return invalid("MaxCurrentMilliamps must not be negative, got %d", o.MaxCurrentMilliamps)
+	}
+	if o.Gamma.R < 0 || o.Gamma.G < 0 || o.Gamma.B < 0 {
+		return invalid("Gamma must not be negative, got %v", o.Gamma)
+	}
+	if d := o.FrameDump; d != nil && (d.Dir == "" || d.Every < 0 || d.Size < 0) {
+		return invalid("FrameDump needs a directory and a positive period and size")
+	}
+	if len(o.Segments) == 0 && len(o.Calibration) > o.LedCount {
+		return invalid("Calibration has %d LEDs, want at most %d", len(o.Calibration), o.LedCount)
+	}
+
+	if m := o.PixelMap; m != nil {
+		if len(m) != o.LedCount {
+			return invalid("PixelMap has %d positions, want %d", len(m), o.LedCount)
+		}
+		used := make([]bool, len(m))
+		for i, led := range m {
+			if led < 0 || led >= len(m) {
+				return &invalidError{fmt.Errorf("PixelMap maps pixel %d to LED %d: %w", i, led, ErrPixelOutOfRange)}
+			}
+			if used[led] {
+				return invalid("PixelMap maps LED %d more than once", led)
+			}
+			used[led] = true
+		}
+	}
+
+	return nil
+}
+
+// invalidError is an invalid option that also matches another error, such as
+// ErrPixelOutOfRange.
+type invalidError struct {
+	err error
+}
+
+func (e *invalidError) Error() string {
+	return ErrInvalidOptions.Error() + ": " + e.err.Error()
+}
+
+func (e *invalidError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is ErrInvalidOptions.
+func (e *invalidError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+// buildOptions applies options in order to the zero options.
+func buildOptions(opts []Option) (*Options, error) {
+	o := &Options{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt.apply(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// LayerOption configures a layer created with NewLayer. A *LayerOptions is a
+// LayerOption that sets all the options at once, so it should come before
+// other options. Nil options are ignored.
+type LayerOption interface {
+	applyLayer(o *LayerOptions) error
+}
+
+type layerOptionFunc func(o *LayerOptions) error
+
+func (f layerOptionFunc) applyLayer(o *LayerOptions) error {
+	return f(o)
+}
+
+func (o *LayerOptions) applyLayer(dst *LayerOptions) error {
+	if o == nil {
+		return nil
+	}
+	*dst = *o
+	return nil
+}
+
+// WithResolution sets the number of pixels of the layer (see
+// LayerOptions.Resolution).
+func WithResolution(n int) LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		if n <= 0 {
+			return ErrZeroResolution
+		}
+		o.Resolution = n
+		return nil
+	})
+}
+
+// WithName sets the name of the layer (see LayerOptions.Name).
+func WithName(name string) LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		o.Name = name
+		return nil
+	})
+}
+
+// WithContentMode sets how the layer is rendered (see
+// LayerOptions.ContentMode).
+func WithContentMode(m ContentMode) LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		o.ContentMode = m
+		return nil
+	})
+}
+
+// WithWrapIndices wraps the indices of SetPixel around the layer (see
+// LayerOptions.WrapIndices).
+func WithWrapIndices() LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		o.WrapIndices = true
+		return nil
+	})
+}
+
+// WithReverse renders the layer in the opposite direction (see
+// LayerOptions.Reverse).
+func WithReverse() LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		o.Reverse = true
+		return nil
+	})
+}
+
+// WithBlur sets the radius of the blur of the layer in pixels (see
+// LayerOptions.Blur).
+func WithBlur(radius int) LayerOption {
+	return layerOptionFunc(func(o *LayerOptions) error {
+		if radius < 0 {
+			return fmt.Errorf("%w: invalid blur radius %d", ErrInvalidOptions, radius)
+		}
+		o.Blur = radius
+		return nil
+	})
+}
+
+// buildLayerOptions applies options in order to the zero options.
+func buildLayerOptions(opts []LayerOption) (*LayerOptions, error) {
+	o := &LayerOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt.applyLayer(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
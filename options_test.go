@@ -0,0 +1,147 @@
+package ring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    Options
+		wantErr bool
+	}{
+		{
+			"functional",
+			[]Option{WithLEDCount(12), WithGPIO(13), WithBrightness(180)},
+			Options{LedCount: 12, GpioPin: 13, MaxBrightness: 180},
+			false,
+		},
+		{
+			"struct then functional",
+			[]Option{&Options{LedCount: 12, MaxBrightness: 100}, WithBrightness(20)},
+			Options{LedCount: 12, MaxBrightness: 20},
+			false,
+		},
+		{
+			"invalid count",
+			[]Option{WithLEDCount(0)},
+			Options{},
+			true,
+		},
+		{
+			"invalid brightness",
+			[]Option{WithBrightness(256)},
+			Options{},
+			true,
+		},
+		{
+			"invalid GPIO",
+			[]Option{WithGPIO(-1)},
+			Options{},
+			true,
+		},
+		{
+			"nil",
+			[]Option{(*Options)(nil), WithLEDCount(3)},
+			Options{LedCount: 3},
+			false,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			got, err := buildOptions(ts.opts)
+			if (err != nil) != ts.wantErr {
+				t.Fatalf("got: %v, want error: %v", err, ts.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("got: %v, want: %v", err, ErrInvalidOptions)
+			}
+			if err == nil && (got.LedCount != ts.want.LedCount || got.GpioPin != ts.want.GpioPin || got.MaxBrightness != ts.want.MaxBrightness) {
+				t.Errorf("got: %+v, want: %+v", *got, ts.want)
+			}
+		})
+	}
+}
+
+func TestLayerOptions(t *testing.T) {
+	l, err := NewLayer(WithResolution(6), WithBlur(1), WithReverse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := *l.Options(); got.Resolution != 6 || got.Blur != 1 || !got.Reverse {
+		t.Errorf("got: %+v, want: resolution 6, blur 1, reverse", got)
+	}
+
+	if _, err := NewLayer(WithResolution(0)); err != ErrZeroResolution {
+		t.Errorf("got: %v, want: %v", err, ErrZeroResolution)
+	}
+	if _, err := NewLayer(WithResolution(6), WithBlur(-1)); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("got: %v, want: %v", err, ErrInvalidOptions)
+	}
+	if _, err := NewLayer(nil); err != ErrZeroResolution {
+		t.Errorf("nil got: %v, want: %v", err, ErrZeroResolution)
+	}
+	if _, err := New(nil); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("New(nil) got: %v, want: %v", err, ErrInvalidOptions)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  *Options
+		ok   bool
+	}{
+		{"valid", &Options{LedCount: 12}, true},
+		{"zero leds", &Options{}, false},
+		{"brightness", &Options{LedCount: 12, MaxBrightness: 300}, false},
+		{"min over max", &Options{LedCount: 12, MinBrightness: 50, MaxBrightness: 10}, false},
+		{"min under default max", &Options{LedCount: 12, MinBrightness: 50}, true},
+		{"min over default max", &Options{LedCount: 12, MinBrightness: 100}, false},
+		{"frequency", &Options{LedCount: 12, Frequency: 1000}, false},
+		{"calibration", &Options{LedCount: 1, Calibration: []ColorScale{{}, {}}}, false},
+		{"gamma", &Options{LedCount: 1, Gamma: Gamma{G: -1}}, false},
+		{"frame dump", &Options{LedCount: 1, FrameDump: &FrameDump{Every: 2}}, false},
+		{"segments", &Options{Segments: []*Options{{LedCount: 4}, {LedCount: 8}}}, true},
+		{"segments count", &Options{LedCount: 10, Segments: []*Options{{LedCount: 4}, {LedCount: 8}}}, false},
+		{"segments driver", &Options{Driver: SPI(""), Segments: []*Options{{LedCount: 4}}}, false},
+		{"invalid segment", &Options{Segments: []*Options{{LedCount: 4}, {}}}, false},
+		{"pixel map", &Options{LedCount: 3, PixelMap: []int{2, 0, 1}}, true},
+		{"pixel map size", &Options{LedCount: 3, PixelMap: []int{0, 1}}, false},
+		{"pixel map range", &Options{LedCount: 2, PixelMap: []int{0, 2}}, false},
+		{"pixel map twice", &Options{LedCount: 2, PixelMap: []int{1, 1}}, false},
+		{"segment pixel map", &Options{Segments: []*Options{{LedCount: 2, PixelMap: []int{0, 0}}}}, false},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			err := ts.opt.validate()
+			if (err == nil) != ts.ok {
+				t.Fatalf("got: %v, want ok: %v", err, ts.ok)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("got: %v, want: %v", err, ErrInvalidOptions)
+			}
+		})
+	}
+
+	r, err := NewWithDevice(&fakeDevice{leds: make([]uint32, 1)}, mustOptions(t, WithLEDCount(1), WithBrightness(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Brightness(); got != 0 {
+		t.Errorf("brightness got: %d, want: 0", got)
+	}
+}
+
+func mustOptions(t *testing.T, opts ...Option) *Options {
+	t.Helper()
+	o, err := buildOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
@@ -0,0 +1,156 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ColorStop is a color at a position of a palette.
+type ColorStop struct {
+	// Pos is the position of the color, from 0.0 to 1.0.
+	Pos float64
+	// Color is the color at the position.
+	Color color.Color
+}
+
+// ParsePalette parses the name of a palette of Palettes, such as "ocean", or
+// comma separated color stops, each a color (see ParseColor) at a position
+// from 0.0 to 1.0, such as "#000000@0,red@0.3,#ffffff@1". Colors without
+// positions, such as "black,red,white", are evenly spaced (see NewPalette).
+func ParsePalette(s string) (Palette, error) {
+	if p, ok := Palettes[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return p, nil
+	}
+
+	fields := strings.Split(s, ",")
+	p := make(Palette, len(fields))
+	positions := 0
+	for i, f := range fields {
+		text, pos := f, ""
+		if at := strings.LastIndex(f, "@"); at >= 0 {
+			text, pos = f[:at], f[at+1:]
+			positions++
+		}
+		c, err := ParseColor(text)
+		if err != nil {
+			return nil, fmt.Errorf("ring: unknown palette %q", s)
+		}
+		p[i].Color = c
+		if pos == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(strings.TrimSpace(pos), 64)
+		if err != nil || x < 0 || x > 1 || i > 0 && x < p[i-1].Pos {
+			return nil, fmt.Errorf("ring: invalid position %q of palette %q", pos, s)
+		}
+		p[i].Pos = x
+	}
+	switch positions {
+	case 0:
+		colors := make([]color.Color, len(p))
+		for i, cs := range p {
+			colors[i] = cs.Color
+		}
+		return NewPalette(colors...), nil
+	case len(p):
+		return p, nil
+	default:
+		return nil, fmt.Errorf("ring: palette %q needs positions for all the colors or none", s)
+	}
+}
+
+// Palette is a gradient of colors defined by color stops, sorted by position.
+type Palette []ColorStop
+
+// NewPalette creates a palette with colors evenly spaced from 0.0 to 1.0.
+func NewPalette(colors ...color.Color) Palette {
+	p := make(Palette, len(colors))
+	for i, c := range colors {
+		p[i] = ColorStop{Color: c}
+		if len(colors) > 1 {
+			p[i].Pos = float64(i) / float64(len(colors)-1)
+		}
+	}
+
+	return p
+}
+
+// At returns the color of the palette at position t, from 0.0 to 1.0,
+// interpolating between the closest color stops. Positions outside of the
+// stops are clamped.
+func (p Palette) At(t float64) color.Color {
+	return p.at64(t)
+}
+
+func (p Palette) at64(t float64) color.RGBA64 {
+	if len(p) == 0 {
+		return color.RGBA64{}
+	}
+	if t <= p[0].Pos {
+		return toRGBA64(p[0].Color)
+	}
+	for i := 1; i < len(p); i++ {
+		a, b := p[i-1], p[i]
+		if t >= b.Pos {
+			continue
+		}
+		return blendLerp(toRGBA64(a.Color), toRGBA64(b.Color), (t-a.Pos)/(b.Pos-a.Pos))
+	}
+
+	return toRGBA64(p[len(p)-1].Color)
+}
+
+// Cycle is like At, but wraps t around so the palette repeats every 1.0.
+func (p Palette) Cycle(t float64) color.Color {
+	return p.at64(t - math.Floor(t))
+}
+
+var (
+	// PaletteRainbow goes through all the hues, starting and ending at red.
+	PaletteRainbow = NewPalette(
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0x00, 0xFF},
+		color.RGBA{0x00, 0xFF, 0x00, 0xFF},
+		color.RGBA{0x00, 0xFF, 0xFF, 0xFF},
+		color.RGBA{0x00, 0x00, 0xFF, 0xFF},
+		color.RGBA{0xFF, 0x00, 0xFF, 0xFF},
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+	)
+	// PaletteHeat goes from black to white through red and yellow, like
+	// fire.
+	PaletteHeat = NewPalette(
+		color.RGBA{0x00, 0x00, 0x00, 0xFF},
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	)
+	// PaletteOcean goes through deep blues, teals and aqua.
+	PaletteOcean = NewPalette(
+		color.RGBA{0x00, 0x00, 0x40, 0xFF},
+		color.RGBA{0x00, 0x20, 0x80, 0xFF},
+		color.RGBA{0x00, 0x80, 0x80, 0xFF},
+		color.RGBA{0x40, 0xE0, 0xD0, 0xFF},
+		color.RGBA{0x00, 0x00, 0x40, 0xFF},
+	)
+	// PaletteParty goes through saturated purples, pinks, oranges and blues.
+	PaletteParty = NewPalette(
+		color.RGBA{0x55, 0x00, 0xAB, 0xFF},
+		color.RGBA{0xB8, 0x00, 0x4B, 0xFF},
+		color.RGBA{0xFF, 0x45, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xC0, 0x00, 0xFF},
+		color.RGBA{0x2F, 0x00, 0xD0, 0xFF},
+		color.RGBA{0x55, 0x00, 0xAB, 0xFF},
+	)
+)
+
+// Palettes are the built-in palettes by name, to choose palettes from text,
+// such as the parameters of effects (see ParamPalette).
+var Palettes = map[string]Palette{
+	"rainbow": PaletteRainbow,
+	"heat":    PaletteHeat,
+	"ocean":   PaletteOcean,
+	"party":   PaletteParty,
+}
@@ -0,0 +1,70 @@
+package ring
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestPalette(t *testing.T) {
+	p := NewPalette(
+		color.RGBA{0x00, 0x00, 0x00, 0xFF},
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	)
+
+	tests := []struct {
+		name string
+		t    float64
+		want color.RGBA
+	}{
+		{"before", -1, color.RGBA{0x00, 0x00, 0x00, 0xFF}},
+		{"start", 0, color.RGBA{0x00, 0x00, 0x00, 0xFF}},
+		{"between", 0.25, color.RGBA{0x7F, 0x00, 0x00, 0xFF}},
+		{"stop", 0.5, color.RGBA{0xFF, 0x00, 0x00, 0xFF}},
+		{"end", 1, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}},
+		{"after", 2, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := color.RGBAModel.Convert(p.At(ts.t)); got != ts.want {
+				t.Errorf("got: %v, want: %v", got, ts.want)
+			}
+		})
+	}
+
+	if got, want := color.RGBAModel.Convert(p.Cycle(1.25)), (color.RGBA{0x7F, 0x00, 0x00, 0xFF}); got != want {
+		t.Errorf("cycle got: %v, want: %v", got, want)
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	blue := color.NRGBA{0x00, 0x00, 0xFF, 0xFF}
+
+	tests := []struct {
+		text    string
+		want    Palette
+		wantErr bool
+	}{
+		{"Ocean", PaletteOcean, false},
+		{"red,#0000ff", Palette{{0, red}, {1, blue}}, false},
+		{"red@0.2, #0000ff@0.8", Palette{{0.2, red}, {0.8, blue}}, false},
+		{"red@0.8,#0000ff@0.2", nil, true},
+		{"red@0,#0000ff", nil, true},
+		{"red@2", nil, true},
+		{"sunrise", nil, true},
+	}
+
+	for _, ts := range tests {
+		got, err := ParsePalette(ts.text)
+		if (err != nil) != ts.wantErr {
+			t.Errorf("%q got: %v, want error: %v", ts.text, err, ts.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, ts.want) {
+			t.Errorf("%q got: %v, want: %v", ts.text, got, ts.want)
+		}
+	}
+}
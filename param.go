@@ -0,0 +1,261 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParamType is the type of a parameter of an effect.
+type ParamType int
+
+const (
+	// ParamFloat is a number, such as "0.5".
+	ParamFloat ParamType = iota
+	// ParamInt is an integer, such as "3".
+	ParamInt
+	// ParamColor is a color, such as "#ff8800" or "orange" (see ParseColor).
+	ParamColor
+	// ParamPalette is a palette, by name, such as "ocean", or by its color
+	// stops, such as "#000000@0,#ffffff@1" (see ParsePalette).
+	ParamPalette
+)
+
+func (t ParamType) String() string {
+	switch t {
+	case ParamFloat:
+		return "float"
+	case ParamInt:
+		return "int"
+	case ParamColor:
+		return "color"
+	case ParamPalette:
+		return "palette"
+	default:
+		return fmt.Sprintf("ParamType(%d)", int(t))
+	}
+}
+
+// parse parses the text of a value of the type.
+func (t ParamType) parse(s string) (interface{}, error) {
+	switch t {
+	case ParamFloat:
+		return strconv.ParseFloat(s, 64)
+	case ParamInt:
+		return strconv.Atoi(s)
+	case ParamColor:
+		return ParseColor(s)
+	case ParamPalette:
+		return ParsePalette(s)
+	default:
+		return nil, fmt.Errorf("ring: invalid parameter type %v", t)
+	}
+}
+
+// ParamSpec describes a parameter of an effect.
+type ParamSpec struct {
+	// Name is the name of the parameter, such as "speed".
+	Name string
+	// Type is the type of the value of the parameter.
+	Type ParamType
+	// Default is the value of the parameter when it is not given, as text.
+	Default string
+	// Doc describes the parameter, with its unit.
+	Doc string
+	// Min and Max are the range of the values of ParamFloat and ParamInt
+	// parameters, such as for sliders. If both are 0, the range is not
+	// limited.
+	Min, Max float64
+}
+
+// value converts a value to the type of the parameter, and checks that it is
+// in range. Values are given as text, or as float64, int, color.Color or
+// Palette.
+func (s ParamSpec) value(v interface{}) (interface{}, error) {
+	if text, ok := v.(string); ok {
+		var err error
+		if v, err = s.Type.parse(text); err != nil {
+			return nil, err
+		}
+	}
+
+	var x float64
+	switch s.Type {
+	case ParamFloat:
+		switch n := v.(type) {
+		case float64:
+			x = n
+		case int:
+			x = float64(n)
+			v = x
+		default:
+			return nil, fmt.Errorf("ring: %T is not a float", v)
+		}
+	case ParamInt:
+		switch n := v.(type) {
+		case int:
+			x = float64(n)
+		case float64:
+			if n != math.Trunc(n) {
+				return nil, fmt.Errorf("ring: %v is not an integer", n)
+			}
+			x = n
+			v = int(n)
+		default:
+			return nil, fmt.Errorf("ring: %T is not an integer", v)
+		}
+	case ParamColor:
+		if _, ok := v.(color.Color); !ok {
+			return nil, fmt.Errorf("ring: %T is not a color", v)
+		}
+		return v, nil
+	case ParamPalette:
+		if _, ok := v.(Palette); !ok {
+			return nil, fmt.Errorf("ring: %T is not a palette", v)
+		}
+		return v, nil
+	}
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil, fmt.Errorf("ring: %v is not a finite number", x)
+	}
+	if (s.Min != 0 || s.Max != 0) && (x < s.Min || x > s.Max) {
+		return nil, fmt.Errorf("ring: %v out of range [%v, %v]", x, s.Min, s.Max)
+	}
+
+	return v, nil
+}
+
+// Params are the values of the parameters of an effect by name, parsed to
+// their types: float64, int, color.Color and Palette.
+type Params map[string]interface{}
+
+// Float returns the value of a ParamFloat parameter.
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+// Int returns the value of a ParamInt parameter.
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+// Color returns the value of a ParamColor parameter.
+func (p Params) Color(name string) color.Color {
+	v, _ := p[name].(color.Color)
+	return v
+}
+
+// Palette returns the value of a ParamPalette parameter.
+func (p Params) Palette(name string) Palette {
+	v, _ := p[name].(Palette)
+	return v
+}
+
+// Param is a parameter of an effect with its current value.
+type Param struct {
+	ParamSpec
+	// Value is the current value of the parameter: a float64, int,
+	// color.Color or Palette.
+	Value interface{}
+}
+
+// String returns the value of the parameter as text, as accepted by
+// Tunable.SetParam. Palettes that are not in Palettes are written as their
+// color stops (see ParsePalette).
+func (p Param) String() string {
+	switch v := p.Value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case color.Color:
+		return formatColor(v)
+	case Palette:
+		for name, q := range Palettes {
+			if reflect.DeepEqual(q, v) {
+				return name
+			}
+		}
+		stops := make([]string, len(v))
+		for i, cs := range v {
+			stops[i] = formatColor(cs.Color) + "@" + strconv.FormatFloat(cs.Pos, 'g', -1, 64)
+		}
+		return strings.Join(stops, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatColor writes a color in hexadecimal, as accepted by ParseColor.
+func formatColor(v color.Color) string {
+	c := color.NRGBAModel.Convert(v).(color.NRGBA)
+	if c.A == 0xFF {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// Tunable is implemented by effects whose parameters can be changed while they
+// play, so user interfaces and remote APIs can show and change them, such as
+// with sliders, without knowing the effect. All the built-in effects of
+// DefaultRegistry are Tunable.
+type Tunable interface {
+	// Params returns the parameters of the effect with their current values.
+	Params() []Param
+	// SetParam changes the value of a parameter, given as text or as a
+	// value of the type of the parameter (see Param.Value). It returns an
+	// error if the effect has no such parameter, or the value is invalid or
+	// out of range.
+	SetParam(name string, value interface{}) error
+}
+
+// params implements Tunable from the specs of the parameters of an effect and
+// functions that get and set their values.
+type params struct {
+	specs []ParamSpec
+	get   func(name string) interface{}
+	set   func(name string, v interface{})
+}
+
+// Params returns the parameters with their current values.
+func (p params) Params() []Param {
+	ps := make([]Param, len(p.specs))
+	for i, s := range p.specs {
+		ps[i] = Param{ParamSpec: s, Value: p.get(s.Name)}
+	}
+
+	return ps
+}
+
+// SetParam changes the value of a parameter.
+func (p params) SetParam(name string, value interface{}) error {
+	for _, s := range p.specs {
+		if s.Name != name {
+			continue
+		}
+		v, err := s.value(value)
+		if err != nil {
+			return fmt.Errorf("ring: parameter %q: %w", name, err)
+		}
+		p.set(name, v)
+		return nil
+	}
+
+	return fmt.Errorf("ring: no parameter %q", name)
+}
+
+// setParams sets the parameters of a tunable effect.
+func setParams(t Tunable, p Params) error {
+	for name, v := range p {
+		if err := t.SetParam(name, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTunable(t *testing.T) {
+	e, err := NewEffect("comet", nil, &LayerOptions{Resolution: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := e.(Tunable)
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr string
+	}{
+		{"speed", "360", "360", ""},
+		{"speed", 90.5, "90.5", ""},
+		{"speed", 45, "45", ""},
+		{"speed", "1000", "45", "out of range"},
+		{"speed", "fast", "45", `parameter "speed"`},
+		{"color", "red", "#ff0000", ""},
+		{"color", color.NRGBA{0x00, 0x00, 0xFF, 0x80}, "#0000ff80", ""},
+		{"color", 3.0, "#0000ff80", "not a color"},
+		{"size", "3", "", `no parameter "size"`},
+	}
+
+	for _, ts := range tests {
+		err := c.SetParam(ts.name, ts.value)
+		if ts.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), ts.wantErr) {
+				t.Errorf("%s=%v got: %v, want: error with %q", ts.name, ts.value, err, ts.wantErr)
+			}
+		} else if err != nil {
+			t.Errorf("%s=%v: %v", ts.name, ts.value, err)
+		}
+		for _, p := range c.Params() {
+			if p.Name == ts.name && p.String() != ts.want {
+				t.Errorf("%s=%v got: %s, want: %s", ts.name, ts.value, p, ts.want)
+			}
+		}
+	}
+}
+
+func TestBuiltinParams(t *testing.T) {
+	for _, spec := range DefaultRegistry.Effects() {
+		e, err := NewEffect(spec.Name, nil, &LayerOptions{Resolution: 12})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tn, ok := e.(Tunable)
+		if !ok {
+			t.Errorf("%s got: not Tunable, want: Tunable", spec.Name)
+			continue
+		}
+		ps := tn.Params()
+		if len(ps) != len(spec.Params) {
+			t.Errorf("%s got: %d params, want: %d", spec.Name, len(ps), len(spec.Params))
+			continue
+		}
+		for _, p := range ps {
+			if got, want := p.String(), p.Default; got != want && p.Type != ParamColor {
+				t.Errorf("%s %s got: %s, want: default %s", spec.Name, p.Name, got, want)
+			}
+			if err := tn.SetParam(p.Name, p.String()); err != nil {
+				t.Errorf("%s %s: %v", spec.Name, p.Name, err)
+			}
+		}
+	}
+}
+
+func TestParamInt(t *testing.T) {
+	s := ParamSpec{Name: "count", Type: ParamInt, Min: 1, Max: 10}
+	tests := []struct {
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"3", 3, false},
+		{4.0, 4, false},
+		{4.5, nil, true},
+		{"0", nil, true},
+		{11, nil, true},
+		{math.Inf(1), nil, true},
+	}
+
+	for _, ts := range tests {
+		got, err := s.value(ts.value)
+		if (err != nil) != ts.wantErr {
+			t.Errorf("%#v got: %v, want error: %v", ts.value, err, ts.wantErr)
+			continue
+		}
+		if err == nil && got != ts.want {
+			t.Errorf("%#v got: %#v, want: %#v", ts.value, got, ts.want)
+		}
+	}
+}
+
+func TestParamFloatNaN(t *testing.T) {
+	for _, v := range []string{"NaN", "Inf", "-Inf"} {
+		if _, err := NewEffect("comet", map[string]string{"speed": v}, &LayerOptions{Resolution: 12}); err == nil {
+			t.Errorf("speed %v got: nil, want: error", v)
+		}
+	}
+	s := ParamSpec{Name: "any", Type: ParamFloat}
+	if _, err := s.value(math.NaN()); err == nil {
+		t.Errorf("unbounded NaN got: nil, want: error")
+	}
+}
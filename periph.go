@@ -0,0 +1,43 @@
+package ring
+
+import (
+	"fmt"
+
+	"periph.io/x/conn/v3/physic"
+	"periph.io/x/conn/v3/spi"
+)
+
+// PeriphSPI returns a driver that drives the LEDs through the MOSI pin of a
+// periph.io SPI port, for boards other than the Raspberry Pi that periph
+// supports. The host drivers must be initialized, with host.Init from
+// periph.io/x/host, before opening the port. The port is closed with the
+// ring if it is a spi.PortCloser.
+func PeriphSPI(port spi.Port) Driver {
+	return &periphDriver{port: port}
+}
+
+type periphDriver struct {
+	port spi.Port
+}
+
+func (d *periphDriver) Open(options *Options) (Device, error) {
+	freq, err := spiFrequency(options)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := d.port.Connect(physic.Frequency(freq)*physic.Hertz, spi.Mode0|spi.NoCS, 8)
+	if err != nil {
+		return nil, &DeviceError{fmt.Sprintf("connect to SPI port %s", d.port), err}
+	}
+
+	write := func(b []byte) error {
+		return conn.Tx(b, nil)
+	}
+	close := func() {
+		if c, ok := d.port.(spi.PortCloser); ok {
+			c.Close()
+		}
+	}
+	return newSPIDevice(write, close, options), nil
+}
@@ -0,0 +1,133 @@
+package ring
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Plasma is a layer that paints the sum of sine waves of different lengths,
+// moving around the ring at different speeds, through a palette, the classic
+// plasma of demos. Add it to a ring with AddLayer and run the ring (see
+// Ring.Run) to animate it. Its parameters are "palette", "frequency" and
+// "speed" (see Tunable).
+type Plasma struct {
+	*Layer
+	params
+
+	mu        sync.Mutex
+	palette   Palette
+	frequency float64 // waves of the base sine around the ring
+	speed     float64 // radians of phase per second
+	t         float64 // phase in radians
+}
+
+// NewPlasma creates a plasma that paints through a palette, with a frequency
+// of 2 and a speed of 1.
+func NewPlasma(p Palette, options *LayerOptions) (*Plasma, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	pl := &Plasma{
+		Layer:     l,
+		palette:   p,
+		frequency: 2,
+		speed:     1,
+	}
+	pl.params = params{specs: plasmaParams, get: pl.param, set: pl.setParam}
+	pl.paint()
+
+	return pl, nil
+}
+
+// SetPalette sets the palette the plasma is painted through.
+func (p *Plasma) SetPalette(palette Palette) {
+	p.mu.Lock()
+	p.palette = palette
+	p.mu.Unlock()
+
+	p.paint()
+}
+
+// SetFrequency sets how many times the base wave repeats around the ring.
+// Higher frequencies show more, smaller blobs.
+func (p *Plasma) SetFrequency(frequency float64) {
+	p.mu.Lock()
+	p.frequency = frequency
+	p.mu.Unlock()
+
+	p.paint()
+}
+
+// SetSpeed sets how fast the waves move.
+func (p *Plasma) SetSpeed(speed float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.speed = speed
+}
+
+var plasmaParams = []ParamSpec{
+	{Name: "palette", Type: ParamPalette, Default: "party", Doc: "palette of the plasma"},
+	{Name: "frequency", Type: ParamFloat, Default: "2", Doc: "waves around the ring, larger is smaller", Min: 0.1, Max: 16},
+	{Name: "speed", Type: ParamFloat, Default: "1", Doc: "how fast the waves move", Min: -10, Max: 10},
+}
+
+func (p *Plasma) param(name string) interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch name {
+	case "palette":
+		return p.palette
+	case "frequency":
+		return p.frequency
+	case "speed":
+		return p.speed
+	}
+	return nil
+}
+
+func (p *Plasma) setParam(name string, v interface{}) {
+	switch name {
+	case "palette":
+		p.SetPalette(v.(Palette))
+	case "frequency":
+		p.SetFrequency(v.(float64))
+	case "speed":
+		p.SetSpeed(v.(float64))
+	}
+}
+
+func (p *Plasma) advance(dt time.Duration) {
+	p.mu.Lock()
+	p.t += p.speed * dt.Seconds()
+	p.mu.Unlock()
+
+	p.paint()
+	p.Layer.advance(dt)
+}
+
+// paint sums three waves: the base wave and a wave half as long moving in
+// opposite directions, and a shorter wave that warps back and forth over time.
+// The sum wraps seamlessly around the ring for whole frequencies.
+func (p *Plasma) paint() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	l := p.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, t := p.frequency, p.t
+	for i := range l.pixels {
+		a := float64(i) * l.pixArc
+		v := math.Sin(f*a+t) +
+			math.Sin(2*f*a-1.3*t) +
+			math.Sin(3*f*a+2*math.Sin(0.4*t)*math.Cos(f*a)+0.7*t)
+		l.pixels[i] = p.palette.at64(v/6 + 0.5)
+	}
+	l.update()
+}
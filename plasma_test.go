@@ -0,0 +1,37 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestPlasma(t *testing.T) {
+	p, err := NewPlasma(NewPalette(color.Black, color.White), &LayerOptions{Resolution: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	varied := false
+	for i := 1; i < 12; i++ {
+		if p.pixel64(i) != p.pixel64(0) {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Errorf("got: flat plasma, want: waves")
+	}
+
+	before := p.pixel64(0)
+	p.advance(time.Second)
+	if after := p.pixel64(0); after == before {
+		t.Errorf("got: %v after advancing, want: changed color", after)
+	}
+
+	p.SetSpeed(0)
+	before = p.pixel64(3)
+	p.advance(time.Second)
+	if after := p.pixel64(3); after != before {
+		t.Errorf("got: %v, want: still %v", after, before)
+	}
+}
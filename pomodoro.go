@@ -0,0 +1,170 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"time"
+)
+
+// PomodoroPhase is a phase of a pomodoro.
+type PomodoroPhase int
+
+const (
+	// PomodoroWork is a work session.
+	PomodoroWork PomodoroPhase = iota
+	// PomodoroShortBreak is a break between work sessions.
+	PomodoroShortBreak
+	// PomodoroLongBreak is a break after a set of work sessions.
+	PomodoroLongBreak
+)
+
+func (p PomodoroPhase) String() string {
+	switch p {
+	case PomodoroWork:
+		return "work"
+	case PomodoroShortBreak:
+		return "short break"
+	case PomodoroLongBreak:
+		return "long break"
+	default:
+		return fmt.Sprintf("PomodoroPhase(%d)", int(p))
+	}
+}
+
+// PomodoroOptions is the list of options of a pomodoro.
+type PomodoroOptions struct {
+	// Work is the duration of the work sessions (default: 25 minutes).
+	Work time.Duration
+	// ShortBreak is the duration of the breaks between work sessions
+	// (default: 5 minutes).
+	ShortBreak time.Duration
+	// LongBreak is the duration of the break after Rounds work sessions
+	// (default: 15 minutes).
+	LongBreak time.Duration
+	// Rounds is the number of work sessions before a long break (default:
+	// 4).
+	Rounds int
+	// WorkPalette paints the timer during work sessions (default:
+	// PaletteHeat).
+	WorkPalette Palette
+	// BreakPalette paints the timer during breaks (default: PaletteOcean).
+	BreakPalette Palette
+}
+
+// Pomodoro is a timer (see Timer) that alternates work sessions and breaks,
+// with a long break after a set of work sessions, painted with a palette per
+// phase. It moves to the next phase on its own once a phase ends. Add it to a
+// ring with AddLayer, start it, and run the ring (see Ring.Run) to count down.
+type Pomodoro struct {
+	*Timer
+
+	mu      sync.Mutex
+	opt     PomodoroOptions
+	phase   PomodoroPhase
+	round   int // work sessions done since the last long break
+	onPhase []func(p PomodoroPhase)
+}
+
+// NewPomodoro creates a stopped pomodoro at the start of a work session, with
+// the defaults of the options.
+func NewPomodoro(options *PomodoroOptions, layerOptions *LayerOptions) (*Pomodoro, error) {
+	p := &Pomodoro{}
+	if options != nil {
+		p.opt = *options
+	}
+	if p.opt.Work <= 0 {
+		p.opt.Work = 25 * time.Minute
+	}
+	if p.opt.ShortBreak <= 0 {
+		p.opt.ShortBreak = 5 * time.Minute
+	}
+	if p.opt.LongBreak <= 0 {
+		p.opt.LongBreak = 15 * time.Minute
+	}
+	if p.opt.Rounds <= 0 {
+		p.opt.Rounds = 4
+	}
+	if p.opt.WorkPalette == nil {
+		p.opt.WorkPalette = PaletteHeat
+	}
+	if p.opt.BreakPalette == nil {
+		p.opt.BreakPalette = PaletteOcean
+	}
+
+	t, err := NewTimer(p.opt.Work, color.Transparent, layerOptions)
+	if err != nil {
+		return nil, err
+	}
+	t.SetPalette(p.opt.WorkPalette)
+	t.OnDone(p.next)
+	p.Timer = t
+
+	return p, nil
+}
+
+// Pause stops counting down, keeping the remaining time of the phase.
+func (p *Pomodoro) Pause() {
+	p.Stop()
+}
+
+// Resume continues counting down after Pause.
+func (p *Pomodoro) Resume() {
+	p.Start()
+}
+
+// Phase returns the current phase.
+func (p *Pomodoro) Phase() PomodoroPhase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.phase
+}
+
+// OnPhase calls f when a new phase starts, with the phase. f is called from
+// the goroutine that advances the pomodoro, usually Ring.Run, so it must not
+// block.
+func (p *Pomodoro) OnPhase(f func(phase PomodoroPhase)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onPhase = append(p.onPhase, f)
+}
+
+// Skip ends the current phase and starts the next one.
+func (p *Pomodoro) Skip() {
+	p.next()
+}
+
+// next starts the phase after the current one.
+func (p *Pomodoro) next() {
+	p.mu.Lock()
+	if p.phase == PomodoroWork {
+		p.round++
+		if p.round >= p.opt.Rounds {
+			p.round = 0
+			p.phase = PomodoroLongBreak
+		} else {
+			p.phase = PomodoroShortBreak
+		}
+	} else {
+		p.phase = PomodoroWork
+	}
+	phase := p.phase
+	d, palette := p.opt.Work, p.opt.WorkPalette
+	switch phase {
+	case PomodoroShortBreak:
+		d, palette = p.opt.ShortBreak, p.opt.BreakPalette
+	case PomodoroLongBreak:
+		d, palette = p.opt.LongBreak, p.opt.BreakPalette
+	}
+	fs := p.onPhase
+	p.mu.Unlock()
+
+	p.Reset(d)
+	p.SetPalette(palette)
+	p.Start()
+	for _, f := range fs {
+		f(phase)
+	}
+}
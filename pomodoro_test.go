@@ -0,0 +1,54 @@
+package ring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPomodoro(t *testing.T) {
+	p, err := NewPomodoro(&PomodoroOptions{
+		Work:       4 * time.Second,
+		ShortBreak: time.Second,
+		LongBreak:  2 * time.Second,
+		Rounds:     2,
+	}, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var phases []PomodoroPhase
+	p.OnPhase(func(phase PomodoroPhase) { phases = append(phases, phase) })
+
+	tests := []struct {
+		name      string
+		do        func()
+		phase     PomodoroPhase
+		remaining time.Duration
+	}{
+		{"stopped", func() { p.advance(time.Second) }, PomodoroWork, 4 * time.Second},
+		{"work", func() { p.Start(); p.advance(time.Second) }, PomodoroWork, 3 * time.Second},
+		{"paused", func() { p.Pause(); p.advance(time.Second) }, PomodoroWork, 3 * time.Second},
+		{"short break", func() { p.Resume(); p.advance(3 * time.Second) }, PomodoroShortBreak, time.Second},
+		{"work again", func() { p.advance(time.Second) }, PomodoroWork, 4 * time.Second},
+		{"long break", func() { p.advance(4 * time.Second) }, PomodoroLongBreak, 2 * time.Second},
+		{"skip", func() { p.Skip() }, PomodoroWork, 4 * time.Second},
+	}
+
+	for _, ts := range tests {
+		ts.do()
+		if got := p.Phase(); got != ts.phase {
+			t.Errorf("%s got: %v, want: %v", ts.name, got, ts.phase)
+		}
+		if got := p.Remaining(); got != ts.remaining {
+			t.Errorf("%s got: %v remaining, want: %v", ts.name, got, ts.remaining)
+		}
+	}
+
+	want := []PomodoroPhase{PomodoroShortBreak, PomodoroWork, PomodoroLongBreak, PomodoroWork}
+	if !reflect.DeepEqual(phases, want) {
+		t.Errorf("got: %v, want: %v", phases, want)
+	}
+	if got, want := p.pixel64(0), PaletteHeat.at64(0.125); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package ring
+
+// milliampsPerChannel is the approximate current drawn by a single color
+// channel of an LED at full output. A full-white LED draws about three times
+// this value.
+const milliampsPerChannel = 20
+
+// estimateCurrent returns the approximate current, in milliamps, drawn by the
+// LEDs to display a serialized frame.
+func estimateCurrent(frame []uint32) int {
+	sum := 0
+	for _, c := range frame {
+		sum += int(c>>16&0xFF) + int(c>>8&0xFF) + int(c&0xFF)
+	}
+
+	return sum * milliampsPerChannel / 0xFF
+}
+
+// limitCurrent scales down a serialized frame so it draws at most max
+// milliamps, and returns the estimated current of the resulting frame.
+func limitCurrent(frame []uint32, max int) int {
+	current := estimateCurrent(frame)
+	if max <= 0 || current <= max {
+		return current
+	}
+
+	scale := func(v uint32) uint32 {
+		return v * uint32(max) / uint32(current)
+	}
+	for i, c := range frame {
+		frame[i] = scale(c>>16&0xFF)<<16 |
+			scale(c>>8&0xFF)<<8 |
+			scale(c&0xFF)
+	}
+
+	return estimateCurrent(frame)
+}
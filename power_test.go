@@ -0,0 +1,51 @@
+package ring
+
+import (
+	"testing"
+)
+
+func TestLimitCurrent(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []uint32
+		max   int
+		want  []uint32
+		mA    int
+	}{
+		{
+			"no limit",
+			[]uint32{0xFFFFFF, 0xFFFFFF},
+			0,
+			[]uint32{0xFFFFFF, 0xFFFFFF},
+			120,
+		},
+		{
+			"under budget",
+			[]uint32{0xFF0000, 0x00FF00},
+			60,
+			[]uint32{0xFF0000, 0x00FF00},
+			40,
+		},
+		{
+			"over budget",
+			[]uint32{0xFFFFFF, 0xFFFFFF},
+			60,
+			[]uint32{0x7F7F7F, 0x7F7F7F},
+			59,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			mA := limitCurrent(ts.frame, ts.max)
+			if mA != ts.mA {
+				t.Errorf("got: %d mA, want: %d mA", mA, ts.mA)
+			}
+			for i := range ts.want {
+				if ts.frame[i] != ts.want[i] {
+					t.Errorf("got: %#x, want: %#x", ts.frame[i], ts.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package ring
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Preset is a named stack of effects with the values of their parameters, to
+// save a look of the ring and recall it later (see PresetStore).
+type Preset struct {
+	// Name is the name of the preset.
+	Name string `json:"name"`
+	// Layers are the effects of the preset, from bottom to top.
+	Layers []PresetLayer `json:"layers"`
+}
+
+// PresetLayer is an effect of a preset.
+type PresetLayer struct {
+	// Name identifies the layer (see LayerOptions.Name).
+	Name string `json:"name,omitempty"`
+	// Effect is the name of the effect in the registry, such as "comet".
+	Effect string `json:"effect"`
+	// Params are the values of the parameters of the effect, as text.
+	// Parameters that are not given take their default values.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// CaptureLayer records an effect created by name, such as with NewEffect,
+// with the current values of its parameters if it is Tunable.
+func CaptureLayer(name, effect string, e Pixeler) PresetLayer {
+	pl := PresetLayer{
+		Name:   name,
+		Effect: effect,
+	}
+	if t, ok := e.(Tunable); ok {
+		pl.Params = make(map[string]string)
+		for _, p := range t.Params() {
+			pl.Params[p.Name] = p.String()
+		}
+	}
+
+	return pl
+}
+
+// Effects creates the effects of the preset with the effects of a registry,
+// from bottom to top, with a resolution of size pixels.
+func (p *Preset) Effects(g *Registry, size int) ([]Pixeler, error) {
+	es := make([]Pixeler, len(p.Layers))
+	for i, pl := range p.Layers {
+		e, err := g.New(pl.Effect, pl.Params, &LayerOptions{Name: pl.Name, Resolution: size})
+		if err != nil {
+			return nil, fmt.Errorf("ring: layer %d of preset %q: %w", i, p.Name, err)
+		}
+		es[i] = e
+	}
+
+	return es, nil
+}
+
+// AddTo creates the effects of the preset with DefaultRegistry and adds them
+// to a ring with AddEffect. It returns the effects, so they can be removed
+// with RemoveEffect. If any effect cannot be created, none is added.
+func (p *Preset) AddTo(r *Ring) ([]Pixeler, error) {
+	es, err := p.Effects(DefaultRegistry, r.Size())
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range es {
+		r.AddEffect(e)
+	}
+
+	return es, nil
+}
+
+// PresetStore keeps presets in a JSON file, in the order in which they were
+// first saved.
+type PresetStore struct {
+	mu      sync.Mutex
+	path    string
+	presets []*Preset
+}
+
+// OpenPresets opens the presets saved in the file at path. If the file does
+// not exist, the store starts empty and the file is created on the first
+// save.
+func OpenPresets(path string) (*PresetStore, error) {
+	s := &PresetStore{path: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ring: could not read presets: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.presets); err != nil {
+		return nil, fmt.Errorf("ring: could not parse presets %q: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Presets returns the saved presets.
+func (s *PresetStore) Presets() []*Preset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Preset(nil), s.presets...)
+}
+
+// Get returns a preset by name or, if no preset has that name, by its index
+// in Presets, such as "0".
+func (s *PresetStore) Get(nameOrIndex string) (*Preset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.index(nameOrIndex); i >= 0 {
+		return s.presets[i], nil
+	}
+	if i, err := strconv.Atoi(nameOrIndex); err == nil && i >= 0 && i < len(s.presets) {
+		return s.presets[i], nil
+	}
+
+	return nil, fmt.Errorf("ring: unknown preset %q", nameOrIndex)
+}
+
+// Save saves a preset to the file, replacing the preset with the same name.
+func (s *PresetStore) Save(p *Preset) error {
+	if p.Name == "" {
+		return fmt.Errorf("ring: missing preset name")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	presets := append([]*Preset(nil), s.presets...)
+	if i := s.index(p.Name); i >= 0 {
+		presets[i] = p
+	} else {
+		presets = append(presets, p)
+	}
+	if err := s.write(presets); err != nil {
+		return err
+	}
+	s.presets = presets
+
+	return nil
+}
+
+// Delete removes a preset from the file.
+func (s *PresetStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("ring: unknown preset %q", name)
+	}
+	presets := append(append([]*Preset(nil), s.presets[:i]...), s.presets[i+1:]...)
+	if err := s.write(presets); err != nil {
+		return err
+	}
+	s.presets = presets
+
+	return nil
+}
+
+func (s *PresetStore) index(name string) int {
+	for i, p := range s.presets {
+		if p.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// write replaces the file with the presets, through a temporary file so the
+// file is never left half written.
+func (s *PresetStore) write(presets []*Preset) error {
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ring: could not encode presets: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ring: could not save presets: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ring: could not save presets: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPresetStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "presets.json")
+
+	s, err := OpenPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewEffect("comet", map[string]string{"speed": "360"}, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*Preset{
+		{Name: "night", Layers: []PresetLayer{{Effect: "solid", Params: map[string]string{"color": "#000010"}}}},
+		{Name: "comet", Layers: []PresetLayer{CaptureLayer("fg", "comet", e)}},
+		{Name: "night", Layers: []PresetLayer{{Effect: "solid", Params: map[string]string{"color": "#100000"}}}},
+	} {
+		if err := s.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Save(&Preset{}); err == nil {
+		t.Errorf("no name got: nil, want: error")
+	}
+
+	s, err = OpenPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		get     string
+		want    string
+		wantErr bool
+	}{
+		{"night", "night", false},
+		{"comet", "comet", false},
+		{"0", "night", false},
+		{"1", "comet", false},
+		{"2", "", true},
+		{"day", "", true},
+	}
+
+	for _, ts := range tests {
+		p, err := s.Get(ts.get)
+		if (err != nil) != ts.wantErr {
+			t.Errorf("%q got: %v, want error: %v", ts.get, err, ts.wantErr)
+			continue
+		}
+		if err == nil && p.Name != ts.want {
+			t.Errorf("%q got: %q, want: %q", ts.get, p.Name, ts.want)
+		}
+	}
+
+	p, _ := s.Get("comet")
+	if got, want := p.Layers[0].Params["speed"], "360"; got != want {
+		t.Errorf("speed got: %q, want: %q", got, want)
+	}
+	es, err := p.Effects(DefaultRegistry, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := es[0].(Tunable).Params(), e.(Tunable).Params(); len(got) != len(want) || got[1] != want[1] {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	p, _ = s.Get("night")
+	es, err = p.Effects(DefaultRegistry, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := color.RGBAModel.Convert(es[0].Pixel(0)), (color.RGBA{0x10, 0x00, 0x00, 0xFF}); got != want {
+		t.Errorf("replaced preset got: %#v, want: %#v", got, want)
+	}
+
+	if err := s.Delete("night"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("night"); err == nil {
+		t.Errorf("deleted twice got: nil, want: error")
+	}
+	s, err = OpenPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Presets()); got != 1 {
+		t.Errorf("got: %d presets, want: 1", got)
+	}
+}
+
+func TestCaptureCustomPalette(t *testing.T) {
+	e, err := NewEffect("rainbow", nil, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := NewPalette(color.RGBA{0xFF, 0x00, 0x00, 0xFF}, color.RGBA{0x00, 0x00, 0xFF, 0xFF})
+	if err := e.(Tunable).SetParam("palette", custom); err != nil {
+		t.Fatal(err)
+	}
+
+	pl := CaptureLayer("bg", "rainbow", e)
+	if got, want := pl.Params["palette"], "#ff0000@0,#0000ff@1"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	p := &Preset{Name: "custom", Layers: []PresetLayer{pl}}
+	es, err := p.Effects(DefaultRegistry, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, param := range es[0].(Tunable).Params() {
+		if param.Name == "palette" && param.String() != pl.Params["palette"] {
+			t.Errorf("got: %s loaded, want: %s", param, pl.Params["palette"])
+		}
+	}
+}
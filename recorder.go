@@ -0,0 +1,229 @@
+package ring
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// recordMagic identifies a stream of recorded frames.
+const recordMagic = "RING\x01"
+
+// maxRecordLeds is the most LEDs per recorded frame, so corrupt recordings
+// cannot make players allocate huge frames.
+const maxRecordLeds = 1 << 16
+
+// Recorder writes the frames rendered by a ring to a compact binary stream,
+// with the time at which they were rendered (see Ring.SetRecorder). Frames are
+// recorded as sent to the LEDs, after brightness scaling.
+type Recorder struct {
+	mu    sync.Mutex
+	w     *bufio.Writer
+	clock Clock
+	start time.Time
+	last  time.Duration               // time of the last frame, as recorded
+	size  int                         // number of LEDs per frame, 0 before the first frame
+	buf   []byte                      // colors of a frame, reused between frames
+	tmp   [binary.MaxVarintLen64]byte // scratch buffer of varints
+}
+
+// NewRecorder creates a recorder that writes to w. Call Flush once done
+// recording.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:     bufio.NewWriter(w),
+		clock: systemClock{},
+	}
+}
+
+// WriteFrame records a frame of colors with the shape 0x00RRGGBB. All the
+// frames of a recording must have the same number of LEDs, up to 65536.
+func (rec *Recorder) WriteFrame(frame []uint32) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	now := rec.clock.Now()
+	if rec.size == 0 {
+		if len(frame) == 0 || len(frame) > maxRecordLeds {
+			return fmt.Errorf("ring: cannot record frames of %d LEDs", len(frame))
+		}
+		rec.start = now
+		rec.size = len(frame)
+		if _, err := rec.w.WriteString(recordMagic); err != nil {
+			return err
+		}
+		if err := rec.writeUvarint(uint64(rec.size)); err != nil {
+			return err
+		}
+	}
+	if len(frame) != rec.size {
+		return fmt.Errorf("ring: recorded frame has %d LEDs, want %d", len(frame), rec.size)
+	}
+
+	// Times are recorded in microseconds, so the time of the frame is
+	// advanced by the recorded delta, and the truncation does not add up.
+	delta := (now.Sub(rec.start) - rec.last) / time.Microsecond
+	if err := rec.writeUvarint(uint64(delta)); err != nil {
+		return err
+	}
+	rec.last += delta * time.Microsecond
+	rec.buf = rec.buf[:0]
+	for _, c := range frame {
+		rec.buf = append(rec.buf, byte(c>>16), byte(c>>8), byte(c))
+	}
+	_, err := rec.w.Write(rec.buf)
+
+	return err
+}
+
+func (rec *Recorder) writeUvarint(v uint64) error {
+	n := binary.PutUvarint(rec.tmp[:], v)
+	_, err := rec.w.Write(rec.tmp[:n])
+	return err
+}
+
+// Flush writes any buffered frames to the underlying writer.
+func (rec *Recorder) Flush() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return rec.w.Flush()
+}
+
+// SetRecorder records every frame rendered by the ring, timed by the clock of
+// the ring (see Options.Clock). A nil recorder stops the recording.
+func (r *Ring) SetRecorder(rec *Recorder) {
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	if rec != nil && r.opt.Clock != nil {
+		rec.mu.Lock()
+		rec.clock = r.opt.Clock
+		rec.mu.Unlock()
+	}
+	r.recorder = rec
+}
+
+// Frame is a recorded frame.
+type Frame struct {
+	// At is the time since the first frame of the recording.
+	At time.Duration
+	// Leds are the colors of the LEDs, with the shape 0x00RRGGBB.
+	Leds []uint32
+}
+
+// Player reads frames recorded by a Recorder.
+type Player struct {
+	r    *bufio.Reader
+	size int
+	at   time.Duration
+}
+
+// NewPlayer creates a player that reads a recording from r.
+func NewPlayer(r io.Reader) (*Player, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(recordMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != recordMagic {
+		return nil, fmt.Errorf("ring: not a frame recording")
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("ring: could not read recording: %w", err)
+	}
+	if size == 0 || size > maxRecordLeds {
+		return nil, fmt.Errorf("ring: recording has invalid frames of %d LEDs", size)
+	}
+
+	return &Player{
+		r:    br,
+		size: int(size),
+	}, nil
+}
+
+// Size returns the number of LEDs of the recorded frames.
+func (p *Player) Size() int {
+	return p.size
+}
+
+// Next reads the next frame of the recording. It returns io.EOF when there are
+// no more frames.
+func (p *Player) Next() (*Frame, error) {
+	delta, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return nil, err
+	}
+	p.at += time.Duration(delta) * time.Microsecond
+
+	buf := make([]byte, 3*p.size)
+	if _, err := io.ReadFull(p.r, buf); err != nil {
+		return nil, fmt.Errorf("ring: truncated frame: %w", err)
+	}
+	f := &Frame{
+		At:   p.at,
+		Leds: make([]uint32, p.size),
+	}
+	for i := range f.Leds {
+		f.Leds[i] = uint32(buf[3*i])<<16 | uint32(buf[3*i+1])<<8 | uint32(buf[3*i+2])
+	}
+
+	return f, nil
+}
+
+// Play shows the frames of a recording on the ring, bypassing its layers,
+// until the recording ends or ctx is done. A speed of 1 keeps the original
+// timing, 2 plays twice as fast, and 0.5 plays at half speed.
+func (r *Ring) Play(ctx context.Context, p *Player, speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("ring: invalid playback speed %v", speed)
+	}
+	if p.Size() != r.Size() {
+		return fmt.Errorf("ring: recording has %d LEDs, want %d", p.Size(), r.Size())
+	}
+
+	clock := r.opt.clock()
+	start := clock.Now()
+	for {
+		f, err := p.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		wait := time.Duration(float64(f.At)/speed) - clock.Now().Sub(start)
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(wait):
+			}
+		}
+		if err := r.show(f.Leds); err != nil {
+			return err
+		}
+	}
+}
+
+// show sends a frame of colors with the shape 0x00RRGGBB, one per LED, to the
+// device as is, bypassing the layers, brightness and calibration of the ring.
+// Snapshot returns the frame as sent.
+func (r *Ring) show(frame []uint32) error {
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	r.mu.Lock()
+	copy(r.front, frame)
+	r.dirty = true
+	r.mu.Unlock()
+	for i := range r.shown {
+		r.shown[i] = deserialize(r.front[i])
+	}
+
+	return r.device.Render(r.front)
+}
@@ -0,0 +1,151 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	r, _ := newTestRing(t, 3)
+	l := newTestLayer(t, &LayerOptions{Resolution: 3})
+	r.AddLayer(l)
+
+	buf := &bytes.Buffer{}
+	rec := NewRecorder(buf)
+	r.SetRecorder(rec)
+
+	l.SetPixel(0, color.RGBA{0x12, 0x34, 0x56, 0xFF})
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	l.SetPixel(2, color.White)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPlayer(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]uint32{
+		{0x123456, 0, 0},
+		{0x123456, 0, 0xFFFFFF},
+	}
+	for _, w := range want {
+		f, err := p.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range w {
+			if f.Leds[i] != w[i] {
+				t.Errorf("got: %#x, want: %#x", f.Leds, w)
+				break
+			}
+		}
+	}
+	if _, err := p.Next(); err != io.EOF {
+		t.Errorf("got: %v, want: %v", err, io.EOF)
+	}
+}
+
+func TestShowSnapshot(t *testing.T) {
+	r, dev := newTestRing(t, 2)
+	r.SetBrightness(10)
+
+	frame := []uint32{0x123456, 0xFFFFFF}
+	if err := r.show(frame); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range r.Snapshot() {
+		if got, want := serialize(c), frame[i]; got != want {
+			t.Errorf("LED %d got: %#x, want: %#x", i, got, want)
+		}
+		if got, want := dev.leds[i], frame[i]; got != want {
+			t.Errorf("LED %d got: %#x sent, want: %#x", i, got, want)
+		}
+	}
+}
+
+func TestRecorderAllocs(t *testing.T) {
+	rec := NewRecorder(ioutil.Discard)
+	frame := make([]uint32, 64)
+	if err := rec.WriteFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		rec.WriteFrame(frame)
+	})
+	if allocs > 0 {
+		t.Errorf("got: %v allocations per frame, want: 0", allocs)
+	}
+}
+
+// stepClock is a clock that only tells the time, which tests move forward.
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time                         { return c.now }
+func (c *stepClock) NewTicker(d time.Duration) Ticker       { panic("not implemented") }
+func (c *stepClock) After(d time.Duration) <-chan time.Time { panic("not implemented") }
+
+func TestRecorderDrift(t *testing.T) {
+	clock := &stepClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	buf := &bytes.Buffer{}
+	rec := NewRecorder(buf)
+	rec.clock = clock
+
+	const frames = 1000
+	step := 16666667 * time.Nanosecond // 60 FPS
+	for i := 0; i < frames; i++ {
+		if err := rec.WriteFrame([]uint32{0}); err != nil {
+			t.Fatal(err)
+		}
+		clock.now = clock.now.Add(step)
+	}
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPlayer(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *Frame
+	for {
+		f, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = f
+	}
+	want := (frames - 1) * step
+	if drift := want - last.At; drift < 0 || drift >= time.Microsecond {
+		t.Errorf("got: last frame at %v, want: %v", last.At, want)
+	}
+}
+
+func TestPlayerInvalidSize(t *testing.T) {
+	for _, size := range []uint64{0, maxRecordLeds + 1, 1 << 40} {
+		data := append([]byte(recordMagic), make([]byte, binary.MaxVarintLen64)...)
+		n := binary.PutUvarint(data[len(recordMagic):], size)
+		if _, err := NewPlayer(bytes.NewReader(data[:len(recordMagic)+n])); err == nil {
+			t.Errorf("%d LEDs got: nil, want: error", size)
+		}
+	}
+	if err := NewRecorder(ioutil.Discard).WriteFrame(make([]uint32, maxRecordLeds+1)); err == nil {
+		t.Errorf("recording %d LEDs got: nil, want: error", maxRecordLeds+1)
+	}
+}
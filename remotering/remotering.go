@@ -0,0 +1,236 @@
+// Package remotering streams the frames rendered by a ring over the network to
+// an agent that displays them on the LEDs, so the layers and animations can
+// run on a more powerful computer than the one driving the LEDs.
+//
+// On the computer connected to the LEDs, run an agent:
+//
+//	r, err := ring.New(&ring.Options{LedCount: 12, MaxBrightness: 255})
+//	...
+//	log.Fatal(remotering.ListenAndServe("udp", ":7777", r))
+//
+// On the computer running the animations, create a ring that renders to it:
+//
+//	r, err := ring.New(&ring.Options{
+//		LedCount: 12,
+//		Driver:   remotering.Driver("udp", "raspberrypi.local:7777"),
+//	})
+//
+// Brightness, calibration and other color options are applied by the ring
+// that renders the frames. The agent shows the frames on a layer on top of the
+// layers of its ring, so its ring should have a MaxBrightness of 255 and no
+// color corrections, to show the frames as they arrive.
+package remotering
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"net"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Each frame is sent as a message with a header of the magic byte and the
+// number of LEDs (big-endian uint16), followed by 3 bytes (R, G, B) per LED.
+// Over UDP, each message is sent in its own datagram.
+const (
+	magic      = 'R'
+	headerSize = 3
+	maxLeds    = (0xFFFF - 8 - 20 - headerSize) / 3 // fits in a UDP datagram
+)
+
+// Device is a device that streams the frames rendered by a ring to an agent.
+type Device struct {
+	conn net.Conn
+	buf  []byte
+}
+
+// Dial connects to an agent at the address on the named network, "udp" or
+// "tcp".
+func Dial(network, addr string) (*Device, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("remotering: could not connect to agent: %w", err)
+	}
+
+	return &Device{conn: conn}, nil
+}
+
+// Render sends a frame to the agent.
+func (d *Device) Render(frame []uint32) error {
+	if len(frame) > maxLeds {
+		return fmt.Errorf("remotering: frame has %d LEDs, max %d", len(frame), maxLeds)
+	}
+
+	d.buf = append(d.buf[:0], magic, byte(len(frame)>>8), byte(len(frame)))
+	for _, c := range frame {
+		d.buf = append(d.buf, byte(c>>16), byte(c>>8), byte(c))
+	}
+	_, err := d.conn.Write(d.buf)
+
+	return err
+}
+
+// Close closes the connection to the agent.
+func (d *Device) Close() {
+	d.conn.Close()
+}
+
+// Driver returns a ring driver that streams frames to an agent at the address
+// on the named network, "udp" or "tcp".
+func Driver(network, addr string) ring.Driver {
+	return driver{network, addr}
+}
+
+type driver struct {
+	network, addr string
+}
+
+func (d driver) Open(options *ring.Options) (ring.Device, error) {
+	return Dial(d.network, d.addr)
+}
+
+// ListenAndServe listens on the address of the named network, "udp" or "tcp",
+// and shows the frames received on the ring.
+func ListenAndServe(network, addr string, r *ring.Ring) error {
+	switch network {
+	case "udp", "udp4", "udp6":
+		c, err := net.ListenPacket(network, addr)
+		if err != nil {
+			return fmt.Errorf("remotering: %w", err)
+		}
+		defer c.Close()
+		return ServePacket(c, r)
+	default:
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return fmt.Errorf("remotering: %w", err)
+		}
+		defer l.Close()
+		return Serve(l, r)
+	}
+}
+
+// screen shows the frames received on a ring, on a layer on top of its
+// layers.
+type screen struct {
+	r *ring.Ring
+	l *ring.Layer
+}
+
+func newScreen(r *ring.Ring) (*screen, error) {
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: r.Size()})
+	if err != nil {
+		return nil, err
+	}
+	r.AddLayer(l)
+
+	return &screen{r: r, l: l}, nil
+}
+
+// show renders a frame of 3 bytes (R, G, B) per LED on the ring.
+func (s *screen) show(buf []byte) error {
+	for i := 0; i < s.r.Size(); i++ {
+		s.l.SetPixel(i, color.RGBA{buf[3*i], buf[3*i+1], buf[3*i+2], 0xFF})
+	}
+
+	return s.r.Render()
+}
+
+// Serve accepts stream connections on the listener and shows the frames
+// received on the ring. Connections that send an invalid frame are closed. It
+// returns when the listener fails, for example when it is closed, or when the
+// ring fails to render a frame.
+func Serve(l net.Listener, r *ring.Ring) error {
+	s, err := newScreen(r)
+	if err != nil {
+		return err
+	}
+	errc := make(chan error, 1)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case err = <-errc:
+			default:
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			if err := s.serveConn(conn); err != nil {
+				select {
+				case errc <- err:
+					l.Close()
+				default:
+				}
+			}
+		}()
+	}
+}
+
+// serveConn shows the frames received on a stream connection until it is
+// closed or it receives an invalid frame. It returns the error of the ring if
+// it fails to render a frame.
+func (s *screen) serveConn(conn net.Conn) error {
+	br := bufio.NewReader(conn)
+	header := make([]byte, headerSize)
+	var buf []byte
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			return nil
+		}
+		n, err := parseHeader(header)
+		if err != nil || n != s.r.Size() {
+			return nil
+		}
+		if cap(buf) < 3*n {
+			buf = make([]byte, 3*n)
+		}
+		buf = buf[:3*n]
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil
+		}
+		if err := s.show(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// ServePacket shows the frames received on the packet connection on the ring.
+// Invalid datagrams are ignored. It returns when the connection fails, for
+// example when it is closed, or when the ring fails to render a frame.
+func ServePacket(c net.PacketConn, r *ring.Ring) error {
+	s, err := newScreen(r)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 0xFFFF)
+	for {
+		n, _, err := c.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		if n < headerSize {
+			continue
+		}
+		leds, err := parseHeader(buf[:headerSize])
+		if err != nil || leds != r.Size() || n != headerSize+3*leds {
+			continue
+		}
+		if err := s.show(buf[headerSize:n]); err != nil {
+			return err
+		}
+	}
+}
+
+// parseHeader returns the number of LEDs of a frame.
+func parseHeader(header []byte) (int, error) {
+	if header[0] != magic {
+		return 0, fmt.Errorf("remotering: invalid frame header %#x", header[0])
+	}
+
+	return int(binary.BigEndian.Uint16(header[1:])), nil
+}
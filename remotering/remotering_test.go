@@ -0,0 +1,130 @@
+package remotering
+
+import (
+	"errors"
+	"image/color"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestRemote(t *testing.T) {
+	tests := []struct {
+		name   string
+		listen func(t *testing.T, r *ring.Ring) (addr string, stop func())
+	}{
+		{
+			"tcp",
+			func(t *testing.T, r *ring.Ring) (string, func()) {
+				l, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				go Serve(l, r)
+				return l.Addr().String(), func() { l.Close() }
+			},
+		},
+		{
+			"udp",
+			func(t *testing.T, r *ring.Ring) (string, func()) {
+				c, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					t.Fatal(err)
+				}
+				go ServePacket(c, r)
+				return c.LocalAddr().String(), func() { c.Close() }
+			},
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			agent, dev := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+			addr, stop := ts.listen(t, agent)
+			defer stop()
+
+			r, err := ring.New(&ring.Options{
+				LedCount:      4,
+				MaxBrightness: 255,
+				Driver:        Driver(ts.name, addr),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			l, err := ring.NewLayer(&ring.LayerOptions{Resolution: 4})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.SetPixel(1, color.RGBA{0x12, 0x34, 0x56, 0xFF})
+			r.AddLayer(l)
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []uint32{0, 0x123456, 0, 0}
+			deadline := time.Now().Add(time.Second)
+			for !equal(dev.Frame(), want) {
+				if time.Now().After(deadline) {
+					t.Fatalf("got: %#v, want: %#v", dev.Frame(), want)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
+
+func equal(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// brokenDevice is a device that fails to render.
+type brokenDevice struct{}
+
+func (brokenDevice) Render(frame []uint32) error { return errors.New("bus error") }
+func (brokenDevice) Close()                      {}
+
+func TestServeError(t *testing.T) {
+	agent, err := ring.NewWithDevice(brokenDevice{}, &ring.Options{LedCount: 2, MaxBrightness: 255})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	errc := make(chan error, 1)
+	go func() { errc <- Serve(l, agent) }()
+
+	dev, err := Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dev.Close()
+	if err := dev.Render([]uint32{0xFF0000, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errc:
+		if err == nil || !strings.Contains(err.Error(), "bus error") {
+			t.Errorf("got: %v, want: render error", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("got: Serve still running, want: render error")
+	}
+}
@@ -4,19 +4,78 @@ import (
 	"fmt"
 	"image/color"
 	"math"
-	"os"
+	"sync"
+	"time"
 
 	ws2811 "github.com/rpi-ws281x/rpi-ws281x-go"
 )
 
 // Ring represents the WS2811 LED device.
 type Ring struct {
-	device    *ws2811.WS2811
+	device    Device
 	layers    []Pixeler
 	ledArc    float64
 	ledOffset int
 	offset    float64
+	rotOffset float64 // offset set by Options.RotationOffset, in LEDs
 	opt       *Options
+	clamp     bool  // do not wrap around when offset, guarded by mu
+	pixelMap  []int // physical position of each pixel, if not in order
+
+	minBri, maxBri int // output brightness range, guarded by mu
+	current        int // estimated current of the last frame, guarded by mu
+
+	tint       color.RGBA64 // color blended over the frame, guarded by mu
+	tintAmount float64      // amount of tint, guarded by mu
+	filter     Filter       // transform of the frame, guarded by mu
+	gamma      *GammaTable  // response curve of the LEDs, if corrected
+
+	renderMu sync.Mutex // serializes renders of the ring
+	mu       sync.Mutex // guards layers, offset, dirty and front
+	front    []uint32   // last composited frame, sent to the device
+	back     []uint32   // frame being composited off-screen
+
+	dirty    bool     // ring changed since the last render
+	versions []uint64 // layer versions at the last render
+
+	ditherErr []uint16 // quantization error of each color channel of each LED
+	dithered  bool     // last frame needs dithering, guarded by mu
+
+	animator *Animator // animations advanced by Run
+	paused   bool      // animations are frozen, guarded by mu
+	scale    float64   // speed of the animations, guarded by mu
+	stats    Stats     // statistics of Run, guarded by mu
+	rendered time.Time // time of the last call to Render, guarded by mu
+	recorder *Recorder // records rendered frames, guarded by renderMu
+	frames   int       // number of rendered frames, guarded by renderMu
+
+	syncPeriod time.Duration // period of SyncToClock, or 0, guarded by mu
+	phase      time.Duration // phase of the animations when synced, guarded by mu
+
+	timestep     time.Duration  // step of SetTimestep, or 0, guarded by mu
+	carry        time.Duration  // time left over from the last step, guarded by mu
+	updatePeriod time.Duration  // period of SetUpdateRate, or 0, guarded by mu
+	sinceUpdate  time.Duration  // time since the last update, guarded by renderMu
+	prevFrame    []color.RGBA64 // blended layers of the previous update, guarded by renderMu
+	nextFrame    []color.RGBA64 // blended layers of the last update, guarded by renderMu
+	alpha        float64        // progress from prevFrame to nextFrame, guarded by renderMu
+	moving       bool           // prevFrame and nextFrame differ, guarded by renderMu
+
+	notices notifier // notifications played above the layers
+
+	pixels []color.RGBA64 // scratch buffer of blended pixels
+	shown  []color.RGBA64 // colors of the last frame, guarded by renderMu
+}
+
+// Device is the output that displays the frames rendered by a ring. The
+// WS2811 LEDs driven by New are a device, and other outputs, such as mock
+// devices for tests, can be used with NewWithDevice.
+type Device interface {
+	// Render displays a frame of colors with the shape 0x00RRGGBB, one per
+	// LED.
+	Render(frame []uint32) error
+	// Close releases the device.
+	Close()
 }
 
 // Pixeler is an interface that returns the color of a pixel at a specific
@@ -26,14 +85,27 @@ type Pixeler interface {
 	Options() *LayerOptions
 }
 
+// pixeler64 is implemented by layers that can return their pixels without the
+// overhead of the color.Color interface.
+type pixeler64 interface {
+	pixel64(int) color.RGBA64
+}
+
+// versioner is implemented by layers that keep track of their changes. Layers
+// that do not implement it are considered to change on every render.
+type versioner interface {
+	version() uint64
+}
+
 // Options is the list of ring options.
 type Options struct {
 	// LedCount is the number of LEDs in the ring.
 	LedCount int
-	// MinBrightness is the minimum output of the LED> Goes from 0 to 255
+	// MinBrightness is the minimum output of the LED. Goes from 0 to 255
 	// (default: 0).
 	// MaxBrightness is the maximum output of the LED. Goes from 0 to 255
-	// (default: 64).
+	// (default: 64). Use WithBrightness(0) or SetBrightness(0) for a maximum of
+	// 0.
 	//
 	// The color will be scaled to these values. For example, color.RGBA{255,
 	// 255, 255, 255} will output led(R: 128, G: 128, B: 128) if MaxBrightness
@@ -43,99 +115,563 @@ type Options struct {
 	// GpioPin is the GPIO pin on the Raspberry Pi with PWM output (default:
 	// GPIO 18). *Do not confuse with the physical pin number*
 	GpioPin int
+	// StripType is the model and color order of the LEDs, for LEDs that do
+	// not take colors in the order of a WS2812 (default: StripGRB).
+	StripType StripType
+	// Invert inverts the output signal, for level shifters that invert it
+	// (default: false).
+	Invert bool
+	// Frequency is the frequency of the output signal in Hz. Older WS2811
+	// LEDs need 400000 (default: 800000).
+	// DmaChannel is the DMA channel used to drive the LEDs, which must not be
+	// in use by other devices (default: 10).
+	//
+	// Both are shared by all the channels of a controller, and are set by the
+	// options of the first channel.
+	Frequency, DmaChannel int
+	// MaxCurrentMilliamps is the power budget of the LEDs in milliamps. Frames
+	// estimated to draw more current are dimmed to fit the budget. A full-white
+	// LED draws about 60mA. For a channel split in Segments, it is the budget
+	// of all the segments together, and each segment can have its own budget
+	// too (default: 0, no limit).
+	MaxCurrentMilliamps int
+	// RotationOffset is a base angular offset (in radians) applied to all
+	// renders, to correct the orientation in which the ring is mounted. A
+	// positive angle rotates counter-clockwise. Offset is applied on top of it
+	// (default: 0).
+	RotationOffset float64
+	// PixelMap sets the physical position of each pixel of the ring, for LEDs
+	// that are not wired in order. For example, []int{11, 10, ..., 0} reverses
+	// a 12-LED ring, and []int{3, 4, ..., 11, 0, 1, 2} starts the ring at the
+	// fourth LED. It must have LedCount unique positions (default: nil, in
+	// order).
+	PixelMap []int
+	// LinearBlending blends the colors of all the layers, and of pixels between
+	// LEDs when offset, in linear light instead of in sRGB. Blends in linear
+	// light keep their brightness, while sRGB blends look too dark at the
+	// midpoints (default: false).
+	LinearBlending bool
+	// Dithering alternates the output of each LED between adjacent levels
+	// across frames to simulate colors between them, which smooths fades at
+	// low brightness. It needs the ring to render continuously (see Run)
+	// (default: false).
+	Dithering bool
+	// Calibration corrects the color response of each LED, by physical
+	// position, scaling each color channel. LEDs without calibration are not
+	// corrected (default: nil).
+	Calibration []ColorScale
+	// Gamma corrects the response curve of each color channel of the LEDs
+	// (default: zero Gamma, no correction).
+	// GammaTable is a custom response curve of each color channel, used
+	// instead of Gamma if set (default: nil).
+	Gamma      Gamma
+	GammaTable *GammaTable
+	// Segments splits the LEDs of a PWM channel into several rings wired in
+	// series, each configured by its own options, in order from the closest
+	// to the Raspberry Pi. Only supported by NewController, which returns a
+	// ring for each segment; the LedCount of the channel is the sum of the
+	// LedCount of its segments.
+	Segments []*Options
+	// Driver opens the device that drives the LEDs, such as SPI or PeriphSPI.
+	// If set, options of the PWM output, such as GpioPin, DmaChannel and
+	// Segments, are ignored (default: nil, PWM on a Raspberry Pi).
+	Driver Driver
+	// Logger logs the events of the ring, such as the start of the device and
+	// render errors. Only the logger of the first channel is used by
+	// NewController to log the events of the device (default: nil, no logs).
+	Logger Logger
+	// Clock tells the time to Run, Play and the recorder of the ring, and
+	// to the packages that follow it (see Ring.Clock). Tests can use a fake
+	// clock to render animations deterministically (default:
+	// nil, the clock of the system).
+	Clock Clock
+	// StartupAnimation is played when the ring is created by New or
+	// NewWithDevice, which return once it ends (default: nil, none).
+	// ShutdownAnimation is played when the ring is closed, before its LEDs
+	// are turned off (default: nil, none). See WipeIn and FadeOut for
+	// built-in animations.
+	StartupAnimation, ShutdownAnimation *PowerAnimation
+	// FrameDump writes rendered frames as PNG images, for debugging
+	// (default: nil, no images).
+	FrameDump *FrameDump
+
+	maxBrightnessSet bool // MaxBrightness was set with WithBrightness
 }
 
-// New creates a new LED ring with given options.
-func New(options *Options) (*Ring, error) {
-	if os.Getuid() != 0 {
-		return nil, fmt.Errorf("ring: rpi-ws281x needs root permissions (try running as sudo)")
-	}
+// Driver opens the device that drives the LEDs of a ring, for outputs other
+// than the PWM of a Raspberry Pi.
+type Driver interface {
+	Open(options *Options) (Device, error)
+}
 
-	opt := ws2811.DefaultOptions
-	if options.LedCount != 0 {
-		opt.Channels[0].LedCount = options.LedCount
+// New creates a new LED ring with given options, applied in order. The
+// options can be set at once with an *Options, or one by one with functional
+// options:
+//
+//	r, err := ring.New(ring.WithLEDCount(12), ring.WithGPIO(18), ring.WithBrightness(180))
+//
+// To drive rings on both PWM channels, or a chain of rings split in Segments,
+// use NewController.
+func New(opts ...Option) (*Ring, error) {
+	options, err := buildOptions(opts)
+	if err != nil {
+		return nil, err
 	}
-	if options.MaxBrightness != 0 {
-		opt.Channels[0].Brightness = options.MaxBrightness
+	if err := options.validate(); err != nil {
+		return nil, err
 	}
-	if options.GpioPin != 0 {
-		opt.Channels[0].GpioPin = options.GpioPin
+	if len(options.Segments) != 0 {
+		return nil, fmt.Errorf("%w: segments need NewController, which returns a ring for each segment", ErrInvalidOptions)
+	}
+
+	if options.Driver != nil {
+		log := options.logger()
+		dev, err := options.Driver.Open(options)
+		if err != nil {
+			log.Error("could not open device", "err", err)
+			return nil, err
+		}
+		r, err := NewWithDevice(dev, options)
+		if err != nil {
+			dev.Close()
+			return nil, err
+		}
+		log.Info("device opened", "leds", options.LedCount)
+		return r, nil
 	}
 
-	dev, err := ws2811.MakeWS2811(&opt)
+	c, err := NewController(options)
 	if err != nil {
-		return nil, fmt.Errorf("ring: could not create ws2811 device: %w", err)
+		return nil, err
+	}
+	r := c.Ring(0)
+	if err := r.playPowerAnimation(options.StartupAnimation); err != nil {
+		c.Close()
+		return nil, err
 	}
 
-	r := &Ring{
-		device: dev,
-		ledArc: 2 * math.Pi / float64(options.LedCount),
-		opt:    options,
+	return r, nil
+}
+
+// NewWithDevice creates a new ring with given options that renders to a
+// device. Options that configure the WS2811 LEDs, such as GpioPin, are ignored.
+// If the startup animation fails, the device is closed.
+func NewWithDevice(dev Device, options *Options) (*Ring, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if len(options.Segments) != 0 {
+		return nil, fmt.Errorf("%w: segments are only supported by the PWM output", ErrInvalidOptions)
 	}
 
-	if err := r.device.Init(); err != nil {
-		return nil, fmt.Errorf("ring: could not start ws2811 device: %w", err)
+	r := newRing(dev, options)
+	if err := r.playPowerAnimation(options.StartupAnimation); err != nil {
+		dev.Close()
+		return nil, err
 	}
 
 	return r, nil
 }
 
+// maxBrightness returns the maximum brightness of the options, after the
+// default.
+func (o *Options) maxBrightness() int {
+	if o.MaxBrightness == 0 && !o.maxBrightnessSet {
+		return ws2811.DefaultBrightness
+	}
+	return o.MaxBrightness
+}
+
+// newRing creates a ring that renders to an initialized device.
+func newRing(dev Device, options *Options) *Ring {
+	r := &Ring{
+		device:   dev,
+		minBri:   options.MinBrightness,
+		maxBri:   options.maxBrightness(),
+		ledArc:   2 * math.Pi / float64(options.LedCount),
+		opt:      options,
+		front:    make([]uint32, options.LedCount),
+		back:     make([]uint32, options.LedCount),
+		dirty:    true,
+		animator: NewAnimator(),
+		pixels:   make([]color.RGBA64, options.LedCount),
+		shown:    make([]color.RGBA64, options.LedCount),
+		pixelMap: options.PixelMap,
+	}
+	if options.GammaTable != nil {
+		r.gamma = options.GammaTable
+	} else if options.Gamma != (Gamma{}) {
+		r.gamma = options.Gamma.Table()
+	}
+	if options.Dithering {
+		r.ditherErr = make([]uint16, 3*options.LedCount)
+	}
+	r.rotOffset = options.RotationOffset / r.ledArc
+	r.scale = 1
+
+	return r
+}
+
 // Render updates the LED ring.
+//
+// The frame is composited off-screen and swapped with the displayed frame
+// once it is complete, so the device never shows a partially composited
+// frame. Layers are read one pixel at a time, so a layer changed by another
+// goroutine during a render may show partly changed until the next render. If
+// nothing changed since the last render, Render does nothing.
 func (r *Ring) Render() error {
-	pixels := make([]color.Color, r.Size())
-	pixel := make([]color.Color, len(r.layers))
-
-	for i := range r.device.Leds(0) {
-		for j, l := range r.layers {
-			switch l.Options().ContentMode {
-			case ContentTile:
-				pixel[j] = l.Pixel(i)
-			case ContentCrop:
-				if i < l.Options().Resolution {
-					pixel[j] = l.Pixel(i)
-				} else {
-					pixel[j] = color.Transparent
-				}
-			case ContentScale:
-				pixel[j] = l.Pixel(scale(i, r.Size(), l.Options().Resolution))
-			}
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	r.mu.Lock()
+	state := r.state()
+	dirty := r.checkDirty()
+	r.rendered = r.opt.clock().Now()
+	r.mu.Unlock()
+
+	if !dirty {
+		return nil
+	}
+
+	dithered := r.composeFrame(r.back, state, r.shown, r.ditherErr)
+	current := limitCurrent(r.back, r.opt.MaxCurrentMilliamps)
+
+	r.mu.Lock()
+	r.front, r.back = r.back, r.front
+	r.current = current
+	r.dithered = dithered
+	r.mu.Unlock()
+
+	if err := r.device.Render(r.front); err != nil {
+		r.opt.logger().Error("could not render frame", "err", err)
+		return err
+	}
+	if r.recorder != nil {
+		if err := r.recorder.WriteFrame(r.front); err != nil {
+			return fmt.Errorf("ring: could not record frame: %w", err)
 		}
-		pixels[i] = blendOver(pixel...)
 	}
-	rotInt := math.Floor(r.offset)
-	rotFloat := r.offset - rotInt
-	for i := range r.device.Leds(0) {
-		r.device.Leds(0)[i] = serialize(lerp(int(rotInt)+i, pixels, rotFloat))
+	r.frames++
+	if r.opt.FrameDump != nil {
+		if err := r.opt.FrameDump.dump(r, r.frames); err != nil {
+			return fmt.Errorf("ring: could not dump frame: %w", err)
+		}
 	}
 
-	if err := r.device.Render(); err != nil {
-		return err
+	return nil
+}
+
+// RenderTo renders the ring into a frame of colors with the shape 0x00RRGGBB,
+// one per LED, instead of the device, so the frames can be output elsewhere,
+// such as another process or machine. The frame goes through the same
+// pipeline as Render, except for Dithering, which needs the frames to be
+// displayed. RenderTo always renders, and does not change the frame shown by
+// the device or Snapshot.
+func (r *Ring) RenderTo(dst []uint32) error {
+	if len(dst) != r.Size() {
+		return fmt.Errorf("ring: frame has %d LEDs, want %d", len(dst), r.Size())
 	}
 
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	r.mu.Lock()
+	state := r.state()
+	r.mu.Unlock()
+
+	r.composeFrame(dst, state, nil, nil)
+	limitCurrent(dst, r.opt.MaxCurrentMilliamps)
+
 	return nil
 }
 
-func lerp(i int, pixels []color.Color, alpha float64) color.Color {
-	return blendLerp(pixels[mod(i, len(pixels))], pixels[mod(i+1, len(pixels))], alpha)
+// frameState is the state of a ring that a frame is rendered from.
+type frameState struct {
+	layers         []Pixeler
+	offset         float64
+	clamp          bool
+	minBri, maxBri int
+	tint           color.RGBA64
+	tintAmount     float64
+	filter         Filter
+}
+
+// state returns the current state to render a frame from. It must be called
+// with r.mu held.
+func (r *Ring) state() frameState {
+	layers := r.layers
+	if o := r.notices.overlay(); o != nil {
+		layers = append(layers[:len(layers):len(layers)], o)
+	}
+
+	minBri := r.minBri
+	if r.maxBri == 0 {
+		// A brightness of 0 turns the LEDs off.
+		minBri = 0
+	}
+
+	return frameState{
+		layers:     layers,
+		offset:     r.offset + r.rotOffset,
+		clamp:      r.clamp,
+		minBri:     minBri,
+		maxBri:     r.maxBri,
+		tint:       r.tint,
+		tintAmount: r.tintAmount,
+		filter:     r.filter,
+	}
+}
+
+// composeFrame blends the layers of a state into a frame of dst, with a color
+// per LED, and stores the colors before the corrections of the LEDs in shown,
+// if not nil. The colors are dithered with the quantization errors in
+// ditherErr, if not nil, and it reports whether the frame needs dithering. It
+// must be called with r.renderMu held.
+func (r *Ring) composeFrame(dst []uint32, s frameState, shown []color.RGBA64, ditherErr []uint16) (dithered bool) {
+	r.blendPixels(s)
+	rotInt := math.Floor(s.offset)
+	rotFloat := s.offset - rotInt
+	for i := range dst {
+		c := lerp(int(rotInt)+i, r.pixels, rotFloat, s.clamp, r.opt.LinearBlending)
+		led := i
+		if r.pixelMap != nil {
+			led = r.pixelMap[i]
+		}
+		if s.tintAmount > 0 {
+			c = blendLerp(c, s.tint, s.tintAmount)
+		}
+		if s.filter != nil {
+			c = s.filter(led, c)
+		}
+		if shown != nil {
+			shown[led] = c
+		}
+		if r.gamma != nil {
+			c = applyGamma(c, r.gamma)
+		}
+		c = scaleBrightness(c, s.minBri, s.maxBri)
+		if led < len(r.opt.Calibration) {
+			c = calibrate(c, r.opt.Calibration[led])
+		}
+		if ditherErr == nil {
+			dst[led] = serialize64(c)
+			continue
+		}
+		dst[led] = dither(c, ditherErr[3*led:3*led+3])
+		dithered = dithered || (c.R|c.G|c.B)&0xFF != 0
+	}
+
+	return dithered
+}
+
+// checkDirty reports whether the ring or any of its layers changed since the
+// last call, and records the current state as rendered. It must be called with
+// r.mu held.
+func (r *Ring) checkDirty() bool {
+	dirty := r.dirty || r.dithered
+	r.dirty = false
+
+	if len(r.versions) != len(r.layers) {
+		r.versions = make([]uint64, len(r.layers))
+		dirty = true
+	}
+	for i, l := range r.layers {
+		v, ok := l.(versioner)
+		if !ok {
+			dirty = true
+			continue
+		}
+		if ver := v.version(); ver != r.versions[i] {
+			r.versions[i] = ver
+			dirty = true
+		}
+	}
+
+	return dirty
+}
+
+// composite blends the colors of all the layers at position i of a ring of a
+// given size. Layers are blended in linear light if linear is true or if their
+// options require it. Layers such as Trails replace the colors beneath them.
+func composite(layers []Pixeler, i, size int, linear bool) (blend color.RGBA64) {
+	for _, l := range layers {
+		if t, ok := l.(trailer); ok {
+			blend = t.trail(i, blend)
+			continue
+		}
+		var c color.RGBA64
+		switch l.Options().ContentMode {
+		case ContentTile:
+			c = layerPixel(l, i)
+		case ContentCrop:
+			if i < l.Options().Resolution {
+				c = layerPixel(l, i)
+			}
+		case ContentScale:
+			c = layerPixel(l, scale(i, size, l.Options().Resolution))
+		}
+
+		if linear || l.Options().LinearBlending {
+			blend = fromLinear(blendOver(toLinear(blend), toLinear(c)))
+		} else {
+			blend = blendOver(blend, c)
+		}
+	}
+
+	return blend
+}
+
+// layerPixel returns the color of the pixel at position i of layer l.
+func layerPixel(l Pixeler, i int) color.RGBA64 {
+	if p, ok := l.(pixeler64); ok {
+		return p.pixel64(i)
+	}
+
+	return toRGBA64(l.Pixel(i))
 }
 
-// AddLayer adds a drawable layer to the ring.
+// lerp returns the color between the pixels at position i and i+1. Positions
+// outside of pixels wrap around, or are clamped to the ends if clamp is true.
+func lerp(i int, pixels []color.RGBA64, alpha float64, clamp, linear bool) color.RGBA64 {
+	index := func(i int) int {
+		if !clamp {
+			return mod(i, len(pixels))
+		}
+		if i < 0 {
+			return 0
+		}
+		if i >= len(pixels) {
+			return len(pixels) - 1
+		}
+		return i
+	}
+
+	if linear {
+		return fromLinear(blendLerp(toLinear(pixels[index(i)]), toLinear(pixels[index(i+1)]), alpha))
+	}
+	return blendLerp(pixels[index(i)], pixels[index(i+1)], alpha)
+}
+
+// Attacher is implemented by layers that need to know when they are added to
+// a ring, such as to start their internal state or subscribe to the clock of
+// the ring.
+type Attacher interface {
+	// OnAttach is called after the layer is added to a ring.
+	OnAttach(r *Ring)
+}
+
+// Detacher is implemented by layers that need to know when they are removed
+// from a ring, such as to stop their internal state or release resources.
+type Detacher interface {
+	// OnDetach is called after the layer is removed from a ring, or when the
+	// ring is closed.
+	OnDetach(r *Ring)
+}
+
+// AddLayer adds a drawable layer to the ring. If the layer is an Attacher, it
+// is told so.
 func (r *Ring) AddLayer(l Pixeler) {
+	r.mu.Lock()
 	r.layers = append(r.layers, l)
+	r.dirty = true
+	r.mu.Unlock()
+
+	if a, ok := l.(Attacher); ok {
+		a.OnAttach(r)
+	}
+}
+
+// RemoveLayer removes a layer from the ring. It does nothing if the layer is
+// not in the ring. If the layer is a Detacher, it is told so.
+func (r *Ring) RemoveLayer(l Pixeler) {
+	r.mu.Lock()
+	removed := false
+	for i, other := range r.layers {
+		if other == l {
+			r.layers = append(r.layers[:i:i], r.layers[i+1:]...)
+			r.dirty = true
+			removed = true
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if d, ok := l.(Detacher); ok && removed {
+		d.OnDetach(r)
+	}
+}
+
+// Layers returns the layers of the ring, from the bottom to the top.
+func (r *Ring) Layers() []Pixeler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Pixeler(nil), r.layers...)
 }
 
-// Close turns off the LED ring and closes the device.
+// Layer returns the topmost layer of the ring with a name (see
+// LayerOptions.Name), or nil if there is none. Layers without a name cannot be
+// found, so an empty name returns nil.
+func (r *Ring) Layer(name string) Pixeler {
+	if name == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.layers) - 1; i >= 0; i-- {
+		if opt := r.layers[i].Options(); opt != nil && opt.Name == name {
+			return r.layers[i]
+		}
+	}
+
+	return nil
+}
+
+// Close plays the shutdown animation of the ring, if any, turns off the LED
+// ring, detaches its layers (see Detacher) and closes the device.
 func (r *Ring) Close() {
+	r.playPowerAnimation(r.opt.ShutdownAnimation)
 	r.TurnOff()
-	r.device.Fini()
+	for _, l := range r.Layers() {
+		if d, ok := l.(Detacher); ok {
+			d.OnDetach(r)
+		}
+	}
+	r.device.Close()
 }
 
 // TurnOff tuns off the LED ring without closing the device.
 func (r *Ring) TurnOff() {
-	for i := range r.device.Leds(0) {
-		r.device.Leds(0)[i] = 0
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	r.device.Render(make([]uint32, r.Size()))
+	for i := range r.shown {
+		r.shown[i] = color.RGBA64{}
 	}
-	r.device.Render()
+
+	r.mu.Lock()
+	r.dirty = true
+	r.mu.Unlock()
+}
+
+// Snapshot returns the colors of the LEDs in the last rendered frame, by
+// physical position, after blending the layers, offset, tint and filter, and
+// before the corrections of the LEDs, such as brightness and gamma. Frames
+// shown by Play have no corrections, so their colors are returned as
+// recorded.
+func (r *Ring) Snapshot() []color.Color {
+	r.renderMu.Lock()
+	defer r.renderMu.Unlock()
+
+	colors := make([]color.Color, len(r.shown))
+	for i, c := range r.shown {
+		colors[i] = c
+	}
+
+	return colors
 }
 
 // Size returns the total number of LEDs of the ring.
@@ -146,12 +682,58 @@ func (r *Ring) Size() int {
 // Offset sets an angular offset (in radians) to render the layers.
 // A positive angle rotates counter-clockwise.
 func (r *Ring) Offset(rotation float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if rotation < 0 {
 		r.ledOffset = int(math.Ceil(rotation / r.ledArc))
 	} else {
 		r.ledOffset = int(math.Floor(rotation / r.ledArc))
 	}
 	r.offset = rotation / r.ledArc
+	r.dirty = true
+}
+
+// OffsetDegrees is like Offset, with the angle in degrees.
+func (r *Ring) OffsetDegrees(rotation float64) {
+	r.Offset(radians(rotation))
+}
+
+// SetBrightness sets the maximum output of the LEDs, from MinBrightness to 255,
+// without reinitializing the device. It takes effect on the next render. A
+// level of 0 turns the LEDs off, and other levels below MinBrightness are
+// raised to it.
+func (r *Ring) SetBrightness(level int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case level <= 0:
+		level = 0
+	case level < r.minBri:
+		level = r.minBri
+	case level > 255:
+		level = 255
+	}
+	r.maxBri = level
+	r.dirty = true
+}
+
+// Brightness returns the maximum output of the LEDs.
+func (r *Ring) Brightness() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.maxBri
+}
+
+// EstimatedCurrent returns the approximate current, in milliamps, drawn by the
+// LEDs to display the last rendered frame.
+func (r *Ring) EstimatedCurrent() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
 }
 
 func scale(v, fmax, tmax int) int {
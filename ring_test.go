@@ -0,0 +1,1078 @@
+package ring
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeDevice struct {
+	leds      []uint32
+	renders   int
+	renderErr error
+	closes    int
+}
+
+func (d *fakeDevice) Render(frame []uint32) error {
+	copy(d.leds, frame)
+	d.renders++
+	return d.renderErr
+}
+
+func (d *fakeDevice) Close() { d.closes++ }
+
+func newTestRing(t *testing.T, n int) (*Ring, *fakeDevice) {
+	t.Helper()
+	dev := &fakeDevice{leds: make([]uint32, n)}
+	return newRing(dev, &Options{LedCount: n, MaxBrightness: 255}), dev
+}
+
+func newTestLayer(t *testing.T, options *LayerOptions) *Layer {
+	t.Helper()
+	l, err := NewLayer(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestRenderAllocs(t *testing.T) {
+	r, _ := newTestRing(t, 24)
+	bg := newTestLayer(t, &LayerOptions{Resolution: 1, ContentMode: ContentScale})
+	bg.SetAll(color.White)
+	fg := newTestLayer(t, &LayerOptions{Resolution: 24})
+	fg.SetPixel(3, color.NRGBA{0xFF, 0x00, 0x00, 0x80})
+	r.AddLayer(bg)
+	r.AddLayer(fg)
+	r.Offset(0.1)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Offset(0.2)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got: %v allocations per render, want: 0", allocs)
+	}
+}
+
+// frameDevice keeps the last frame rendered, without copying it.
+type frameDevice struct {
+	frame []uint32
+}
+
+func (d *frameDevice) Render(frame []uint32) error {
+	d.frame = frame
+	return nil
+}
+
+func (d *frameDevice) Close() {}
+
+// probeLayer calls probe on every pixel read.
+type probeLayer struct {
+	opt   *LayerOptions
+	c     color.Color
+	probe func()
+}
+
+func (l *probeLayer) Pixel(int) color.Color {
+	l.probe()
+	return l.c
+}
+
+func (l *probeLayer) Options() *LayerOptions {
+	return l.opt
+}
+
+func TestRenderDoubleBuffer(t *testing.T) {
+	dev := &frameDevice{}
+	r := newRing(dev, &Options{LedCount: 4, MaxBrightness: 255})
+	var want uint32
+	l := &probeLayer{opt: &LayerOptions{Resolution: 4}, probe: func() {
+		// The displayed frame must not change while the next one is
+		// composited.
+		for i, got := range dev.frame {
+			if got != want {
+				t.Fatalf("displayed LED %d got: %#x, want: %#x", i, got, want)
+			}
+		}
+	}}
+	r.AddLayer(l)
+
+	for _, ts := range []struct {
+		c    color.Color
+		want uint32
+	}{
+		{color.RGBA{0xFF, 0x00, 0x00, 0xFF}, 0xFF0000},
+		{color.RGBA{0x00, 0x00, 0xFF, 0xFF}, 0x0000FF},
+		{color.RGBA{0x00, 0xFF, 0x00, 0xFF}, 0x00FF00},
+	} {
+		l.c = ts.c
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		want = ts.want
+		for i, got := range dev.frame {
+			if got != ts.want {
+				t.Errorf("LED %d got: %#x, want: %#x", i, got, ts.want)
+			}
+		}
+	}
+}
+
+func TestRenderSkipsUnchanged(t *testing.T) {
+	r, dev := newTestRing(t, 12)
+	l := newTestLayer(t, &LayerOptions{Resolution: 12})
+	r.AddLayer(l)
+
+	render := func() {
+		t.Helper()
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	render()
+	render()
+	if dev.renders != 1 {
+		t.Errorf("got: %d renders, want: 1", dev.renders)
+	}
+
+	l.SetPixel(0, color.White)
+	render()
+	if dev.renders != 2 {
+		t.Errorf("got: %d renders, want: 2", dev.renders)
+	}
+	if got, want := dev.leds[0], uint32(0xFFFFFF); got != want {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+}
+
+func TestStripScroll(t *testing.T) {
+	r, dev := newTestRing(t, 4)
+	s := &Strip{ring: r}
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.SetPixel(0, color.RGBA{0x01, 0x00, 0x00, 0xFF})
+	l.SetPixel(3, color.RGBA{0x04, 0x00, 0x00, 0xFF})
+	s.AddLayer(l)
+
+	tests := []struct {
+		name   string
+		mode   ScrollMode
+		pixels float64
+		want   []uint32
+	}{
+		{"clamp", ScrollClamp, 2, []uint32{0x000000, 0x040000, 0x040000, 0x040000}},
+		{"clamp back", ScrollClamp, -2, []uint32{0x010000, 0x010000, 0x010000, 0x000000}},
+		{"wrap", ScrollWrap, 2, []uint32{0x000000, 0x040000, 0x010000, 0x000000}},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			s.SetScrollMode(ts.mode)
+			s.Scroll(ts.pixels)
+			if err := s.Render(); err != nil {
+				t.Fatal(err)
+			}
+			for i := range ts.want {
+				if dev.leds[i] != ts.want[i] {
+					t.Errorf("led %d got: %#x, want: %#x", i, dev.leds[i], ts.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSegment(t *testing.T) {
+	r, dev := newTestRing(t, 12)
+
+	tests := []struct {
+		name       string
+		start, end float64
+		want       []uint32
+	}{
+		{
+			"second quadrant",
+			math.Pi / 2, math.Pi,
+			[]uint32{0, 0, 0, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			"across the first LED",
+			-math.Pi / 6, math.Pi / 6,
+			[]uint32{0xFFFFFF, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xFFFFFF},
+		},
+		{
+			"wrapped end",
+			math.Pi / 2, math.Pi - 4*math.Pi,
+			[]uint32{0, 0, 0, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			"huge end",
+			0, -1e20,
+			nil,
+		},
+		{
+			"more than a turn",
+			math.Pi / 2, 5 * math.Pi,
+			[]uint32{0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF, 0xFFFFFF},
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			s, err := r.Segment(ts.start, ts.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.SetAll(color.White)
+			r.layers = []Pixeler{s}
+			r.dirty = true
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+			for i := range ts.want {
+				if dev.leds[i] != ts.want[i] {
+					t.Errorf("led %d got: %#x, want: %#x", i, dev.leds[i], ts.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentError(t *testing.T) {
+	r, _ := newTestRing(t, 12)
+
+	for _, angle := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := r.Segment(0, angle); err == nil {
+			t.Errorf("end %v got: nil, want: error", angle)
+		}
+		if _, err := r.Segment(angle, 0); err == nil {
+			t.Errorf("start %v got: nil, want: error", angle)
+		}
+	}
+}
+
+func TestPixelMap(t *testing.T) {
+	dev := &fakeDevice{leds: make([]uint32, 4)}
+	r := newRing(dev, &Options{LedCount: 4, MaxBrightness: 255, PixelMap: []int{3, 2, 1, 0}})
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.SetPixel(0, color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uint32{0, 0, 0, 0xFFFFFF}
+	for i := range want {
+		if dev.leds[i] != want[i] {
+			t.Errorf("led %d got: %#x, want: %#x", i, dev.leds[i], want[i])
+		}
+	}
+}
+
+func TestNewImageLayer(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	blue := color.RGBA{0x00, 0x00, 0xFF, 0xFF}
+
+	circle := image.NewRGBA(image.Rect(0, 0, 9, 9))
+	circle.Set(4, 0, red)
+	circle.Set(8, 4, blue)
+
+	strip := image.NewRGBA(image.Rect(0, 0, 8, 1))
+	strip.Set(1, 0, red)
+	strip.Set(7, 0, blue)
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want map[int]color.RGBA
+	}{
+		{"circle", circle, map[int]color.RGBA{0: red, 1: blue, 2: {}, 3: {}}},
+		{"strip", strip, map[int]color.RGBA{0: red, 1: {}, 2: {}, 3: blue}},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			l, err := NewImageLayer(ts.img, &LayerOptions{Resolution: 4})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range ts.want {
+				if got := color.RGBAModel.Convert(l.Pixel(i)); got != want {
+					t.Errorf("pixel %d got: %v, want: %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLayerDraw(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 6})
+	draw.Draw(l, image.Rect(2, 0, 4, 1), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for i := 0; i < 6; i++ {
+		want := color.RGBA{}
+		if i == 2 || i == 3 {
+			want = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+		}
+		if got := color.RGBAModel.Convert(l.At(i, 0)); got != want {
+			t.Errorf("pixel %d got: %v, want: %v", i, got, want)
+		}
+	}
+
+	// The transformed pixels are updated once they are read, after all the
+	// pixels of a draw are set.
+	for i := 0; i < 6; i++ {
+		if _, _, _, a := l.Pixel(i).RGBA(); (a > 0) != (i == 2 || i == 3) {
+			t.Errorf("pixel %d got: alpha %#x after draw", i, a)
+		}
+	}
+	l.Set(5, 0, color.White)
+	if !l.stale {
+		t.Errorf("got: buffer updated by Set, want: updated once read")
+	}
+	if _, _, _, a := l.Pixel(5).RGBA(); a == 0 {
+		t.Errorf("pixel 5 got: transparent, want: white")
+	}
+}
+
+func TestWrapIndices(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 4, WrapIndices: true})
+	l.SetPixel(-1, color.White)
+	l.SetPixel(5, color.White)
+
+	for i, want := range []bool{false, true, false, true} {
+		if got := l.pixelRaw(i) != (color.RGBA64{}); got != want {
+			t.Errorf("pixel %d set got: %v, want: %v", i, got, want)
+		}
+	}
+}
+
+func TestSetRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      func(l *Layer)
+		want     []bool
+		wantVers uint64
+	}{
+		{
+			"range",
+			func(l *Layer) { l.SetRange(1, 3, color.White) },
+			[]bool{false, true, true, false},
+			1,
+		},
+		{
+			"wrapped range",
+			func(l *Layer) { l.SetRange(3, 5, color.White) },
+			[]bool{true, false, false, true},
+			1,
+		},
+		{
+			"pixels",
+			func(l *Layer) { l.SetPixels(map[int]color.Color{0: color.White, -2: color.White}) },
+			[]bool{true, false, true, false},
+			1,
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			l := newTestLayer(t, &LayerOptions{Resolution: 4, WrapIndices: true})
+			ver := l.version()
+			ts.set(l)
+			if got := l.version() - ver; got != ts.wantVers {
+				t.Errorf("updates got: %d, want: %d", got, ts.wantVers)
+			}
+			for i, want := range ts.want {
+				if got := l.pixelRaw(i) != (color.RGBA64{}); got != want {
+					t.Errorf("pixel %d set got: %v, want: %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetRangeOutOfRange(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	ver := l.version()
+
+	for name, err := range map[string]error{
+		"range":  l.SetRange(2, 5, color.White),
+		"before": l.SetRange(-1, 2, color.White),
+		"pixels": l.SetPixels(map[int]color.Color{0: color.White, 4: color.White}),
+	} {
+		if !errors.Is(err, ErrPixelOutOfRange) {
+			t.Errorf("%s got: %v, want: %v", name, err, ErrPixelOutOfRange)
+		}
+	}
+	if got := l.version() - ver; got != 0 {
+		t.Errorf("got: %d updates, want: layer unchanged", got)
+	}
+}
+
+func TestShift(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.SetPixel(0, color.White)
+
+	for _, ts := range []struct {
+		n    int
+		want int
+	}{
+		{1, 1},
+		{2, 3},
+		{-5, 2},
+	} {
+		l.Shift(ts.n)
+		for i := 0; i < 4; i++ {
+			if got := l.pixel64(i) != (color.RGBA64{}); got != (i == ts.want) {
+				t.Errorf("shift %d: pixel %d lit got: %v, want: %v", ts.n, i, got, i == ts.want)
+			}
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	lit := func(l *Layer, pixel func(int) color.RGBA64) []bool {
+		got := make([]bool, l.opt.Resolution)
+		for i := range got {
+			got[i] = pixel(i) != (color.RGBA64{})
+		}
+		return got
+	}
+
+	l := newTestLayer(t, &LayerOptions{Resolution: 6})
+	l.SetPixel(1, color.White)
+	l.SetPixel(2, color.White)
+	l.Mirror()
+	if got, want := lit(l, l.pixelRaw), []bool{false, true, true, false, true, true}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("mirror got: %v, want: %v", got, want)
+	}
+
+	r := newTestLayer(t, &LayerOptions{Resolution: 6, Reverse: true})
+	r.SetPixel(1, color.White)
+	if got, want := lit(r, r.pixel64), []bool{false, false, false, false, false, true}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("reverse got: %v, want: %v", got, want)
+	}
+}
+
+func TestBlur(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 8, Blur: 2})
+	l.SetPixel(0, color.White)
+
+	center, near, far := l.pixel64(0).R, l.pixel64(1).R, l.pixel64(4).R
+	if !(center > near && near > 0 && far == 0) {
+		t.Errorf("got: center %#x, near %#x, far %#x, want: center > near > far = 0", center, near, far)
+	}
+	if l.pixel64(1) != l.pixel64(-1) {
+		t.Errorf("got: %v, want: symmetric %v", l.pixel64(1), l.pixel64(-1))
+	}
+
+	sum := 0
+	for i := 0; i < 8; i++ {
+		sum += int(l.pixel64(i).A)
+	}
+	if sum < 0xFFFF-8 || sum > 0xFFFF+8 {
+		t.Errorf("total alpha got: %#x, want: %#x", sum, 0xFFFF)
+	}
+}
+
+func TestDrawAt(t *testing.T) {
+	tests := []struct {
+		name string
+		draw func(l *Layer)
+		want []uint16
+	}{
+		{
+			"on pixel",
+			func(l *Layer) { l.DrawAtDegrees(90, color.White) },
+			[]uint16{0, 0xFFFF, 0, 0},
+		},
+		{
+			"between pixels",
+			func(l *Layer) { l.DrawAtDegrees(-45, color.White) },
+			[]uint16{0x7FFF, 0, 0, 0x7FFF},
+		},
+		{
+			"arc",
+			func(l *Layer) { l.DrawArcDegrees(45, 225, color.White) },
+			[]uint16{0, 0xFFFF, 0xFFFF, 0},
+		},
+		{
+			"wrapped arc",
+			func(l *Layer) { l.DrawArcDegrees(315-22.5, 22.5, color.White) },
+			[]uint16{0xBFFF, 0, 0, 0x3FFF},
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			l := newTestLayer(t, &LayerOptions{Resolution: 4})
+			ts.draw(l)
+			for i, want := range ts.want {
+				if got := int(l.pixelRaw(i).A); got < int(want)-1 || got > int(want)+1 {
+					t.Errorf("pixel %d got: %#x, want: %#x", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDegrees(t *testing.T) {
+	tests := []struct {
+		name    string
+		radians func(r *Ring, l *Layer, angle float64)
+		degrees func(r *Ring, l *Layer, angle float64)
+	}{
+		{
+			"rotate",
+			func(r *Ring, l *Layer, a float64) { l.Rotate(a) },
+			func(r *Ring, l *Layer, a float64) { l.RotateDegrees(a) },
+		},
+		{
+			"offset",
+			func(r *Ring, l *Layer, a float64) { r.Offset(a) },
+			func(r *Ring, l *Layer, a float64) { r.OffsetDegrees(a) },
+		},
+		{
+			"set pixel",
+			func(r *Ring, l *Layer, a float64) { l.SetPixelAt(a, color.White) },
+			func(r *Ring, l *Layer, a float64) { l.SetPixelAtDegrees(a, color.White) },
+		},
+		{
+			"draw",
+			func(r *Ring, l *Layer, a float64) { l.DrawAt(a, color.White) },
+			func(r *Ring, l *Layer, a float64) { l.DrawAtDegrees(a, color.White) },
+		},
+		{
+			"arc",
+			func(r *Ring, l *Layer, a float64) { l.DrawArc(a, a+math.Pi/2, color.White) },
+			func(r *Ring, l *Layer, a float64) { l.DrawArcDegrees(a, a+90, color.White) },
+		},
+	}
+
+	render := func(t *testing.T, do func(r *Ring, l *Layer, angle float64), angle float64) []uint32 {
+		r, dev := newTestRing(t, 8)
+		l := newTestLayer(t, &LayerOptions{Resolution: 8})
+		l.SetPixel(0, color.RGBA{0x80, 0x00, 0x00, 0xFF})
+		r.AddLayer(l)
+		do(r, l, angle)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		return append([]uint32(nil), dev.leds...)
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			for _, deg := range []float64{0, 45, 90, -135, 202.5, 720} {
+				want := render(t, ts.radians, deg*math.Pi/180)
+				got := render(t, ts.degrees, deg)
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Errorf("%v degrees got: %#x, want: %#x", deg, got, want)
+				}
+			}
+			if fmt.Sprint(render(t, ts.degrees, 90)) == fmt.Sprint(render(t, ts.degrees, 0)) {
+				t.Errorf("got: same LEDs at 0 and 90 degrees, want: moved")
+			}
+		})
+	}
+}
+
+func TestSpin(t *testing.T) {
+	tests := []struct {
+		name     string
+		velocity float64
+		dt       time.Duration
+		want     int // lit LED
+	}{
+		{"still", 0, time.Second, 0},
+		{"quarter turn", math.Pi, 500 * time.Millisecond, 9},
+		{"full turn", math.Pi, 2 * time.Second, 0},
+		{"backwards", -math.Pi, 500 * time.Millisecond, 3},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			r, dev := newTestRing(t, 12)
+			l := newTestLayer(t, &LayerOptions{Resolution: 12})
+			l.SetPixel(0, color.White)
+			l.Spin(ts.velocity)
+			r.AddLayer(l)
+			r.advance(ts.dt)
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range dev.leds {
+				if got, want := c != 0, i == ts.want; got != want {
+					t.Errorf("LED %d got: lit %v, want: %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	dev := &fakeDevice{leds: make([]uint32, 3)}
+	r := newRing(dev, &Options{
+		LedCount:      3,
+		MaxBrightness: 255,
+		Calibration:   []ColorScale{{1, 1, 1}, {1, 0.5, 0.8}},
+	})
+	l := newTestLayer(t, &LayerOptions{Resolution: 1, ContentMode: ContentScale})
+	l.SetAll(color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uint32{0xFFFFFF, 0xFF7FCC, 0xFFFFFF}
+	for i := range want {
+		if dev.leds[i] != want[i] {
+			t.Errorf("led %d got: %#x, want: %#x", i, dev.leds[i], want[i])
+		}
+	}
+}
+
+func TestGamma(t *testing.T) {
+	var table GammaTable
+	for i := range table[0] {
+		table[0][i], table[1][i], table[2][i] = uint8(i), 0, 0xFF
+	}
+
+	for _, ts := range []struct {
+		name string
+		opt  Options
+		want uint32
+	}{
+		{"none", Options{}, 0x808080},
+		{"uniform", Options{Gamma: UniformGamma(2)}, 0x404040},
+		{"green", Options{Gamma: Gamma{G: 2}}, 0x804080},
+		{"table", Options{Gamma: UniformGamma(2), GammaTable: &table}, 0x8000FF},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			dev := &fakeDevice{leds: make([]uint32, 1)}
+			ts.opt.LedCount, ts.opt.MaxBrightness = 1, 255
+			r := newRing(dev, &ts.opt)
+			l := newTestLayer(t, &LayerOptions{Resolution: 1})
+			l.SetAll(color.RGBA{0x80, 0x80, 0x80, 0xFF})
+			r.AddLayer(l)
+			if err := r.Render(); err != nil {
+				t.Fatal(err)
+			}
+			if got := dev.leds[0]; got != ts.want {
+				t.Errorf("got: %#x, want: %#x", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestBrightness(t *testing.T) {
+	dev := &fakeDevice{leds: make([]uint32, 1)}
+	r := newRing(dev, &Options{LedCount: 1, MinBrightness: 10, MaxBrightness: 255})
+	l := newTestLayer(t, &LayerOptions{Resolution: 1})
+	l.SetAll(color.White)
+	r.AddLayer(l)
+
+	tests := []struct {
+		level int
+		want  int    // brightness
+		led   uint32 // output of a white LED
+	}{
+		{128, 128, 0x808080},
+		{0, 0, 0x000000},
+		{5, 10, 0x0A0A0A},
+		{-1, 0, 0x000000},
+		{300, 255, 0xFFFFFF},
+	}
+
+	for _, ts := range tests {
+		r.SetBrightness(ts.level)
+		if got := r.Brightness(); got != ts.want {
+			t.Errorf("level %d got: brightness %d, want: %d", ts.level, got, ts.want)
+		}
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		if got := dev.leds[0]; got != ts.led {
+			t.Errorf("level %d got: %#x, want: %#x", ts.level, got, ts.led)
+		}
+	}
+}
+
+func TestLinearBlending(t *testing.T) {
+	black := newTestLayer(t, &LayerOptions{Resolution: 1})
+	black.SetAll(color.Black)
+
+	tests := []struct {
+		name   string
+		linear bool
+		want   uint32
+	}{
+		{"sRGB", false, 0x808080},
+		{"linear", true, 0xBCBCBC},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			white := newTestLayer(t, &LayerOptions{Resolution: 1, LinearBlending: ts.linear})
+			white.SetAll(color.NRGBA{0xFF, 0xFF, 0xFF, 0x80})
+			got := serialize64(composite([]Pixeler{black, white}, 0, 1, false))
+			if got != ts.want {
+				t.Errorf("got: %#x, want: %#x", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestTintFilter(t *testing.T) {
+	r, dev := newTestRing(t, 2)
+	l := newTestLayer(t, &LayerOptions{Resolution: 2})
+	l.SetPixel(0, color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+	r.AddLayer(l)
+
+	r.SetTint(color.White, 0.5)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.leds, []uint32{0xFF7F7F, 0x7F7F7F}; got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("tint got: %#x, want: %#x", got, want)
+	}
+
+	r.SetTint(color.Black, 0)
+	r.SetFilter(func(led int, c color.RGBA64) color.RGBA64 {
+		if led == 1 {
+			return color.RGBA64{0, 0, 0xFFFF, 0xFFFF}
+		}
+		return color.RGBA64{c.G, c.R, c.B, c.A}
+	})
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.leds, []uint32{0x00FF00, 0x0000FF}; got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("filter got: %#x, want: %#x", got, want)
+	}
+}
+
+func TestLayerByName(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	bg := newTestLayer(t, &LayerOptions{Name: "bg", Resolution: 4})
+	fg := newTestLayer(t, &LayerOptions{Name: "fg", Resolution: 4})
+	r.AddLayer(bg)
+	r.AddLayer(fg)
+
+	if got := r.Layer("bg"); got != Pixeler(bg) {
+		t.Errorf("got: %v, want: %v", got, bg)
+	}
+	if got := r.Layer("none"); got != nil {
+		t.Errorf("got: %v, want: nil", got)
+	}
+	r.RemoveLayer(bg)
+	if got := r.Layers(); len(got) != 1 || got[0] != Pixeler(fg) {
+		t.Errorf("got: %v, want: [fg]", got)
+	}
+	r.AddLayer(newTestLayer(t, &LayerOptions{Resolution: 4}))
+	if got := r.Layer(""); got != nil {
+		t.Errorf("got: %v for no name, want: nil", got)
+	}
+}
+
+func TestPause(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.Spin(1)
+	r.AddLayer(l)
+	s := NewSprite(0, color.White)
+	s.SetVelocity(1)
+	r.Animator().Play(s)
+
+	r.Pause()
+	r.advance(time.Second)
+	if l.angle != 0 || s.Position() != 0 {
+		t.Errorf("paused ring got: layer %v, sprite %v, want: 0, 0", l.angle, s.Position())
+	}
+
+	r.Resume()
+	r.Animator().Pause()
+	r.advance(time.Second)
+	if l.angle != 1 || s.Position() != 0 {
+		t.Errorf("paused animator got: layer %v, sprite %v, want: 1, 0", l.angle, s.Position())
+	}
+
+	r.Animator().Resume()
+	r.advance(time.Second)
+	if math.Abs(s.Position()-1) > 1e-9 {
+		t.Errorf("resumed got: sprite %v, want: 1", s.Position())
+	}
+}
+
+func TestTimeScale(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.Spin(1)
+	r.AddLayer(l)
+
+	if err := r.SetTimeScale(0.5); err != nil {
+		t.Fatal(err)
+	}
+	for _, factor := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if err := r.SetTimeScale(factor); err == nil {
+			t.Errorf("%v got: nil, want: error", factor)
+		}
+	}
+	r.advance(time.Second)
+	if math.Abs(l.angle-0.5) > 1e-9 {
+		t.Errorf("got: %v, want: %v", l.angle, 0.5)
+	}
+}
+
+// stepRecorder is an animation that records the steps it is advanced by.
+type stepRecorder struct {
+	steps []time.Duration
+}
+
+func (s *stepRecorder) Tick(dt time.Duration) bool {
+	s.steps = append(s.steps, dt)
+	return false
+}
+
+func TestTimestep(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	rec := &stepRecorder{}
+	r.Animator().Play(rec)
+	r.SetTimestep(10 * time.Millisecond)
+
+	tests := []struct {
+		dt    time.Duration
+		steps int
+		total time.Duration
+	}{
+		{25 * time.Millisecond, 2, 20 * time.Millisecond},
+		{25 * time.Millisecond, 3, 30 * time.Millisecond},
+		{5 * time.Millisecond, 0, 0},
+		{time.Second, maxSteps, time.Second},
+	}
+
+	for _, ts := range tests {
+		rec.steps = nil
+		r.tick(ts.dt)
+		var total time.Duration
+		for _, step := range rec.steps {
+			total += step
+		}
+		if len(rec.steps) != ts.steps || total != ts.total {
+			t.Errorf("%v got: %d steps of %v, want: %d steps of %v", ts.dt, len(rec.steps), total, ts.steps, ts.total)
+		}
+	}
+}
+
+// clockLayer is a layer that updates itself.
+type clockLayer struct {
+	*Layer
+	elapsed time.Duration
+}
+
+func (c *clockLayer) Update(t time.Duration) {
+	c.elapsed += t
+}
+
+func TestUpdater(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	c := &clockLayer{Layer: newTestLayer(t, &LayerOptions{Resolution: 4})}
+	c.Spin(1)
+	r.AddLayer(c)
+
+	r.advance(time.Second)
+	r.Pause()
+	r.advance(time.Second)
+	if c.elapsed != time.Second || c.angle != 1 {
+		t.Errorf("got: elapsed %v, angle %v, want: %v, 1", c.elapsed, c.angle, time.Second)
+	}
+}
+
+// hookLayer is a layer that records when it is attached and detached.
+type hookLayer struct {
+	*Layer
+	events []string
+}
+
+func (h *hookLayer) OnAttach(r *Ring) { h.events = append(h.events, "attach") }
+func (h *hookLayer) OnDetach(r *Ring) { h.events = append(h.events, "detach") }
+
+func TestLayerHooks(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	h := &hookLayer{Layer: newTestLayer(t, &LayerOptions{Resolution: 4})}
+
+	r.RemoveLayer(h)
+	r.AddLayer(h)
+	r.RemoveLayer(h)
+	r.AddEffect(h)
+	r.Close()
+
+	want := []string{"attach", "detach", "attach", "detach"}
+	if !reflect.DeepEqual(h.events, want) {
+		t.Errorf("got: %v, want: %v", h.events, want)
+	}
+}
+
+func TestFadeTo(t *testing.T) {
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.SetAll(color.Black)
+	l.FadeTo(color.White, time.Second)
+
+	l.advance(500 * time.Millisecond)
+	if got := l.pixel64(0).R; got < 0x7F00 || got > 0x8100 {
+		t.Errorf("got: %#x, want: %#x", got, 0x8000)
+	}
+
+	l.advance(600 * time.Millisecond)
+	if got, want := l.pixel64(3), toRGBA64(color.White); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+	if l.trans != nil {
+		t.Errorf("got: %#v, want: finished transition", l.trans)
+	}
+
+	l.FadeAllTo([]color.Color{color.Black}, 0)
+	if got, want := l.pixel64(0), toRGBA64(color.Black); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+	if got, want := l.pixel64(1), (color.RGBA64{}); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+}
+
+func TestPowerAnimation(t *testing.T) {
+	dev := &fakeDevice{leds: make([]uint32, 4)}
+	r, err := NewWithDevice(dev, &Options{
+		LedCount:          4,
+		MaxBrightness:     255,
+		StartupAnimation:  WipeIn(color.RGBA{0xFF, 0x00, 0x00, 0xFF}, 0),
+		ShutdownAnimation: FadeOut(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.leds, []uint32{0xFF0000, 0xFF0000, 0xFF0000, 0xFF0000}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+	if got := len(r.Layers()); got != 0 {
+		t.Errorf("got: %d layers after the startup animation, want: 0", got)
+	}
+
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	WipeIn(color.White, time.Second).Draw(l, 0.5)
+	for i, want := range []uint16{0xFFFF, 0xFFFF, 0, 0} {
+		if got := l.pixel64(i).R; got != want {
+			t.Errorf("pixel %d got: %#x, want: %#x", i, got, want)
+		}
+	}
+
+	renders := dev.renders
+	r.Close()
+	if got, want := dev.renders-renders, 2; got != want {
+		t.Errorf("got: %d renders on close, want: %d", got, want)
+	}
+}
+
+func TestPowerAnimationError(t *testing.T) {
+	renderErr := errors.New("bus error")
+	dev := &fakeDevice{leds: make([]uint32, 4), renderErr: renderErr}
+	_, err := NewWithDevice(dev, &Options{
+		LedCount:         4,
+		StartupAnimation: WipeIn(color.White, 0),
+	})
+	if !errors.Is(err, renderErr) {
+		t.Errorf("got: %v, want: %v", err, renderErr)
+	}
+	if got, want := dev.closes, 1; got != want {
+		t.Errorf("got: device closed %d times, want: %d", got, want)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dev := &fakeDevice{leds: make([]uint32, 3)}
+	r := newRing(dev, &Options{LedCount: 3, MaxBrightness: 64, PixelMap: []int{2, 1, 0}})
+	l := newTestLayer(t, &LayerOptions{Resolution: 3})
+	l.SetPixel(0, color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []color.RGBA64{{}, {}, toRGBA64(color.White)}
+	got := r.Snapshot()
+	for i := range want {
+		if toRGBA64(got[i]) != want[i] {
+			t.Errorf("led %d got: %#v, want: %#v", i, got[i], want[i])
+		}
+	}
+	if got, want := dev.leds[2], uint32(0x404040); got != want {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+
+	r.TurnOff()
+	if got := toRGBA64(r.Snapshot()[2]); got != (color.RGBA64{}) {
+		t.Errorf("got: %#v after turning off, want: %#v", got, color.RGBA64{})
+	}
+}
+
+func TestRingImage(t *testing.T) {
+	r, _ := newTestRing(t, 4)
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	l.SetPixel(0, color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+	l.SetPixel(1, color.NRGBA{0x00, 0x00, 0xFF, 0x80})
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := r.Bounds(), image.Rect(0, 0, 4, 1); got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if got, want := r.At(1, 0), (color.RGBA64{0, 0, 0x8080, 0xFFFF}); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+
+	img := r.CircleImage(40)
+	for _, ts := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{20, 20, color.RGBA{0, 0, 0, 0xFF}},    // center
+		{20, 3, color.RGBA{0xFF, 0, 0, 0xFF}},  // first LED, at the top
+		{36, 20, color.RGBA{0, 0, 0x80, 0xFF}}, // second LED, to the right
+		{3, 20, color.RGBA{0, 0, 0, 0xFF}},     // last LED, off
+	} {
+		if got := img.RGBAAt(ts.x, ts.y); got != ts.want {
+			t.Errorf("(%d, %d) got: %#v, want: %#v", ts.x, ts.y, got, ts.want)
+		}
+	}
+}
+
+func TestRenderTo(t *testing.T) {
+	r, dev := newTestRing(t, 3)
+	l := newTestLayer(t, &LayerOptions{Resolution: 3})
+	l.SetPixel(1, color.White)
+	r.AddLayer(l)
+
+	frame := make([]uint32, 3)
+	if err := r.RenderTo(frame); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := frame, []uint32{0, 0xFFFFFF, 0}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+	if dev.renders != 0 {
+		t.Errorf("got: %d device renders, want: 0", dev.renders)
+	}
+	if err := r.RenderTo(make([]uint32, 2)); err == nil {
+		t.Errorf("got: nil error, want: frame size error")
+	}
+}
@@ -0,0 +1,280 @@
+// Package ringsync keeps several rings showing the same effects in step, for
+// installations with rings driven by different computers. A leader ring
+// broadcasts its effects (see ring.Preset) and how far it has played them
+// over UDP, and follower rings show the same effects at the same point.
+//
+// On the computer of the leader:
+//
+//	conn, err := net.Dial("udp", "255.255.255.255:7778")
+//	...
+//	l := ringsync.NewLeader(r, conn)
+//	l.Play(preset)
+//	go l.Run(ctx, 100*time.Millisecond)
+//
+// On the computers of the followers:
+//
+//	log.Fatal(ringsync.ListenAndServe(":7778", r))
+//
+// Followers only need to run their rings (see ring.Ring.Run); they must not be
+// paused or time scaled, or they drift from the leader. Leaders and followers
+// tell the time with the clock of their rings (see ring.Ring.Clock).
+package ringsync
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Each state is sent as a datagram with a header of the magic byte, the
+// session of the leader (big-endian uint32), the version of the effects in the
+// session (big-endian uint32) and the time since the leader started playing
+// them in nanoseconds (big-endian int64), followed by the effects as a JSON
+// ring.Preset. The session is random for each leader, so followers reload the
+// effects when a leader restarts and counts versions from the start.
+const (
+	magic      = 'S'
+	headerSize = 17
+)
+
+// DefaultTolerance is how far a follower can drift from the leader before it
+// is corrected.
+const DefaultTolerance = 50 * time.Millisecond
+
+// Leader plays effects on a ring and broadcasts them to the followers.
+type Leader struct {
+	r       *ring.Ring
+	conn    net.Conn
+	session uint32 // random ID of the leader, sent in every state
+
+	mu      sync.Mutex
+	preset  *ring.Preset
+	data    []byte // preset as JSON
+	version uint32
+	effects []ring.Pixeler
+	start   time.Time // when the effects started playing
+}
+
+// NewLeader creates a leader of a ring that sends its state on conn, usually
+// a UDP connection to a broadcast address.
+func NewLeader(r *ring.Ring, conn net.Conn) *Leader {
+	return &Leader{
+		r:       r,
+		conn:    conn,
+		session: rand.New(rand.NewSource(r.Clock().Now().UnixNano())).Uint32(),
+	}
+}
+
+// Play replaces the effects played by the leader with the effects of a preset,
+// and sends them to the followers.
+func (l *Leader) Play(p *ring.Preset) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("ringsync: %w", err)
+	}
+	es, err := p.Effects(ring.DefaultRegistry, l.r.Size())
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	for _, e := range l.effects {
+		l.r.RemoveEffect(e)
+	}
+	for _, e := range es {
+		l.r.AddEffect(e)
+	}
+	l.preset = p
+	l.data = data
+	l.version++
+	l.effects = es
+	l.start = l.r.Clock().Now()
+	l.mu.Unlock()
+
+	return l.Send()
+}
+
+// Send sends the current state of the leader to the followers.
+func (l *Leader) Send() error {
+	l.mu.Lock()
+	if l.preset == nil {
+		l.mu.Unlock()
+		return nil
+	}
+	msg := encode(l.session, l.version, l.r.Clock().Now().Sub(l.start), l.data)
+	l.mu.Unlock()
+
+	if _, err := l.conn.Write(msg); err != nil {
+		return fmt.Errorf("ringsync: %w", err)
+	}
+
+	return nil
+}
+
+// Run sends the state of the leader to the followers every interval, so
+// followers that start late or miss datagrams catch up, until ctx is done. It
+// returns ctx.Err() once ctx is done, or the first error sending the state.
+func (l *Leader) Run(ctx context.Context, interval time.Duration) error {
+	ticker := l.r.Clock().NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if err := l.Send(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Follower shows on a ring the effects of a leader.
+type Follower struct {
+	r *ring.Ring
+
+	mu        sync.Mutex
+	tolerance time.Duration
+	session   uint32
+	version   uint32
+	preset    *ring.Preset
+	effects   []ring.Pixeler
+	start     time.Time // when the effects would have started playing
+}
+
+// NewFollower creates a follower that shows the effects of the leader on a
+// ring.
+func NewFollower(r *ring.Ring) *Follower {
+	return &Follower{
+		r:         r,
+		tolerance: DefaultTolerance,
+	}
+}
+
+// SetTolerance sets how far the follower can drift from the leader before it
+// is corrected (default: DefaultTolerance). Corrections make the effects jump,
+// so the tolerance should be larger than the jitter of the network.
+func (f *Follower) SetTolerance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tolerance = d
+}
+
+// ListenAndServe listens for the state of a leader on the UDP address and
+// follows it on the ring.
+func ListenAndServe(addr string, r *ring.Ring) error {
+	c, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return fmt.Errorf("ringsync: %w", err)
+	}
+	defer c.Close()
+
+	return NewFollower(r).Serve(c)
+}
+
+// Serve follows the states received on the packet connection. Invalid
+// datagrams are ignored. It returns when the connection fails, for example
+// when it is closed.
+func (f *Follower) Serve(c net.PacketConn) error {
+	buf := make([]byte, 0xFFFF)
+	for {
+		n, _, err := c.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		f.handle(buf[:n], f.r.Clock().Now())
+	}
+}
+
+// handle follows a state received at time now.
+func (f *Follower) handle(msg []byte, now time.Time) error {
+	session, version, phase, data, err := decode(msg)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.preset == nil || session != f.session || version != f.version {
+		p := &ring.Preset{}
+		if err := json.Unmarshal(data, p); err != nil {
+			return fmt.Errorf("ringsync: invalid preset: %w", err)
+		}
+		if err := f.play(p, phase, now); err != nil {
+			return err
+		}
+		f.session = session
+		f.version = version
+		return nil
+	}
+
+	switch drift := phase - now.Sub(f.start); {
+	case drift > f.tolerance:
+		for _, e := range f.effects {
+			ring.AdvanceEffect(e, drift)
+		}
+		f.start = f.start.Add(-drift)
+	case drift < -f.tolerance:
+		// Effects cannot go back in time, so they start over.
+		return f.play(f.preset, phase, now)
+	}
+
+	return nil
+}
+
+// play replaces the effects of the follower with the effects of a preset, at
+// phase from their start.
+func (f *Follower) play(p *ring.Preset, phase time.Duration, now time.Time) error {
+	es, err := p.Effects(ring.DefaultRegistry, f.r.Size())
+	if err != nil {
+		return err
+	}
+	for _, e := range es {
+		ring.AdvanceEffect(e, phase)
+	}
+
+	for _, e := range f.effects {
+		f.r.RemoveEffect(e)
+	}
+	for _, e := range es {
+		f.r.AddEffect(e)
+	}
+	f.preset = p
+	f.effects = es
+	f.start = now.Add(-phase)
+
+	return nil
+}
+
+// encode returns the message of a state.
+func encode(session, version uint32, phase time.Duration, data []byte) []byte {
+	msg := make([]byte, headerSize, headerSize+len(data))
+	msg[0] = magic
+	binary.BigEndian.PutUint32(msg[1:], session)
+	binary.BigEndian.PutUint32(msg[5:], version)
+	binary.BigEndian.PutUint64(msg[9:], uint64(phase))
+
+	return append(msg, data...)
+}
+
+// decode returns the state of a message.
+func decode(msg []byte) (session, version uint32, phase time.Duration, data []byte, err error) {
+	if len(msg) < headerSize || msg[0] != magic {
+		return 0, 0, 0, nil, fmt.Errorf("ringsync: invalid message")
+	}
+	session = binary.BigEndian.Uint32(msg[1:])
+	version = binary.BigEndian.Uint32(msg[5:])
+	phase = time.Duration(binary.BigEndian.Uint64(msg[9:]))
+
+	return session, version, phase, msg[headerSize:], nil
+}
@@ -0,0 +1,184 @@
+package ringsync
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestFollow(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	follower, dev := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	go NewFollower(follower).Serve(c)
+
+	conn, err := net.Dial("udp", c.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	leader, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	l := NewLeader(leader, conn)
+
+	for _, ts := range []struct {
+		color string
+		want  uint32
+	}{
+		{"#ff0000", 0xFF0000},
+		{"#0000ff", 0x0000FF},
+	} {
+		p := &ring.Preset{Layers: []ring.PresetLayer{{Effect: "solid", Params: map[string]string{"color": ts.color}}}}
+		if err := l.Play(p); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(leader.Layers()); got != 1 {
+			t.Errorf("leader got: %d layers, want: 1", got)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			if err := follower.Render(); err != nil {
+				t.Fatal(err)
+			}
+			if dev.Frame()[0] == ts.want && len(follower.Layers()) == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got: %#x, want: %#x", dev.Frame(), ts.want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestDrift(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 8})
+	f := NewFollower(r)
+	p := &ring.Preset{Layers: []ring.PresetLayer{{Effect: "rainbow", Params: map[string]string{"speed": "90"}}}}
+	data := []byte(`{"layers": [{"effect": "rainbow", "params": {"speed": "90"}}]}`)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		phase time.Duration // of the leader
+		at    time.Duration // local time of the message
+		want  time.Duration // phase of the follower
+	}{
+		{"start", time.Second, 0, time.Second},
+		{"in step", 2 * time.Second, time.Second, 2 * time.Second},
+		{"within tolerance", 3*time.Second + 20*time.Millisecond, 2 * time.Second, 3 * time.Second},
+		{"behind", 4 * time.Second, 2500 * time.Millisecond, 4 * time.Second},
+		{"ahead", 4 * time.Second, 3 * time.Second, 4 * time.Second},
+	}
+
+	var last time.Duration
+	for _, ts := range tests {
+		// The ring of the follower plays the effects in local time.
+		for _, e := range f.effects {
+			ring.AdvanceEffect(e, ts.at-last)
+		}
+		last = ts.at
+
+		if err := f.handle(encode(1, 1, ts.phase, data), start.Add(ts.at)); err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+		if got := start.Add(ts.at).Sub(f.start); got != ts.want {
+			t.Errorf("%s got: phase %v, want: %v", ts.name, got, ts.want)
+		}
+
+		es, err := p.Effects(ring.DefaultRegistry, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring.AdvanceEffect(es[0], ts.want)
+		for i := 0; i < 8; i++ {
+			if got, want := f.effects[0].Pixel(i), es[0].Pixel(i); got != want {
+				t.Errorf("%s pixel %d got: %v, want: %v", ts.name, i, got, want)
+				break
+			}
+		}
+	}
+
+	if err := f.handle([]byte("hello"), start); err == nil {
+		t.Errorf("invalid message got: nil, want: error")
+	}
+}
+
+func TestLeaderRestart(t *testing.T) {
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	f := NewFollower(r)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		session uint32
+		version uint32
+		color   string
+		want    uint32
+	}{
+		{"first", 1, 1, "#ff0000", 0xFF0000},
+		{"same version", 1, 1, "#00ff00", 0xFF0000},
+		{"new version", 1, 2, "#00ff00", 0x00FF00},
+		{"restarted leader", 2, 1, "#0000ff", 0x0000FF},
+	}
+
+	for _, ts := range tests {
+		data := []byte(`{"layers": [{"effect": "solid", "params": {"color": "` + ts.color + `"}}]}`)
+		if err := f.handle(encode(ts.session, ts.version, 0, data), now); err != nil {
+			t.Fatalf("%s: %v", ts.name, err)
+		}
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		if got := dev.Frame()[0]; got != ts.want {
+			t.Errorf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+		}
+	}
+}
+
+func TestLeaderClock(t *testing.T) {
+	c, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	conn, err := net.Dial("udp", c.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	clock := ringtest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4, Clock: clock})
+	l := NewLeader(r, conn)
+	p := &ring.Preset{Layers: []ring.PresetLayer{{Effect: "solid", Params: map[string]string{"color": "red"}}}}
+	if err := l.Play(p); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 0xFFFF)
+	if _, _, err := c.ReadFrom(buf); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(1500 * time.Millisecond)
+	if err := l.Send(); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := c.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, phase, _, err := decode(buf[:n])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 1500 * time.Millisecond; phase != want {
+		t.Errorf("got: phase %v, want: %v", phase, want)
+	}
+}
@@ -0,0 +1,156 @@
+package ringtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Clock is a fake clock that only moves forward when advanced, to render
+// animations deterministically. Set it as the Clock of the options of a ring,
+// run the ring in a goroutine, and advance the clock:
+//
+//	go func() { done <- r.Run(ctx, 60) }()
+//	clock.WaitForTickers(1)
+//	clock.Advance(time.Second) // renders 60 frames
+//	cancel()
+//	<-done // the last frame is rendered
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*ticker
+	timers  []*timer
+}
+
+// NewClock creates a fake clock that starts at a time.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTicker returns a ticker that ticks every period d as the clock is
+// advanced.
+func (c *Clock) NewTicker(d time.Duration) ring.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &ticker{
+		c:      make(chan time.Time),
+		stop:   make(chan struct{}),
+		period: d,
+		next:   c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, t)
+
+	return t
+}
+
+// After returns a channel that receives the time once the clock is advanced
+// by d.
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{
+		c:  make(chan time.Time, 1),
+		at: c.now.Add(d),
+	}
+	c.timers = append(c.timers, t)
+
+	return t.c
+}
+
+// WaitForTickers blocks until n tickers were created, for example by Run
+// running in another goroutine, so the clock is not advanced before they
+// start.
+func (c *Clock) WaitForTickers(n int) {
+	for {
+		c.mu.Lock()
+		created := len(c.tickers)
+		c.mu.Unlock()
+		if created >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Advance moves the clock forward by d, firing the tickers and timers that
+// are due, in order. Each tick waits until it is received, or until its
+// ticker is stopped, so a ring run with the clock renders every frame.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var next time.Time
+		var fire func(time.Time)
+		for _, t := range c.tickers {
+			t := t
+			if !t.next.After(end) && (fire == nil || t.next.Before(next)) {
+				next = t.next
+				fire = func(now time.Time) {
+					t.next = t.next.Add(t.period)
+					c.mu.Unlock()
+					t.send(now)
+				}
+			}
+		}
+		for i, t := range c.timers {
+			i, t := i, t
+			if !t.at.After(end) && (fire == nil || t.at.Before(next)) {
+				next = t.at
+				fire = func(now time.Time) {
+					c.timers = append(c.timers[:i:i], c.timers[i+1:]...)
+					c.mu.Unlock()
+					t.c <- now
+				}
+			}
+		}
+		if fire == nil {
+			c.now = end
+			c.mu.Unlock()
+			return
+		}
+		c.now = next
+		fire(next)
+	}
+}
+
+type ticker struct {
+	c      chan time.Time
+	stop   chan struct{}
+	once   sync.Once
+	period time.Duration
+	next   time.Time
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *ticker) Stop() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+func (t *ticker) send(now time.Time) {
+	select {
+	case t.c <- now:
+	case <-t.stop:
+	}
+}
+
+type timer struct {
+	c  chan time.Time
+	at time.Time
+}
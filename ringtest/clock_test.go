@@ -0,0 +1,39 @@
+package ringtest_test
+
+import (
+	"context"
+	"image/color"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestClock(t *testing.T) {
+	clock := ringtest.NewClock(time.Unix(0, 0))
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4, Clock: clock})
+	s := ring.NewSprite(0, color.White)
+	s.SetVelocity(1)
+	r.Animator().Play(s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx, 10) }()
+
+	clock.WaitForTickers(1)
+	clock.Advance(time.Second)
+	cancel()
+	<-done
+
+	if got := s.Position(); math.Abs(got-1) > 1e-9 {
+		t.Errorf("position got: %v, want: 1", got)
+	}
+	if got := r.Stats().Frames; got != 10 {
+		t.Errorf("frames got: %d, want: 10", got)
+	}
+	if got, want := clock.Now(), time.Unix(1, 0); !got.Equal(want) {
+		t.Errorf("now got: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+// Package ringtest provides utilities to test layers and effects of the ring
+// package without LED hardware.
+//
+// Rings are rendered to a mock Device, and the rendered frames can be compared
+// against golden files stored in the testdata directory of the package under
+// test. Run the tests with the environment variable RINGTEST_UPDATE=1 to
+// create or update the golden files.
+package ringtest
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cgxeiji/ring"
+)
+
+// Device is a mock device that keeps the frames rendered by a ring in memory.
+type Device struct {
+	mu     sync.Mutex
+	frames [][]uint32
+	closed bool
+}
+
+// Render records a copy of a frame.
+func (d *Device) Render(frame []uint32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return fmt.Errorf("ringtest: render on closed device")
+	}
+	d.frames = append(d.frames, append([]uint32(nil), frame...))
+
+	return nil
+}
+
+// Close marks the device as closed.
+func (d *Device) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+}
+
+// Closed reports whether the device was closed.
+func (d *Device) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.closed
+}
+
+// Frames returns all the frames rendered to the device.
+func (d *Device) Frames() [][]uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.frames
+}
+
+// Frame returns the last frame rendered to the device, or nil if nothing was
+// rendered.
+func (d *Device) Frame() []uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.frames) == 0 {
+		return nil
+	}
+	return d.frames[len(d.frames)-1]
+}
+
+// NewRing creates a ring that renders to a mock device. If MaxBrightness is
+// not set, it defaults to 255 so frames keep the colors of the layers.
+func NewRing(t testing.TB, options *ring.Options) (*ring.Ring, *Device) {
+	t.Helper()
+
+	opt := *options
+	if opt.MaxBrightness == 0 {
+		opt.MaxBrightness = 255
+	}
+	dev := &Device{}
+	r, err := ring.NewWithDevice(dev, &opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r, dev
+}
+
+// Render renders a stack of layers on a ring with a number of LEDs and
+// returns the rendered frame.
+func Render(t testing.TB, size int, layers ...ring.Pixeler) []uint32 {
+	t.Helper()
+
+	r, dev := NewRing(t, &ring.Options{LedCount: size})
+	for _, l := range layers {
+		r.AddLayer(l)
+	}
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	return dev.Frame()
+}
+
+// Golden compares a frame against the golden file testdata/name.golden. Each
+// color channel of each LED can differ by up to tolerance.
+func Golden(t testing.TB, name string, frame []uint32, tolerance uint8) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv("RINGTEST_UPDATE") != "" {
+		if err := writeGolden(path, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := readGolden(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frame) != len(want) {
+		t.Fatalf("ringtest: got %d LEDs, golden file %q has %d", len(frame), path, len(want))
+	}
+	for i := range want {
+		if !Equal(frame[i], want[i], tolerance) {
+			t.Errorf("ringtest: LED %d got: #%06x, want: #%06x", i, frame[i], want[i])
+		}
+	}
+}
+
+// Equal reports whether two colors with the shape 0x00RRGGBB differ by at
+// most tolerance on each color channel.
+func Equal(a, b uint32, tolerance uint8) bool {
+	for shift := uint(0); shift < 24; shift += 8 {
+		ca, cb := int(a>>shift&0xFF), int(b>>shift&0xFF)
+		d := ca - cb
+		if d < 0 {
+			d = -d
+		}
+		if d > int(tolerance) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeGolden writes a frame as a golden file, with a hex color per line.
+func writeGolden(path string, frame []uint32) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, c := range frame {
+		fmt.Fprintf(&b, "#%06x\n", c)
+	}
+
+	return ioutil.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// readGolden reads a frame from a golden file.
+func readGolden(path string) ([]uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ringtest: could not open golden file (run with RINGTEST_UPDATE=1 to create it): %w", err)
+	}
+	defer f.Close()
+
+	var frame []uint32
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		var c uint32
+		if _, err := fmt.Sscanf(line, "#%06x", &c); err != nil {
+			return nil, fmt.Errorf("ringtest: invalid color %q in %q", line, path)
+		}
+		frame = append(frame, c)
+	}
+
+	return frame, s.Err()
+}
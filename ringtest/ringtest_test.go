@@ -0,0 +1,34 @@
+package ringtest_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestGolden(t *testing.T) {
+	bg, err := ring.NewLayer(&ring.LayerOptions{Resolution: 1, ContentMode: ring.ContentScale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bg.SetAll(color.RGBA{0x00, 0x00, 0x40, 0xFF})
+	fg, err := ring.NewLayer(&ring.LayerOptions{Resolution: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fg.SetPixel(1, color.NRGBA{0xFF, 0x00, 0x00, 0x80})
+	fg.SetPixel(4, color.White)
+
+	frame := ringtest.Render(t, 6, bg, fg)
+	ringtest.Golden(t, "two_layers", frame, 1)
+}
+
+func TestNewRingOptions(t *testing.T) {
+	options := &ring.Options{LedCount: 2}
+	ringtest.NewRing(t, options)
+	if got := options.MaxBrightness; got != 0 {
+		t.Errorf("got: MaxBrightness %d, want: options unchanged", got)
+	}
+}
@@ -0,0 +1,208 @@
+package ring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// advancer is implemented by layers that change over time while the ring
+// runs.
+type advancer interface {
+	advance(dt time.Duration)
+}
+
+// Updater is implemented by layers that animate themselves, such as a layer
+// that shows the time, so they need no goroutine of their own. Run calls
+// Update on the layers of the ring that implement it, and on the layers of the
+// scenes of a SceneManager, between frames.
+type Updater interface {
+	// Update advances the layer by t, the time since the last update, paused
+	// and scaled as the animations of the ring. It is called from the
+	// goroutine of Run, so it must not block.
+	Update(t time.Duration)
+}
+
+// updateLayer advances a layer by dt, both the built-in animations of the
+// layer, such as Layer.Spin, and its own updates, if it is an Updater.
+func updateLayer(l interface{}, dt time.Duration) {
+	if a, ok := l.(advancer); ok {
+		a.advance(dt)
+	}
+	if u, ok := l.(Updater); ok {
+		u.Update(dt)
+	}
+}
+
+// Run renders the ring at a fixed number of frames per second, advancing its
+// animator and animated layers (see Layer.Spin) between frames by the time
+// elapsed, or in fixed steps (see SetTimestep), until ctx is done. Frames
+// where nothing changed are skipped. Run returns ctx.Err() once ctx is done,
+// or the first render error. See Stats for the statistics of the frames.
+func (r *Ring) Run(ctx context.Context, fps float64) error {
+	if fps <= 0 {
+		return fmt.Errorf("ring: invalid frame rate %v", fps)
+	}
+
+	clock := r.opt.clock()
+	period := time.Duration(float64(time.Second) / fps)
+	ticker := clock.NewTicker(period)
+	defer ticker.Stop()
+
+	last := clock.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C():
+			interval := now.Sub(last)
+			r.tick(r.step(now, interval))
+			last = now
+			if err := r.Render(); err != nil {
+				return err
+			}
+			r.recordFrame(interval, clock.Now().Sub(now), period)
+		}
+	}
+}
+
+// Animator returns the animator of the ring, advanced by Run.
+func (r *Ring) Animator() *Animator {
+	return r.animator
+}
+
+// Pause freezes the animations and animated layers of the ring, while Run
+// keeps the current frame lit, until Resume is called. Layers can still be
+// changed directly while paused.
+func (r *Ring) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paused = true
+}
+
+// Resume continues the animations and animated layers of the ring after
+// Pause.
+func (r *Ring) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.paused = false
+}
+
+// Paused reports whether the ring is paused.
+func (r *Ring) Paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.paused
+}
+
+// SetTimeScale sets how fast the animations and animated layers of the ring
+// advance, relative to real time: 0.5 plays them at half speed, and 2 at
+// twice the speed (default: 1). Negative factors are treated as 0. It returns
+// an error, and keeps the current speed, if factor is NaN or infinite.
+func (r *Ring) SetTimeScale(factor float64) error {
+	if math.IsNaN(factor) || math.IsInf(factor, 0) {
+		return fmt.Errorf("ring: invalid time scale %v", factor)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if factor < 0 {
+		factor = 0
+	}
+	r.scale = factor
+
+	return nil
+}
+
+// TimeScale returns the speed of the animations of the ring.
+func (r *Ring) TimeScale() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.scale
+}
+
+// maxSteps is the most fixed steps that the animations advance per frame (see
+// SetTimestep). If rendering falls further behind, the last step is longer, so
+// the animations keep up with time.
+const maxSteps = 64
+
+// SetTimestep makes Run advance the animations, animated layers and effects of
+// the ring in fixed steps of a duration, carrying the time left over to the
+// next frame, instead of by the time between frames. Effects that simulate,
+// such as Balls, then play the same at any frame rate, and all the animations
+// stay in step with time when frames are late. A step of 0 (the default)
+// advances them by the time between frames.
+func (r *Ring) SetTimestep(step time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if step < 0 {
+		step = 0
+	}
+	r.timestep = step
+	r.carry = 0
+}
+
+// Timestep returns the fixed step the animations advance by, or 0 if they
+// advance by the time between frames.
+func (r *Ring) Timestep() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.timestep
+}
+
+// advanceSteps advances the ring by dt, in fixed steps if step is not 0,
+// carrying the time left over to the next call.
+func (r *Ring) advanceSteps(dt, step time.Duration) {
+	if step <= 0 {
+		r.advance(dt)
+		return
+	}
+
+	r.mu.Lock()
+	r.carry += dt
+	n := int(r.carry / step)
+	r.carry -= time.Duration(n) * step
+	r.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		if i == maxSteps-1 {
+			r.advance(time.Duration(n-i) * step)
+			return
+		}
+		r.advance(step)
+	}
+}
+
+// advance moves the animations and animated layers of the ring forward by dt,
+// scaled by the time scale unless synced (see SyncToClock), unless the ring is
+// paused. Notifications always move forward by dt.
+func (r *Ring) advance(dt time.Duration) {
+	if r.notices.advance(dt) {
+		r.markDirty()
+	}
+
+	r.mu.Lock()
+	layers := r.layers
+	paused := r.paused
+	if r.syncPeriod == 0 {
+		dt = time.Duration(float64(dt) * r.scale)
+	}
+	r.mu.Unlock()
+
+	if paused {
+		return
+	}
+	r.animator.Tick(dt)
+
+	for _, l := range layers {
+		updateLayer(l, dt)
+	}
+}
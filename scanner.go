@@ -0,0 +1,193 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Scanner is a layer with an eye that sweeps back and forth, leaving a trail
+// that fades out, like the Larson scanner of Knight Rider. The eye sweeps
+// either the full ring, turning back at the first pixel, or an arc from the
+// first pixel, which can be placed with Rotate, turning back at its end.
+// Add it to a ring with AddLayer and run the ring (see Ring.Run) to animate
+// it. Its parameters are "color", "width", "speed" and "arc" in degrees, and
+// "decay" in seconds (see Tunable).
+type Scanner struct {
+	*Layer
+	params
+
+	mu    sync.Mutex
+	color color.Color
+	width float64 // angular width of the eye in radians
+	speed float64 // angular velocity in radians per second
+	decay float64 // seconds for the trail to fade out
+	arc   float64 // angular length of the sweep in radians
+	dist  float64 // angle travelled, modulo a sweep there and back
+}
+
+// trailFloor is the brightness of a trail, as a fraction of its start, at
+// which it is considered faded out.
+const trailFloor = 0.01
+
+// NewScanner creates a scanner of a color, with an eye of 20 degrees and a
+// trail that fades out in half a second, that sweeps the full ring in 2
+// seconds each way.
+func NewScanner(c color.Color, options *LayerOptions) (*Scanner, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Scanner{
+		Layer: l,
+		color: c,
+		width: radians(20),
+		speed: math.Pi,
+		decay: 0.5,
+		arc:   2 * math.Pi,
+	}
+	s.params = params{specs: scannerParams, get: s.param, set: s.setParam}
+	s.paint(0)
+
+	return s, nil
+}
+
+// SetColor sets the color of the eye.
+func (s *Scanner) SetColor(c color.Color) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.color = c
+}
+
+// SetWidth sets the angular width of the eye (in radians).
+func (s *Scanner) SetWidth(angle float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.width = angle
+}
+
+// SetSpeed sets the angular velocity of the eye (in radians per second).
+func (s *Scanner) SetSpeed(velocity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.speed = math.Abs(velocity)
+}
+
+// SetDecay sets the time for the trail to fade out. A zero duration leaves no
+// trail.
+func (s *Scanner) SetDecay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.decay = d.Seconds()
+}
+
+// SetArc sets the angular length of the sweep from the first pixel (in
+// radians). An angle of 2π or more sweeps the full ring.
+func (s *Scanner) SetArc(angle float64) {
+	s.mu.Lock()
+	s.arc = math.Min(math.Max(angle, 0), 2*math.Pi)
+	s.dist = math.Mod(s.dist, 2*s.arc)
+	s.mu.Unlock()
+
+	s.SetAll(color.Transparent)
+	s.paint(0)
+}
+
+var scannerParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#ff0000", Doc: "color of the eye"},
+	{Name: "width", Type: ParamFloat, Default: "20", Doc: "width of the eye, in degrees", Min: 0, Max: 360},
+	{Name: "speed", Type: ParamFloat, Default: "180", Doc: "degrees per second", Min: 0, Max: 1440},
+	{Name: "decay", Type: ParamFloat, Default: "0.5", Doc: "seconds for the trail to fade out", Min: 0, Max: 10},
+	{Name: "arc", Type: ParamFloat, Default: "360", Doc: "degrees swept from the first pixel, or 360 for the full ring", Min: 0, Max: 360},
+}
+
+func (s *Scanner) param(name string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name {
+	case "color":
+		return s.color
+	case "width":
+		return s.width * 180 / math.Pi
+	case "speed":
+		return s.speed * 180 / math.Pi
+	case "decay":
+		return s.decay
+	case "arc":
+		return s.arc * 180 / math.Pi
+	}
+	return nil
+}
+
+func (s *Scanner) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		s.SetColor(v.(color.Color))
+	case "width":
+		s.SetWidth(radians(v.(float64)))
+	case "speed":
+		s.SetSpeed(radians(v.(float64)))
+	case "decay":
+		s.SetDecay(time.Duration(v.(float64) * float64(time.Second)))
+	case "arc":
+		s.SetArc(radians(v.(float64)))
+	}
+}
+
+func (s *Scanner) advance(dt time.Duration) {
+	s.mu.Lock()
+	factor := 0.0
+	if s.decay > 0 {
+		factor = math.Pow(trailFloor, dt.Seconds()/s.decay)
+	}
+	s.mu.Unlock()
+
+	s.dim(factor)
+	s.paint(s.speed * dt.Seconds())
+	s.Layer.advance(dt)
+}
+
+// paint moves the eye by an angle and draws it over the trail, across all the
+// positions it went through, so fast eyes leave no gaps.
+func (s *Scanner) paint(angle float64) {
+	s.mu.Lock()
+	c, width, arc := s.color, s.width, s.arc
+	from := s.dist
+	to := from + angle
+	s.dist = 0
+	if arc > 0 {
+		s.dist = math.Mod(to, 2*arc)
+	}
+	s.mu.Unlock()
+
+	if arc == 0 {
+		return
+	}
+	// pos is the position of the eye after travelling d, along the sweep
+	// there and back.
+	pos := func(d float64) float64 {
+		d = math.Mod(d, 2*arc)
+		if d > arc {
+			return 2*arc - d
+		}
+		return d
+	}
+	lo, hi := math.Min(pos(from), pos(to)), math.Max(pos(from), pos(to))
+	if math.Floor((from-arc)/(2*arc)) != math.Floor((to-arc)/(2*arc)) {
+		hi = arc // turned back at the end
+	}
+	if math.Floor(from/(2*arc)) != math.Floor(to/(2*arc)) {
+		lo = 0 // turned back at the start
+	}
+
+	n := float64(s.opt.Resolution)
+	scale := n / (2 * math.Pi)
+	s.drawSpan((lo-width/2)*scale, (hi+width/2)*scale, solid(c))
+}
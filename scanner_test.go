@@ -0,0 +1,70 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name  string
+		arc   float64
+		steps []int // brightest pixel after each second
+	}{
+		{"full", 2 * math.Pi, []int{2, 4, 6, 0, 6, 4, 2, 0}},
+		{"arc", math.Pi, []int{2, 4, 2, 0, 2, 4}},
+	}
+
+	for _, ts := range tests {
+		s, err := NewScanner(color.White, &LayerOptions{Resolution: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.SetWidth(2 * math.Pi / 8)
+		s.SetSpeed(math.Pi / 2)
+		s.SetDecay(100 * time.Millisecond)
+		s.SetArc(ts.arc)
+
+		for i, want := range ts.steps {
+			for j := 0; j < 10; j++ {
+				s.advance(100 * time.Millisecond)
+			}
+			if got := brightest(s.Layer); got != want {
+				t.Errorf("%s second %d got: eye at %d, want: %d", ts.name, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestScannerTrail(t *testing.T) {
+	s, err := NewScanner(color.White, &LayerOptions{Resolution: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetWidth(2 * math.Pi / 8)
+	s.SetSpeed(math.Pi / 2)
+	s.SetDecay(time.Second)
+	for i := 0; i < 10; i++ {
+		s.advance(100 * time.Millisecond)
+	}
+
+	_, _, _, eye := s.Pixel(2).RGBA()
+	_, _, _, trail := s.Pixel(1).RGBA()
+	_, _, _, ahead := s.Pixel(3).RGBA()
+	if !(eye > trail && trail > 0 && ahead == 0) {
+		t.Errorf("got: eye %d, trail %d, ahead %d, want: eye > trail > ahead = 0", eye, trail, ahead)
+	}
+}
+
+// brightest returns the index of the most opaque pixel of a layer.
+func brightest(l *Layer) int {
+	index, max := -1, uint32(0)
+	for i := 0; i < l.opt.Resolution; i++ {
+		if _, _, _, a := l.Pixel(i).RGBA(); a > max {
+			index, max = i, a
+		}
+	}
+	return index
+}
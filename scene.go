@@ -0,0 +1,269 @@
+package ring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Scene is a stack of layers and the animations that change them, usually
+// loaded from a scene file.
+type Scene struct {
+	// Name is the name of the scene.
+	Name string
+	// Layers are the layers of the scene, from bottom to top.
+	Layers []*Layer
+	// Timelines are the animations of the layers.
+	Timelines []*Timeline
+}
+
+// SceneSpec describes a scene in a scene file.
+type SceneSpec struct {
+	Name   string      `json:"name" yaml:"name"`
+	Layers []LayerSpec `json:"layers" yaml:"layers"`
+}
+
+// LayerSpec describes a layer of a scene file.
+type LayerSpec struct {
+	// Name identifies the layer (see LayerOptions.Name).
+	Name string `json:"name" yaml:"name"`
+	// Resolution is the number of pixels of the layer.
+	Resolution int `json:"resolution" yaml:"resolution"`
+	// ContentMode is one of "tile" (default), "crop" or "scale".
+	ContentMode string `json:"content_mode" yaml:"content_mode"`
+	// Color fills the layer, as a hex color such as "#ff8800" or a named
+	// color such as "orange" (see ParseColor).
+	Color string `json:"color" yaml:"color"`
+	// Pixels sets the color of single pixels, by index.
+	Pixels map[int]string `json:"pixels" yaml:"pixels"`
+	// Rotation is the initial rotation of the layer, in degrees.
+	Rotation float64 `json:"rotation" yaml:"rotation"`
+	// Spin is the angular velocity of the layer, in degrees per second.
+	Spin float64 `json:"spin" yaml:"spin"`
+	// Opacity is the opacity of the layer, from 0.0 to 1.0 (default: 1.0).
+	Opacity *float64 `json:"opacity" yaml:"opacity"`
+	// Tracks animate the properties of the layer.
+	Tracks []TrackSpec `json:"tracks" yaml:"tracks"`
+}
+
+// TrackSpec describes the animation of a property of a layer in a scene file.
+type TrackSpec struct {
+	// Property is one of "rotation" (in degrees), "opacity" or "color".
+	Property string `json:"property" yaml:"property"`
+	// Loop is one of "none" (default), "repeat" or "ping-pong".
+	Loop string `json:"loop" yaml:"loop"`
+	// Keyframes are the values of the property over time.
+	Keyframes []KeyframeSpec `json:"keyframes" yaml:"keyframes"`
+}
+
+// KeyframeSpec describes a keyframe in a scene file.
+type KeyframeSpec struct {
+	// At is the time of the keyframe, such as "1.5s".
+	At string `json:"at" yaml:"at"`
+	// Value is the value of rotation and opacity tracks.
+	Value float64 `json:"value" yaml:"value"`
+	// Color is the value of color tracks, as a hex or named color.
+	Color string `json:"color" yaml:"color"`
+	// Easing is one of "linear" (default), "ease-in", "ease-out" or
+	// "ease-in-out".
+	Easing string `json:"easing" yaml:"easing"`
+}
+
+// LoadScene reads a scene file and builds its layers. The format is selected
+// by the extension of the file: ".json", ".yaml" or ".yml".
+func LoadScene(path string) (*Scene, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ring: could not read scene: %w", err)
+	}
+
+	spec := &SceneSpec{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(spec)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, spec)
+	default:
+		return nil, fmt.Errorf("ring: unknown scene format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ring: could not parse scene %q: %w", path, err)
+	}
+
+	return NewScene(spec)
+}
+
+// NewScene builds the layers and animations described by a scene spec.
+func NewScene(spec *SceneSpec) (*Scene, error) {
+	s := &Scene{
+		Name: spec.Name,
+	}
+	for i, ls := range spec.Layers {
+		l, tls, err := ls.build()
+		if err != nil {
+			return nil, fmt.Errorf("ring: layer %d of scene %q: %w", i, spec.Name, err)
+		}
+		s.Layers = append(s.Layers, l)
+		s.Timelines = append(s.Timelines, tls...)
+	}
+
+	return s, nil
+}
+
+// AddTo adds the layers of the scene to a ring, and plays the animations with
+// the animator of the ring.
+func (s *Scene) AddTo(r *Ring) {
+	for _, l := range s.Layers {
+		r.syncEffect(l)
+		r.AddLayer(l)
+	}
+	for _, tl := range s.Timelines {
+		r.syncEffect(tl)
+		r.Animator().Play(tl)
+	}
+}
+
+func (ls *LayerSpec) build() (*Layer, []*Timeline, error) {
+	mode, err := parseContentMode(ls.ContentMode)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := NewLayer(&LayerOptions{
+		Name:        ls.Name,
+		Resolution:  ls.Resolution,
+		ContentMode: mode,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ls.Color != "" {
+		c, err := ParseColor(ls.Color)
+		if err != nil {
+			return nil, nil, err
+		}
+		l.SetAll(c)
+	}
+	for i, hex := range ls.Pixels {
+		if i < 0 || i >= ls.Resolution {
+			return nil, nil, fmt.Errorf("%w: pixel %d of %d", ErrPixelOutOfRange, i, ls.Resolution)
+		}
+		c, err := ParseColor(hex)
+		if err != nil {
+			return nil, nil, err
+		}
+		l.SetPixel(i, c)
+	}
+	l.RotateDegrees(ls.Rotation)
+	l.Spin(radians(ls.Spin))
+	if ls.Opacity != nil {
+		l.SetOpacity(*ls.Opacity)
+	}
+
+	var tls []*Timeline
+	for _, ts := range ls.Tracks {
+		tl, err := ts.build(l)
+		if err != nil {
+			return nil, nil, err
+		}
+		tls = append(tls, tl)
+	}
+
+	return l, tls, nil
+}
+
+func (ts *TrackSpec) build(l *Layer) (*Timeline, error) {
+	keys := make([]Keyframe, len(ts.Keyframes))
+	for i, ks := range ts.Keyframes {
+		k, err := ks.build()
+		if err != nil {
+			return nil, err
+		}
+		if ts.Property == "rotation" {
+			k.Value = radians(k.Value)
+		}
+		keys[i] = k
+	}
+
+	tl := NewTimeline()
+	switch ts.Property {
+	case "rotation":
+		tl.Rotation(l, keys...)
+	case "opacity":
+		tl.Opacity(l, keys...)
+	case "color":
+		tl.Color(l, keys...)
+	default:
+		return nil, fmt.Errorf("unknown track property %q", ts.Property)
+	}
+
+	switch ts.Loop {
+	case "", "none":
+		tl.SetLoop(LoopNone)
+	case "repeat":
+		tl.SetLoop(LoopRepeat)
+	case "ping-pong":
+		tl.SetLoop(LoopPingPong)
+	default:
+		return nil, fmt.Errorf("unknown loop mode %q", ts.Loop)
+	}
+
+	return tl, nil
+}
+
+func (ks *KeyframeSpec) build() (Keyframe, error) {
+	k := Keyframe{
+		Value: ks.Value,
+	}
+
+	if ks.At != "" {
+		at, err := time.ParseDuration(ks.At)
+		if err != nil {
+			return k, err
+		}
+		k.At = at
+	}
+	if ks.Color != "" {
+		c, err := ParseColor(ks.Color)
+		if err != nil {
+			return k, err
+		}
+		k.Color = c
+	}
+
+	switch ks.Easing {
+	case "", "linear":
+		k.Easing = Linear
+	case "ease-in":
+		k.Easing = EaseIn
+	case "ease-out":
+		k.Easing = EaseOut
+	case "ease-in-out":
+		k.Easing = EaseInOut
+	default:
+		return k, fmt.Errorf("unknown easing %q", ks.Easing)
+	}
+
+	return k, nil
+}
+
+func parseContentMode(s string) (ContentMode, error) {
+	switch s {
+	case "", "tile":
+		return ContentTile, nil
+	case "crop":
+		return ContentCrop, nil
+	case "scale":
+		return ContentScale, nil
+	}
+
+	return 0, fmt.Errorf("unknown content mode %q", s)
+}
@@ -0,0 +1,135 @@
+package ring
+
+import (
+	"errors"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadScene(t *testing.T) {
+	files := map[string]string{
+		"scene.yaml": `
+name: alarm
+layers:
+  - resolution: 1
+    content_mode: scale
+    color: "#ff0000"
+    tracks:
+      - property: opacity
+        loop: ping-pong
+        keyframes:
+          - at: 0s
+            value: 0
+          - at: 1s
+            value: 1
+            easing: ease-in-out
+  - resolution: 12
+    pixels:
+      3: "#fff"
+    spin: 90
+`,
+		"scene.json": `{
+  "name": "alarm",
+  "layers": [
+    {"resolution": 1, "content_mode": "scale", "color": "#ff0000",
+     "tracks": [{"property": "opacity", "loop": "ping-pong", "keyframes": [
+       {"at": "0s", "value": 0}, {"at": "1s", "value": 1, "easing": "ease-in-out"}]}]},
+    {"resolution": 12, "pixels": {"3": "#fff"}, "spin": 90}
+  ]
+}`,
+	}
+
+	dir, err := ioutil.TempDir("", "scene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := LoadScene(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Name != "alarm" {
+				t.Errorf("got name: %q, want: %q", s.Name, "alarm")
+			}
+			if len(s.Layers) != 2 || len(s.Timelines) != 1 {
+				t.Fatalf("got: %d layers and %d timelines, want: 2 and 1", len(s.Layers), len(s.Timelines))
+			}
+			if got, want := s.Layers[0].Options().ContentMode, ContentScale; got != want {
+				t.Errorf("got content mode: %v, want: %v", got, want)
+			}
+			if got, want := toRGBA64(s.Layers[1].Pixel(3)), toRGBA64(color.White); got != want {
+				t.Errorf("got: %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadSceneUnknownField(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scene")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, data := range map[string]string{
+		"scene.yaml": "name: alarm\nlayers:\n  - resolution: 1\n    colour: red\n",
+		"scene.json": `{"name": "alarm", "layers": [{"resolution": 1, "colour": "red"}]}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadScene(path); err == nil || !strings.Contains(err.Error(), "colour") {
+			t.Errorf("%s got: %v, want: error for unknown field", name, err)
+		}
+	}
+}
+
+func TestNewSceneNegativeResolution(t *testing.T) {
+	_, err := NewScene(&SceneSpec{Layers: []LayerSpec{{Resolution: -3}}})
+	if !errors.Is(err, ErrZeroResolution) {
+		t.Errorf("got: %v, want: %v", err, ErrZeroResolution)
+	}
+}
+
+func TestSceneManager(t *testing.T) {
+	scene := func(c color.Color) *Scene {
+		l := newTestLayer(t, &LayerOptions{Resolution: 1, ContentMode: ContentScale})
+		l.SetAll(c)
+		return &Scene{Layers: []*Layer{l}}
+	}
+	red := scene(color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+	blue := scene(color.RGBA{0x00, 0x00, 0xFF, 0xFF})
+
+	m := NewSceneManager(12)
+	m.SwitchTo(red, 0)
+	m.SwitchTo(blue, time.Second)
+
+	tests := []struct {
+		dt   time.Duration
+		want uint32
+	}{
+		{0, 0xFF0000},
+		{500 * time.Millisecond, 0x80007F},
+		{500 * time.Millisecond, 0x0000FF},
+	}
+
+	for _, ts := range tests {
+		m.advance(ts.dt)
+		if got := serialize(m.Pixel(5)); got != ts.want {
+			t.Errorf("got: %#x, want: %#x", got, ts.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package ring
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// SceneManager is a layer that shows one scene at a time, and crossfades, or
+// plays another transition, between scenes when switching. Add it to a ring
+// with AddLayer and run the ring (see Ring.Run) to play the scenes and the
+// transitions.
+type SceneManager struct {
+	mu   sync.Mutex
+	opt  *LayerOptions
+	from *sceneState // scene fading out, if any
+	to   *sceneState // current scene
+
+	fade       time.Duration // duration of the current transition
+	elapsed    time.Duration // time since the start of the current transition
+	transition Transition
+}
+
+// Transition mixes two scenes while switching between them (see
+// SceneManager.SwitchWith). It returns how much of the new scene shows at the
+// pixel at position i of a ring of size pixels, from 0.0 (only the previous
+// scene) to 1.0 (only the new scene), at a progress t of the transition, from
+// 0.0 to 1.0.
+type Transition func(i, size int, t float64) float64
+
+// Crossfade is a Transition that fades all the pixels from the previous scene
+// to the new scene at once.
+func Crossfade(i, size int, t float64) float64 {
+	return t
+}
+
+// sceneState is a scene played by a scene manager.
+type sceneState struct {
+	scene  *Scene
+	layers []Pixeler
+}
+
+// NewSceneManager creates a scene manager for a ring with a given number of
+// LEDs, without any scene.
+func NewSceneManager(size int) *SceneManager {
+	return &SceneManager{
+		opt: &LayerOptions{
+			Resolution: size,
+		},
+	}
+}
+
+// SwitchTo changes the current scene, crossfading from the previous scene over
+// a duration. If a transition is in progress, it is replaced.
+func (m *SceneManager) SwitchTo(s *Scene, fade time.Duration) {
+	m.SwitchWith(s, fade, Crossfade)
+}
+
+// SwitchWith is like SwitchTo, but plays a transition from the previous scene,
+// such as WipeTransition, instead of a crossfade. A nil transition crossfades.
+func (m *SceneManager) SwitchWith(s *Scene, d time.Duration, tr Transition) {
+	if tr == nil {
+		tr = Crossfade
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := &sceneState{
+		scene: s,
+	}
+	for _, l := range s.Layers {
+		next.layers = append(next.layers, l)
+	}
+
+	m.from = m.to
+	m.to = next
+	m.fade = d
+	m.elapsed = 0
+	m.transition = tr
+	if m.fade <= 0 {
+		m.from = nil
+	}
+}
+
+// Scene returns the current scene, or nil if there is none.
+func (m *SceneManager) Scene() *Scene {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.to == nil {
+		return nil
+	}
+	return m.to.scene
+}
+
+// Pixel returns the color of the pixel at position i, blending the scenes
+// during a transition.
+func (m *SceneManager) Pixel(i int) color.Color {
+	return m.pixel64(i)
+}
+
+// Options returns the options of the scene manager layer.
+func (m *SceneManager) Options() *LayerOptions {
+	return m.opt
+}
+
+func (m *SceneManager) pixel64(i int) color.RGBA64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.to == nil {
+		return color.RGBA64{}
+	}
+	to := m.composite(m.to, i)
+	if m.from == nil {
+		return to
+	}
+	from := m.composite(m.from, i)
+
+	t := float64(m.elapsed) / float64(m.fade)
+	return blendLerp(from, to, m.transition(i, m.opt.Resolution, t))
+}
+
+func (m *SceneManager) composite(s *sceneState, i int) color.RGBA64 {
+	return composite(s.layers, i, m.opt.Resolution, m.opt.LinearBlending)
+}
+
+// advance plays the scenes and the transition between them.
+func (m *SceneManager) advance(dt time.Duration) {
+	m.mu.Lock()
+	var states []*sceneState
+	if m.from != nil {
+		m.elapsed += dt
+		if m.elapsed >= m.fade {
+			m.from = nil
+		} else {
+			states = append(states, m.from)
+		}
+	}
+	if m.to != nil {
+		states = append(states, m.to)
+	}
+	m.mu.Unlock()
+
+	for _, s := range states {
+		for _, tl := range s.scene.Timelines {
+			tl.Tick(dt)
+		}
+		for _, l := range s.layers {
+			updateLayer(l, dt)
+		}
+	}
+}
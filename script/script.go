@@ -0,0 +1,303 @@
+// Package script defines effects in Starlark, a small dialect of Python,
+// loaded at runtime, so new animations can be deployed to a ring without
+// compiling Go.
+//
+// A script defines a function draw(t), called on every frame with the time t
+// since the effect started, in seconds. It paints the pixels of the effect,
+// which keep their colors between frames:
+//
+//	def draw(t):
+//	    for i in range(size):
+//	        set_pixel(i, palette((i / size + t / 4) % 1, "rainbow"))
+//
+// The script can use:
+//
+//	size                   the number of pixels of the effect
+//	set_pixel(i, color)    sets the color of pixel i
+//	set_all(color)         sets the color of all the pixels
+//	palette(x, colors...)  the color at position x, from 0.0 to 1.0, of a
+//	                       gradient of colors, or of a palette by name,
+//	                       such as "rainbow" (see ring.Palettes)
+//	hsv(h, s, v)           a color from its hue, saturation and value, from
+//	                       0.0 to 1.0
+//	sin(x), cos(x), pi     trigonometry, in radians
+//
+// Colors are strings, such as "#ff8800" or "orange" (see ring.ParseColor), or
+// tuples (r, g, b) or (r, g, b, a) of numbers from 0 to 255.
+//
+// Scripts have no access to the system. Each frame may run at most MaxSteps
+// Starlark steps; a script that runs longer, such as one looping over a huge
+// range, stops with an error instead of freezing the ring.
+package script
+
+import (
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+
+	"github.com/cgxeiji/ring"
+)
+
+// MaxSteps is the number of Starlark steps that a script may run to load, and
+// then to paint each frame.
+const MaxSteps = 1000000
+
+// Effect is a layer painted by a script. Add it to a ring with AddLayer, and
+// play it with the animator of the ring (see ring.Ring.Animator) to advance
+// it:
+//
+//	e, err := script.Load("aurora.star", &ring.LayerOptions{Resolution: 24})
+//	...
+//	r.AddLayer(e)
+//	r.Animator().Play(e)
+type Effect struct {
+	*ring.Layer
+
+	mu     sync.Mutex
+	name   string
+	draw   starlark.Callable
+	pixels map[int]color.Color // pixels painted by the current frame
+	t      time.Duration
+	err    error
+}
+
+// Load creates an effect from a script file.
+func Load(path string, options *ring.LayerOptions) (*Effect, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("script: could not read script: %w", err)
+	}
+
+	return New(filepath.Base(path), src, options)
+}
+
+// New creates an effect from the source of a script, and paints its first
+// frame. The name identifies the script in errors.
+func New(name string, src []byte, options *ring.LayerOptions) (*Effect, error) {
+	l, err := ring.NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Effect{
+		Layer:  l,
+		name:   name,
+		pixels: make(map[int]color.Color),
+	}
+	globals, err := starlark.ExecFile(e.newThread(), name, src, e.predeclared())
+	if err != nil {
+		return nil, fmt.Errorf("script: %w", err)
+	}
+	draw, ok := globals["draw"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script: %s does not define draw(t)", name)
+	}
+	e.draw = draw
+	if err := e.paint(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Tick advances the effect by dt and paints the next frame. If the script
+// fails, the effect stops (see Err).
+func (e *Effect) Tick(dt time.Duration) bool {
+	e.mu.Lock()
+	e.t += dt
+	e.mu.Unlock()
+
+	return e.paint() != nil
+}
+
+// Err returns the error of the script that stopped the effect, if any.
+func (e *Effect) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+// paint calls draw(t) and sets the pixels painted by it at once.
+func (e *Effect) paint() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return e.err
+	}
+	for i := range e.pixels {
+		delete(e.pixels, i)
+	}
+	t := starlark.Float(e.t.Seconds())
+	if _, err := starlark.Call(e.newThread(), e.draw, starlark.Tuple{t}, nil); err != nil {
+		e.err = fmt.Errorf("script: %w", err)
+		return e.err
+	}
+	if len(e.pixels) != 0 {
+		e.SetPixels(e.pixels)
+	}
+
+	return nil
+}
+
+// newThread returns a thread that runs the script for at most MaxSteps.
+func (e *Effect) newThread() *starlark.Thread {
+	thread := &starlark.Thread{Name: e.name}
+	thread.SetMaxExecutionSteps(MaxSteps)
+
+	return thread
+}
+
+// predeclared returns the names that scripts can use.
+func (e *Effect) predeclared() starlark.StringDict {
+	size := e.Options().Resolution
+
+	return starlark.StringDict{
+		"size": starlark.MakeInt(size),
+		"set_pixel": starlark.NewBuiltin("set_pixel", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var i int
+			var v starlark.Value
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &i, &v); err != nil {
+				return nil, err
+			}
+			if i < 0 || i >= size {
+				return nil, fmt.Errorf("%s: pixel %d out of range [0, %d)", b.Name(), i, size)
+			}
+			c, err := toColor(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			e.pixels[i] = c
+			return starlark.None, nil
+		}),
+		"set_all": starlark.NewBuiltin("set_all", func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			var v starlark.Value
+			if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &v); err != nil {
+				return nil, err
+			}
+			c, err := toColor(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			for i := 0; i < size; i++ {
+				e.pixels[i] = c
+			}
+			return starlark.None, nil
+		}),
+		"palette": starlark.NewBuiltin("palette", paletteBuiltin),
+		"hsv":     starlark.NewBuiltin("hsv", hsvBuiltin),
+		"sin":     mathBuiltin("sin", math.Sin),
+		"cos":     mathBuiltin("cos", math.Cos),
+		"pi":      starlark.Float(math.Pi),
+	}
+}
+
+func paletteBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if len(args) < 2 || len(kwargs) != 0 {
+		return nil, fmt.Errorf("%s: want a position and a palette or colors", b.Name())
+	}
+	x, ok := starlark.AsFloat(args[0])
+	if !ok {
+		return nil, fmt.Errorf("%s: position is %s, want a number", b.Name(), args[0].Type())
+	}
+
+	var p ring.Palette
+	if name, ok := args[1].(starlark.String); ok && len(args) == 2 {
+		p = ring.Palettes[string(name)]
+	}
+	if p == nil {
+		var colors []color.Color
+		for _, v := range args[1:] {
+			c, err := toColor(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", b.Name(), err)
+			}
+			colors = append(colors, c)
+		}
+		p = ring.NewPalette(colors...)
+	}
+
+	return fromColor(p.At(x)), nil
+}
+
+func hsvBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var h, s, v float64
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3, &h, &s, &v); err != nil {
+		return nil, err
+	}
+
+	h = (h - math.Floor(h)) * 6
+	f := h - math.Floor(h)
+	p, q, t := v*(1-s), v*(1-s*f), v*(1-s*(1-f))
+	var r, g, bl float64
+	switch int(h) {
+	case 0:
+		r, g, bl = v, t, p
+	case 1:
+		r, g, bl = q, v, p
+	case 2:
+		r, g, bl = p, v, t
+	case 3:
+		r, g, bl = p, q, v
+	case 4:
+		r, g, bl = t, p, v
+	default:
+		r, g, bl = v, p, q
+	}
+
+	return starlark.Tuple{starlark.Float(255 * r), starlark.Float(255 * g), starlark.Float(255 * bl)}, nil
+}
+
+// mathBuiltin wraps a function of a number as a builtin.
+func mathBuiltin(name string, f func(float64) float64) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var x float64
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &x); err != nil {
+			return nil, err
+		}
+		return starlark.Float(f(x)), nil
+	})
+}
+
+// toColor converts a color of a script, a string or a tuple of channels, to a
+// color.
+func toColor(v starlark.Value) (color.Color, error) {
+	switch v := v.(type) {
+	case starlark.String:
+		return ring.ParseColor(string(v))
+	case starlark.Tuple:
+		if len(v) != 3 && len(v) != 4 {
+			return nil, fmt.Errorf("color has %d channels, want 3 or 4", len(v))
+		}
+		ch := [4]uint8{0, 0, 0, 0xFF}
+		for i, x := range v {
+			f, ok := starlark.AsFloat(x)
+			if !ok {
+				return nil, fmt.Errorf("color channel is %s, want a number", x.Type())
+			}
+			ch[i] = uint8(math.Round(math.Max(0, math.Min(255, f))))
+		}
+		return color.NRGBA{ch[0], ch[1], ch[2], ch[3]}, nil
+	default:
+		return nil, fmt.Errorf("color is %s, want a string or a tuple", v.Type())
+	}
+}
+
+// fromColor converts a color to a tuple (r, g, b, a) of a script.
+func fromColor(c color.Color) starlark.Value {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	return starlark.Tuple{
+		starlark.MakeInt(int(n.R)),
+		starlark.MakeInt(int(n.G)),
+		starlark.MakeInt(int(n.B)),
+		starlark.MakeInt(int(n.A)),
+	}
+}
@@ -0,0 +1,93 @@
+package script
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+func TestEffect(t *testing.T) {
+	src := `
+def draw(t):
+    set_all((0, 0, 0))
+    set_pixel(int(t) % size, "red")
+    set_pixel(3, palette(0.5, "#000000", (0, 0, 254)))
+`
+	e, err := New("test.star", []byte(src), &ring.LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dt   time.Duration
+		want []color.Color
+	}{
+		{0, []color.Color{
+			color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x7F, 0xFF},
+		}},
+		{time.Second, []color.Color{
+			color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			color.RGBA{0x00, 0x00, 0x7F, 0xFF},
+		}},
+	}
+
+	for _, ts := range tests {
+		if done := e.Tick(ts.dt); done {
+			t.Fatalf("got: done after %v, want: running (%v)", ts.dt, e.Err())
+		}
+		for i, want := range ts.want {
+			if got := color.RGBAModel.Convert(e.Pixel(i)); got != want {
+				t.Errorf("after %v, pixel %d got: %#v, want: %#v", ts.dt, i, got, want)
+			}
+		}
+	}
+}
+
+func TestEffectErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"syntax", "def draw(t)", "want ':'"},
+		{"no draw", "x = 1", "does not define draw(t)"},
+		{"out of range", "def draw(t):\n    set_pixel(4, 'red')", "out of range"},
+		{"invalid color", "def draw(t):\n    set_all('nope')", "invalid color"},
+		{"channels", "def draw(t):\n    set_all((1, 2))", "2 channels"},
+		{"endless", "def draw(t):\n    for i in range(1 << 40):\n        pass", "too many steps"},
+	}
+
+	for _, ts := range tests {
+		_, err := New(ts.name, []byte(ts.src), &ring.LayerOptions{Resolution: 4})
+		if err == nil || !strings.Contains(err.Error(), ts.want) {
+			t.Errorf("%s got: %v, want: error with %q", ts.name, err, ts.want)
+		}
+	}
+}
+
+func TestEffectStopsOnError(t *testing.T) {
+	src := `
+def draw(t):
+    if t > 0.5:
+        fail("boom")
+    set_all("blue")
+`
+	e, err := New("test.star", []byte(src), &ring.LayerOptions{Resolution: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done := e.Tick(time.Second); !done {
+		t.Errorf("got: running, want: done")
+	}
+	if err := e.Err(); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("got: %v, want: error with %q", err, "boom")
+	}
+}
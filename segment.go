@@ -0,0 +1,111 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Segment is a view of an arc of the ring that behaves like a layer with one
+// pixel per LED of the arc. Add it to the ring with AddLayer.
+type Segment struct {
+	layer *Layer
+	start int // first LED of the arc
+	size  int // number of LEDs of the arc
+}
+
+// Segment creates a transparent view of the arc of the ring between two
+// angles (in radians). Angles are measured from the first LED in the direction
+// of the LED indices, and the arc includes every LED at or after startAngle
+// and before endAngle. It returns an error if an angle is NaN or infinite.
+func (r *Ring) Segment(startAngle, endAngle float64) (*Segment, error) {
+	for _, angle := range []float64{startAngle, endAngle} {
+		if math.IsNaN(angle) || math.IsInf(angle, 0) {
+			return nil, fmt.Errorf("ring: invalid segment angle %v", angle)
+		}
+	}
+	// Arcs wider than a full turn are clamped to the whole ring, so the angles
+	// can be reduced to a turn.
+	arc := endAngle - startAngle
+	if arc < 0 {
+		arc = math.Mod(arc, 2*math.Pi)
+		if arc < 0 {
+			arc += 2 * math.Pi
+		}
+	}
+	if arc > 2*math.Pi {
+		arc = 2 * math.Pi
+	}
+	startAngle = math.Mod(startAngle, 2*math.Pi)
+	endAngle = startAngle + arc
+
+	// Round before taking the ceiling, so angles that fall on an LED are not
+	// pushed to the next one by floating point errors.
+	ceil := func(angle float64) int {
+		return int(math.Ceil(math.Round(angle/r.ledArc*1e6) / 1e6))
+	}
+	start := ceil(startAngle)
+	end := ceil(endAngle)
+	size := end - start
+	if size > r.Size() {
+		size = r.Size()
+	}
+
+	l, err := NewLayer(&LayerOptions{
+		Resolution: r.Size(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Segment{
+		layer: l,
+		start: mod(start, r.Size()),
+		size:  size,
+	}, nil
+}
+
+// Size returns the number of pixels of the segment.
+func (s *Segment) Size() int {
+	return s.size
+}
+
+// SetAll sets all the pixels of the segment to an uniform color.
+func (s *Segment) SetAll(c color.Color) {
+	c64 := toRGBA64(c)
+
+	s.layer.mu.Lock()
+	defer s.layer.mu.Unlock()
+
+	for i := 0; i < s.size; i++ {
+		s.layer.pixels[mod(s.start+i, len(s.layer.pixels))] = c64
+	}
+	s.layer.update()
+}
+
+// SetPixel sets the color of a single pixel of the segment. Pixels outside of
+// the segment are ignored.
+func (s *Segment) SetPixel(i int, c color.Color) {
+	if i < 0 || i >= s.size {
+		return
+	}
+	s.layer.SetPixel(mod(s.start+i, len(s.layer.pixels)), c)
+}
+
+// Pixel returns the color of the LED at position i of the ring.
+func (s *Segment) Pixel(i int) color.Color {
+	return s.layer.Pixel(i)
+}
+
+// Options returns the options of the underlying layer.
+func (s *Segment) Options() *LayerOptions {
+	return s.layer.Options()
+}
+
+func (s *Segment) pixel64(i int) color.RGBA64 {
+	return s.layer.pixel64(i)
+}
+
+func (s *Segment) version() uint64 {
+	return s.layer.version()
+}
@@ -0,0 +1,59 @@
+package ring
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// signalFadeOut is how long HandleSignals fades out a ring without a shutdown
+// animation.
+const signalFadeOut = 500 * time.Millisecond
+
+// exit ends the program. It is replaced in tests.
+var exit = os.Exit
+
+// HandleSignals closes the ring and exits the program with status 0 once it
+// receives any of the signals, so the LEDs are not left lit when the program
+// is stopped:
+//
+//	r, err := ring.New(ring.WithLEDCount(12))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	ring.HandleSignals(r, os.Interrupt, syscall.SIGTERM)
+//
+// The ring plays its shutdown animation (see Options.ShutdownAnimation) or, if
+// it has none, fades out before closing. Without signals, it handles
+// os.Interrupt and syscall.SIGTERM. Call stop to stop handling the signals.
+func HandleSignals(r *Ring, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		select {
+		case sig := <-c:
+			r.opt.logger().Info("received signal, closing the ring", "signal", sig)
+			if r.opt.ShutdownAnimation == nil {
+				r.playPowerAnimation(FadeOut(signalFadeOut))
+			}
+			r.Close()
+			exit(0)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package ring
+
+import (
+	"image/color"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	codes := make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	defer func() { exit = os.Exit }()
+
+	dev := &lockedDevice{dev: fakeDevice{leds: make([]uint32, 2)}}
+	r := newRing(dev, &Options{LedCount: 2, MaxBrightness: 255, ShutdownAnimation: FadeOut(0)})
+	l := newTestLayer(t, &LayerOptions{Resolution: 2})
+	l.SetAll(color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := HandleSignals(r, os.Interrupt)
+	defer stop()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skip("could not send signal:", err)
+	}
+
+	select {
+	case code := <-codes:
+		if code != 0 {
+			t.Errorf("got: exit status %d, want: 0", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("program did not exit")
+	}
+	if got, want := dev.led(0), uint32(0); got != want {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package ring
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// spiBitsPerLedBit is the number of SPI bits used to encode each bit sent
+	// to the LEDs. A 1 is sent as 110 and a 0 as 100.
+	spiBitsPerLedBit = 3
+	// spiResetBytes is the number of low bytes sent after a frame to latch
+	// the colors, enough for the 280µs reset of newer WS2812B LEDs.
+	spiResetBytes = 90
+)
+
+// NewSPI creates a new LED ring with given options, driven through the MOSI
+// pin of an SPI device, such as "/dev/spidev0.0", instead of PWM. It does not
+// need root permissions, only access to the SPI device. Options that
+// configure the PWM output, such as GpioPin and DmaChannel, are ignored.
+//
+// The SPI device of a Raspberry Pi transfers at most 4096 bytes by default,
+// which fits 450 LEDs (or 337 with a white channel). For longer rings, set
+// spidev.bufsiz in /boot/cmdline.txt.
+func NewSPI(path string, options *Options) (*Ring, error) {
+	o := *options
+	o.Driver = SPI(path)
+	return New(&o)
+}
+
+// SPI returns a driver that drives the LEDs through the MOSI pin of an SPI
+// device, such as "/dev/spidev0.0". See NewSPI.
+func SPI(path string) Driver {
+	return spiDriver(path)
+}
+
+type spiDriver string
+
+func (path spiDriver) Open(options *Options) (Device, error) {
+	freq, err := spiFrequency(options)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(string(path), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, &DeviceError{"open SPI device", err}
+	}
+	if err := setSPISpeed(f, uint32(freq)); err != nil {
+		f.Close()
+		return nil, &DeviceError{"set SPI speed", err}
+	}
+
+	write := func(b []byte) error {
+		_, err := f.Write(b)
+		return err
+	}
+	return newSPIDevice(write, func() { f.Close() }, options), nil
+}
+
+// spiFrequency returns the SPI clock frequency, in Hz, that encodes the
+// output frequency of the options.
+func spiFrequency(options *Options) (int, error) {
+	freq := options.Frequency
+	if freq == 0 {
+		freq = 800000
+	}
+	if freq != 400000 && freq != 800000 {
+		return 0, fmt.Errorf("ring: frequency must be 400000 or 800000 Hz, got %d", freq)
+	}
+
+	return freq * spiBitsPerLedBit, nil
+}
+
+// spiDevice is a device that encodes frames as an SPI bit stream.
+type spiDevice struct {
+	write func([]byte) error
+	close func()
+	order StripType
+	buf   []byte
+}
+
+func newSPIDevice(write func([]byte) error, close func(), options *Options) *spiDevice {
+	order := options.StripType
+	if order == 0 {
+		order = StripGRB
+	}
+
+	return &spiDevice{
+		write: write,
+		close: close,
+		order: order,
+		buf:   make([]byte, 0, options.LedCount*order.bytes()*spiBitsPerLedBit+spiResetBytes),
+	}
+}
+
+func (d *spiDevice) Render(frame []uint32) error {
+	d.buf = encodeSPI(d.buf[:0], frame, d.order)
+	return d.write(d.buf)
+}
+
+func (d *spiDevice) Close() {
+	d.close()
+}
+
+// bytes returns the number of color bytes sent to each LED.
+func (t StripType) bytes() int {
+	if t>>24 != 0 {
+		return 4
+	}
+	return 3
+}
+
+// encodeSPI appends the SPI bit stream of a frame of 0x00RRGGBB colors to
+// buf, in the color order of the strip type, followed by the reset signal.
+// As in the ws2811 driver, the shifts of the strip type select the byte of
+// the color sent first (red shift), second (green shift), third (blue shift)
+// and, if any, fourth (white shift).
+func encodeSPI(buf []byte, frame []uint32, order StripType) []byte {
+	shifts := []uint{
+		uint(order>>16) & 0xFF,
+		uint(order>>8) & 0xFF,
+		uint(order) & 0xFF,
+		uint(order>>24) & 0xFF,
+	}[:order.bytes()]
+
+	for _, c := range frame {
+		for _, shift := range shifts {
+			buf = appendSPIByte(buf, byte(c>>shift))
+		}
+	}
+	for i := 0; i < spiResetBytes; i++ {
+		buf = append(buf, 0)
+	}
+
+	return buf
+}
+
+// appendSPIByte appends the 3 SPI bytes that encode a byte sent to the LEDs,
+// most significant bit first.
+func appendSPIByte(buf []byte, b byte) []byte {
+	var bits uint32
+	for i := 7; i >= 0; i-- {
+		bits <<= spiBitsPerLedBit
+		if b>>uint(i)&1 == 1 {
+			bits |= 0x6 // 110
+		} else {
+			bits |= 0x4 // 100
+		}
+	}
+
+	return append(buf, byte(bits>>16), byte(bits>>8), byte(bits))
+}
@@ -0,0 +1,18 @@
+package ring
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// spiIocWrMaxSpeedHz is the SPI_IOC_WR_MAX_SPEED_HZ ioctl of spidev.
+const spiIocWrMaxSpeedHz = 0x40046b04
+
+func setSPISpeed(f *os.File, hz uint32) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), spiIocWrMaxSpeedHz, uintptr(unsafe.Pointer(&hz)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
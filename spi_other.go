@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package ring
+
+import (
+	"errors"
+	"os"
+)
+
+func setSPISpeed(f *os.File, hz uint32) error {
+	return errors.New("SPI is only supported on Linux")
+}
@@ -0,0 +1,106 @@
+package ring
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"periph.io/x/conn/v3/spi/spitest"
+)
+
+func TestEncodeSPI(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []uint32
+		order StripType
+		want  []byte
+	}{
+		{
+			"grb",
+			[]uint32{0xFF0000},
+			StripGRB,
+			[]byte{
+				0x92, 0x49, 0x24, // G: 0x00
+				0xDB, 0x6D, 0xB6, // R: 0xFF
+				0x92, 0x49, 0x24, // B: 0x00
+			},
+		},
+		{
+			"rgb",
+			[]uint32{0xFF0000},
+			StripRGB,
+			[]byte{
+				0xDB, 0x6D, 0xB6,
+				0x92, 0x49, 0x24,
+				0x92, 0x49, 0x24,
+			},
+		},
+		{
+			"grbw",
+			[]uint32{0x0000A5},
+			StripGRBW,
+			[]byte{
+				0x92, 0x49, 0x24, // G: 0x00
+				0x92, 0x49, 0x24, // R: 0x00
+				0xD3, 0x49, 0xA6, // B: 0xA5
+				0x92, 0x49, 0x24, // W: 0x00
+			},
+		},
+		{
+			"gbr",
+			[]uint32{0xFF00A5},
+			StripGBR,
+			[]byte{
+				0x92, 0x49, 0x24, // G: 0x00
+				0xD3, 0x49, 0xA6, // B: 0xA5
+				0xDB, 0x6D, 0xB6, // R: 0xFF
+			},
+		},
+		{
+			"brg",
+			[]uint32{0xFF00A5},
+			StripBRG,
+			[]byte{
+				0xD3, 0x49, 0xA6, // B: 0xA5
+				0xDB, 0x6D, 0xB6, // R: 0xFF
+				0x92, 0x49, 0x24, // G: 0x00
+			},
+		},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			got := encodeSPI(nil, ts.frame, ts.order)
+			want := append(ts.want, make([]byte, spiResetBytes)...)
+			if !bytes.Equal(got, want) {
+				t.Errorf("got: %#v, want: %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestPeriphSPI(t *testing.T) {
+	var out bytes.Buffer
+	r, err := New(&Options{
+		LedCount:      1,
+		MaxBrightness: 255,
+		Driver:        PeriphSPI(spitest.NewRecordRaw(&out)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewLayer(&LayerOptions{Resolution: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetAll(color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := encodeSPI(nil, []uint32{0xFF0000}, StripGRB)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Sprite is an arc of color with a position and a velocity that can be drawn
+// on a layer with sub-pixel precision, as a building block for effects such
+// as comets, scanners and bouncing dots. A sprite is an Animation that moves
+// at its velocity and never finishes.
+type Sprite struct {
+	mu       sync.Mutex
+	pos      float64 // angle of the center in radians
+	width    float64 // angular width in radians
+	velocity float64 // angular velocity in radians per second
+	friction float64 // velocity lost per second, relative to the velocity
+
+	spring             bool    // pulled towards target
+	target             float64 // target angle of the spring in radians
+	stiffness, damping float64
+	color              color.Color
+	palette            Palette
+}
+
+// NewSprite creates a sprite of a color with an angular width (in radians),
+// at angle 0.
+func NewSprite(width float64, c color.Color) *Sprite {
+	return &Sprite{
+		width: width,
+		color: c,
+	}
+}
+
+// SetColor paints the sprite with a single color.
+func (s *Sprite) SetColor(c color.Color) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.color = c
+	s.palette = nil
+}
+
+// SetPalette paints the sprite with a gradient, from the start of the sprite
+// (0.0) to its end (1.0) in the direction of the pixel indices.
+func (s *Sprite) SetPalette(p Palette) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.palette = p
+}
+
+// SetWidth sets the angular width of the sprite (in radians).
+func (s *Sprite) SetWidth(width float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.width = width
+}
+
+// MoveTo moves the center of the sprite to an angle (in radians), measured
+// from the first pixel in the direction of the pixel indices.
+func (s *Sprite) MoveTo(angle float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pos = math.Mod(angle, 2*math.Pi)
+}
+
+// Position returns the angle of the center of the sprite.
+func (s *Sprite) Position() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pos
+}
+
+// SetVelocity sets the angular velocity of the sprite (in radians per
+// second), in the direction of the pixel indices.
+func (s *Sprite) SetVelocity(velocity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.velocity = velocity
+}
+
+// Velocity returns the angular velocity of the sprite.
+func (s *Sprite) Velocity() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.velocity
+}
+
+// SetFriction slows the sprite down over time. A friction of 1 loses about
+// 63% of the velocity every second, and 0 keeps the velocity (default).
+func (s *Sprite) SetFriction(friction float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.friction = friction
+}
+
+// SpringTo pulls the sprite towards an angle (in radians) with a spring, by
+// the shortest way around the ring. The stiffness sets how strongly the
+// sprite is pulled, and the damping how fast it settles. Low damping makes
+// the sprite overshoot and oscillate around the angle, like a needle.
+func (s *Sprite) SpringTo(angle, stiffness, damping float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.spring = true
+	s.target = angle
+	s.stiffness = stiffness
+	s.damping = damping
+}
+
+// ReleaseSpring stops pulling the sprite, which keeps moving at its velocity.
+func (s *Sprite) ReleaseSpring() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.spring = false
+}
+
+// maxPhysicsStep is the longest step of the simulation of the motion of a
+// sprite, to keep springs stable at low frame rates.
+const maxPhysicsStep = 5 * time.Millisecond
+
+// Tick moves the sprite by the angle covered at its velocity in dt, applying
+// its friction and spring.
+func (s *Sprite) Tick(dt time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dt > 0 {
+		step := dt
+		if step > maxPhysicsStep {
+			step = maxPhysicsStep
+		}
+		dt -= step
+		h := step.Seconds()
+
+		if s.spring {
+			diff := math.Remainder(s.target-s.pos, 2*math.Pi)
+			s.velocity += (s.stiffness*diff - s.damping*s.velocity) * h
+		}
+		if s.friction > 0 {
+			s.velocity *= math.Exp(-s.friction * h)
+		}
+		s.pos = math.Mod(s.pos+s.velocity*h, 2*math.Pi)
+	}
+
+	return false
+}
+
+// Draw paints the sprite over the pixels of a layer.
+func (s *Sprite) Draw(l *Layer) {
+	s.mu.Lock()
+	from := (s.pos - s.width/2) / l.pixArc
+	to := (s.pos + s.width/2) / l.pixArc
+	paint := solid(s.color)
+	if s.palette != nil {
+		paint = s.palette.at64
+	}
+	s.mu.Unlock()
+
+	l.drawSpan(from, to, paint)
+}
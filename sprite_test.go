@@ -0,0 +1,57 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSprite(t *testing.T) {
+	s := NewSprite(radians(90), color.White)
+	s.SetVelocity(radians(45))
+	s.Tick(3 * time.Second)
+
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	s.Draw(l)
+	for i, want := range []uint16{0, 0x7FFF, 0x7FFF, 0} {
+		if got := int(l.pixelRaw(i).A); got < int(want)-1 || got > int(want)+1 {
+			t.Errorf("pixel %d got: %#x, want: %#x", i, got, want)
+		}
+	}
+
+	l.SetAll(color.Transparent)
+	s.SetPalette(NewPalette(color.RGBA{0xFF, 0, 0, 0xFF}, color.RGBA{0, 0, 0xFF, 0xFF}))
+	s.SetWidth(radians(180))
+	s.Draw(l)
+	if a, b := l.pixelRaw(1), l.pixelRaw(2); !(a.R > a.B && b.B > b.R) {
+		t.Errorf("got: %v, %v, want: red to blue gradient", a, b)
+	}
+}
+
+func TestSpriteSpring(t *testing.T) {
+	s := NewSprite(0, color.White)
+	s.SpringTo(radians(-90), 100, 5)
+
+	overshoot := false
+	for i := 0; i < 300; i++ {
+		s.Tick(10 * time.Millisecond)
+		if math.Remainder(s.Position(), 2*math.Pi) < radians(-90) {
+			overshoot = true
+		}
+	}
+	if !overshoot {
+		t.Errorf("got: no overshoot, want: overshoot")
+	}
+	if got := math.Remainder(s.Position(), 2*math.Pi); math.Abs(got-radians(-90)) > 1e-3 {
+		t.Errorf("got: %v, want: %v", got, radians(-90))
+	}
+
+	s.ReleaseSpring()
+	s.SetVelocity(1)
+	s.SetFriction(1)
+	s.Tick(time.Second)
+	if got, want := s.Velocity(), math.Exp(-1); math.Abs(got-want) > 1e-3 {
+		t.Errorf("velocity got: %v, want: %v", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package ring
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+)
+
+// powerAnimationFPS is the frame rate of startup and shutdown animations.
+const powerAnimationFPS = 60
+
+// PowerAnimation is an animation played automatically when a ring starts or
+// closes (see Options.StartupAnimation and Options.ShutdownAnimation). It is
+// drawn on a layer with one pixel per LED, above all the other layers, which
+// is removed once the animation ends.
+type PowerAnimation struct {
+	// Duration is how long the animation plays. Animations with no duration
+	// draw their last frame at once.
+	Duration time.Duration
+	// Draw draws the frame of the animation at a progress t, from 0.0 to 1.0,
+	// on the layer.
+	Draw func(l *Layer, t float64)
+}
+
+// WipeIn lights the LEDs with a color one by one, from the first to the last.
+func WipeIn(c color.Color, d time.Duration) *PowerAnimation {
+	return &PowerAnimation{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			l.SetAll(color.Transparent)
+			l.drawSpan(-0.5, t*float64(l.opt.Resolution)-0.5, solid(c))
+		},
+	}
+}
+
+// WipeOut turns off the LEDs one by one, from the first to the last.
+func WipeOut(d time.Duration) *PowerAnimation {
+	return &PowerAnimation{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			l.SetAll(color.Transparent)
+			l.drawSpan(-0.5, t*float64(l.opt.Resolution)-0.5, solid(color.Black))
+		},
+	}
+}
+
+// FadeIn lights all the LEDs with a color gradually.
+func FadeIn(c color.Color, d time.Duration) *PowerAnimation {
+	return &PowerAnimation{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			l.SetAll(c)
+			l.SetOpacity(t)
+		},
+	}
+}
+
+// FadeOut turns off all the LEDs gradually.
+func FadeOut(d time.Duration) *PowerAnimation {
+	return &PowerAnimation{
+		Duration: d,
+		Draw: func(l *Layer, t float64) {
+			l.SetAll(color.Black)
+			l.SetOpacity(t)
+		},
+	}
+}
+
+// playPowerAnimation renders an animation on the ring, timed by the clock of
+// the ring, and returns once it ends. A nil animation does nothing.
+func (r *Ring) playPowerAnimation(a *PowerAnimation) error {
+	if a == nil || a.Draw == nil {
+		return nil
+	}
+
+	l, err := NewLayer(&LayerOptions{Resolution: r.Size()})
+	if err != nil {
+		return err
+	}
+	r.AddLayer(l)
+	defer r.RemoveLayer(l)
+
+	clock := r.opt.clock()
+	ticker := clock.NewTicker(time.Second / powerAnimationFPS)
+	defer ticker.Stop()
+
+	start := clock.Now()
+	now := start
+	for {
+		t := 1.0
+		if a.Duration > 0 && now.Sub(start) < a.Duration {
+			t = float64(now.Sub(start)) / float64(a.Duration)
+		}
+		a.Draw(l, t)
+		if err := r.Render(); err != nil {
+			return fmt.Errorf("ring: could not play animation: %w", err)
+		}
+		if t == 1 {
+			return nil
+		}
+		now = <-ticker.C()
+	}
+}
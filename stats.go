@@ -0,0 +1,52 @@
+package ring
+
+import "time"
+
+// Stats are the statistics of the frames rendered by Run.
+type Stats struct {
+	// FPS is the recent number of frames rendered per second.
+	FPS float64
+	// Frames is the number of frames rendered.
+	Frames uint64
+	// Dropped is the number of frames missed because the previous frames took
+	// longer than the frame period.
+	Dropped uint64
+	// RenderTime is the recent average duration of a frame, including
+	// advancing the animations and rendering the layers.
+	RenderTime time.Duration
+}
+
+// statsSmoothing is the weight of the last frame in the recent averages of
+// the statistics.
+const statsSmoothing = 0.1
+
+// Stats returns the statistics of the frames rendered by Run.
+func (r *Ring) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// recordFrame updates the statistics with a frame that started an interval
+// after the previous one, and took a duration to render, for a frame period.
+func (r *Ring) recordFrame(interval, duration, period time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &r.stats
+	if missed := int64(interval/period) - 1; missed > 0 && s.Frames > 0 {
+		s.Dropped += uint64(missed)
+	}
+	if s.Frames == 0 {
+		s.FPS = float64(time.Second) / float64(period)
+		s.RenderTime = duration
+	} else {
+		if interval > 0 {
+			fps := float64(time.Second) / float64(interval)
+			s.FPS += statsSmoothing * (fps - s.FPS)
+		}
+		s.RenderTime += time.Duration(statsSmoothing * float64(duration-s.RenderTime))
+	}
+	s.Frames++
+}
@@ -0,0 +1,31 @@
+package ring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	r, _ := newTestRing(t, 1)
+	period := 10 * time.Millisecond
+
+	r.recordFrame(period, 2*time.Millisecond, period)
+	for i := 0; i < 50; i++ {
+		r.recordFrame(period, 2*time.Millisecond, period)
+	}
+	r.recordFrame(3*period, 2*time.Millisecond, period)
+
+	s := r.Stats()
+	if s.Frames != 52 {
+		t.Errorf("frames got: %d, want: %d", s.Frames, 52)
+	}
+	if s.Dropped != 2 {
+		t.Errorf("dropped got: %d, want: %d", s.Dropped, 2)
+	}
+	if s.RenderTime != 2*time.Millisecond {
+		t.Errorf("render time got: %v, want: %v", s.RenderTime, 2*time.Millisecond)
+	}
+	if s.FPS < 80 || s.FPS > 100 {
+		t.Errorf("fps got: %v, want: about 100", s.FPS)
+	}
+}
@@ -0,0 +1,108 @@
+package ring
+
+import "context"
+
+// Strip represents a linear LED strip. It uses the same layers as a Ring, but
+// its content scrolls instead of rotating.
+type Strip struct {
+	ring *Ring
+}
+
+// ScrollMode defines what is shown at the ends of a scrolled strip.
+type ScrollMode uint8
+
+const (
+	// ScrollClamp shows the first or last pixel of the content at the ends of
+	// the strip, without repeating the content (default).
+	ScrollClamp ScrollMode = iota
+	// ScrollWrap repeats the content, as if the strip were a ring.
+	ScrollWrap
+)
+
+// NewStrip creates a new LED strip with given options.
+func NewStrip(options *Options) (*Strip, error) {
+	r, err := New(options)
+	if err != nil {
+		return nil, err
+	}
+	s := &Strip{ring: r}
+	s.SetScrollMode(ScrollClamp)
+
+	return s, nil
+}
+
+// Scroll moves the content of the strip by a number of pixels. A positive
+// value moves the content towards the start of the strip.
+func (s *Strip) Scroll(pixels float64) {
+	s.ring.mu.Lock()
+	defer s.ring.mu.Unlock()
+
+	s.ring.offset = pixels
+	s.ring.dirty = true
+}
+
+// SetScrollMode sets what is shown at the ends of the strip when scrolled.
+func (s *Strip) SetScrollMode(mode ScrollMode) {
+	s.ring.mu.Lock()
+	defer s.ring.mu.Unlock()
+
+	s.ring.clamp = mode == ScrollClamp
+	s.ring.dirty = true
+}
+
+// Render updates the LED strip.
+func (s *Strip) Render() error {
+	return s.ring.Render()
+}
+
+// Run renders the strip at a fixed number of frames per second until ctx is
+// done (see Ring.Run).
+func (s *Strip) Run(ctx context.Context, fps float64) error {
+	return s.ring.Run(ctx, fps)
+}
+
+// Animator returns the animator of the strip, advanced while it runs.
+func (s *Strip) Animator() *Animator {
+	return s.ring.Animator()
+}
+
+// AddLayer adds a layer on top of the layers of the strip.
+func (s *Strip) AddLayer(l Pixeler) {
+	s.ring.AddLayer(l)
+}
+
+// RemoveLayer removes a layer from the strip.
+func (s *Strip) RemoveLayer(l Pixeler) {
+	s.ring.RemoveLayer(l)
+}
+
+// Layers returns the layers of the strip, from bottom to top.
+func (s *Strip) Layers() []Pixeler {
+	return s.ring.Layers()
+}
+
+// Size returns the number of LEDs of the strip.
+func (s *Strip) Size() int {
+	return s.ring.Size()
+}
+
+// Close turns off the LED strip and closes the device.
+func (s *Strip) Close() {
+	s.ring.Close()
+}
+
+// TurnOff turns off the LED strip without closing the device.
+func (s *Strip) TurnOff() {
+	s.ring.TurnOff()
+}
+
+// SetBrightness sets the maximum output of the LEDs, from 0 to 255, without
+// reinitializing the device.
+func (s *Strip) SetBrightness(level int) {
+	s.ring.SetBrightness(level)
+}
+
+// Brightness returns the maximum output of the LEDs.
+func (s *Strip) Brightness() int {
+	return s.ring.Brightness()
+}
@@ -0,0 +1,63 @@
+package ring
+
+import "time"
+
+// SyncToClock locks the animations of the ring to the wall clock, so rings
+// running the same scene on different computers animate in lockstep without
+// any network between them, as long as their clocks are synchronized, such as
+// with NTP. The animations must repeat every period, such as a spin of 90
+// degrees per second with a period of 4 seconds.
+//
+// While synced, Run advances the animations to the phase of the current time,
+// the time since the Unix epoch modulo period, instead of by the time between
+// frames, so the animations catch up after slow frames, clock corrections and
+// Pause. The time scale is ignored. Animations are assumed to be at their
+// start when the ring is synced; effects added later with AddEffect or
+// Scene.AddTo are advanced to the current phase. A period of 0 stops syncing.
+func (r *Ring) SyncToClock(period time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if period < 0 {
+		period = 0
+	}
+	r.syncPeriod = period
+	r.phase = 0
+}
+
+// phaseAt returns the phase of a time in a period, from the Unix epoch.
+func phaseAt(t time.Time, period time.Duration) time.Duration {
+	return time.Duration(t.UnixNano() % int64(period))
+}
+
+// step returns how far to advance the animations of the ring on a frame at
+// time now, an interval after the previous frame. When synced, it is how far
+// the phase of the clock moved since the previous frame, and 0 while paused.
+func (r *Ring) step(now time.Time, interval time.Duration) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.syncPeriod == 0 {
+		return interval
+	}
+	if r.paused {
+		return 0
+	}
+	target := phaseAt(now, r.syncPeriod)
+	dt := (target - r.phase + r.syncPeriod) % r.syncPeriod
+	r.phase = target
+
+	return dt
+}
+
+// syncEffect advances an effect added while the ring is synced to the current
+// phase.
+func (r *Ring) syncEffect(e interface{}) {
+	r.mu.Lock()
+	phase := r.phase
+	r.mu.Unlock()
+
+	if phase != 0 {
+		advanceEffect(e, phase)
+	}
+}
@@ -0,0 +1,52 @@
+package ring
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSyncToClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	angle := func(l *Layer) float64 {
+		return math.Mod(math.Mod(l.angle, 2*math.Pi)+2*math.Pi, 2*math.Pi)
+	}
+
+	var layers []*Layer
+	for i, frame := range []time.Duration{16 * time.Millisecond, 33 * time.Millisecond} {
+		r, _ := newTestRing(t, 4)
+		r.SyncToClock(4 * time.Second)
+		r.SetTimeScale(3)
+		l := newTestLayer(t, &LayerOptions{Resolution: 4})
+		l.Spin(math.Pi / 2)
+		r.AddEffect(l)
+		layers = append(layers, l)
+
+		// The rings start at different times, with different frame rates,
+		// and the second one is paused for a while.
+		now := start.Add(time.Duration(i) * 1300 * time.Millisecond)
+		last := now
+		for end := start.Add(10 * time.Second); now.Before(end); now = now.Add(frame) {
+			if i == 1 && now.Sub(start) > 5*time.Second && now.Sub(start) < 6*time.Second {
+				r.Pause()
+			} else {
+				r.Resume()
+			}
+			r.advance(r.step(now, now.Sub(last)))
+			last = now
+		}
+		r.advance(r.step(start.Add(10*time.Second), now.Sub(last)))
+
+		late := newTestLayer(t, &LayerOptions{Resolution: 4})
+		late.Spin(math.Pi / 2)
+		r.AddEffect(late)
+		layers = append(layers, late)
+	}
+
+	// 10 seconds from a multiple of the period: half a turn.
+	for i, l := range layers {
+		if got, want := angle(l), math.Pi; math.Abs(got-want) > 1e-6 {
+			t.Errorf("layer %d got: %v, want: %v", i, got, want)
+		}
+	}
+}
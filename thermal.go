@@ -0,0 +1,192 @@
+package ring
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultThermalZone is the file with the temperature of the SoC of a
+// Raspberry Pi, in millidegrees Celsius.
+const DefaultThermalZone = "/sys/class/thermal/thermal_zone0/temp"
+
+// ThermalOptions is the list of options of a thermal monitor.
+type ThermalOptions struct {
+	// Path is the file with the temperature, in millidegrees Celsius
+	// (default: DefaultThermalZone).
+	Path string
+	// Threshold is the temperature, in degrees Celsius, above which the
+	// brightness of the ring is reduced (default: 70).
+	Threshold float64
+	// Hysteresis is how much the temperature, in degrees Celsius, must drop
+	// below Threshold before the brightness is restored, so the brightness
+	// does not flicker around the threshold (default: 5).
+	Hysteresis float64
+	// Scale is the factor applied to the brightness of the ring while the
+	// temperature is above the threshold, from 0.0 to 1.0 (default: 0.5).
+	Scale float64
+	// Interval is the time between readings of the temperature (default: 5
+	// seconds).
+	Interval time.Duration
+	// OnChange is called when the brightness is reduced or restored, with the
+	// temperature that caused it (default: nil).
+	OnChange func(throttled bool, celsius float64)
+}
+
+// ThermalMonitor reduces the brightness of a ring while the SoC of the
+// Raspberry Pi is too hot, as enclosed rings lit at full white heat up fast.
+type ThermalMonitor struct {
+	r   *Ring
+	opt ThermalOptions
+
+	mu        sync.Mutex // guards celsius, throttled and bright
+	celsius   float64    // last temperature read
+	throttled bool       // brightness is reduced
+	bright    int        // brightness of the ring before throttling
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewThermalMonitor starts reading the temperature periodically, timed by the
+// clock of the ring (see Options.Clock), and reducing the brightness of the
+// ring while it is above the threshold. Changes to the brightness of the ring
+// while it is reduced are overwritten once it is restored. Call Stop before
+// closing the ring.
+func NewThermalMonitor(r *Ring, options *ThermalOptions) *ThermalMonitor {
+	m := newThermalMonitor(r, options)
+	go m.run()
+
+	return m
+}
+
+// newThermalMonitor creates a monitor with the defaults of the options, without
+// starting it.
+func newThermalMonitor(r *Ring, options *ThermalOptions) *ThermalMonitor {
+	m := &ThermalMonitor{
+		r:    r,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if options != nil {
+		m.opt = *options
+	}
+	if m.opt.Path == "" {
+		m.opt.Path = DefaultThermalZone
+	}
+	if m.opt.Threshold == 0 {
+		m.opt.Threshold = 70
+	}
+	if m.opt.Hysteresis == 0 {
+		m.opt.Hysteresis = 5
+	}
+	if m.opt.Scale == 0 {
+		m.opt.Scale = 0.5
+	}
+	if m.opt.Interval <= 0 {
+		m.opt.Interval = 5 * time.Second
+	}
+
+	return m
+}
+
+func (m *ThermalMonitor) run() {
+	defer close(m.done)
+
+	clock := m.r.opt.clock()
+	ticker := clock.NewTicker(m.opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.check(); err != nil {
+			m.r.opt.logger().Warn("could not read temperature", "err", err)
+		}
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// check reads the temperature and throttles or restores the brightness of the
+// ring.
+func (m *ThermalMonitor) check() error {
+	celsius, err := readTemperature(m.opt.Path)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.celsius = celsius
+	changed := false
+	switch {
+	case !m.throttled && celsius > m.opt.Threshold:
+		m.throttled, changed = true, true
+		m.bright = m.r.Brightness()
+		m.r.SetBrightness(int(float64(m.bright) * m.opt.Scale))
+		m.r.opt.logger().Warn("ring too hot, reducing brightness", "celsius", celsius)
+	case m.throttled && celsius < m.opt.Threshold-m.opt.Hysteresis:
+		m.throttled, changed = false, true
+		m.r.SetBrightness(m.bright)
+		m.r.opt.logger().Info("ring cooled down, restoring brightness", "celsius", celsius)
+	}
+	throttled := m.throttled
+	m.mu.Unlock()
+
+	if changed && m.opt.OnChange != nil {
+		m.opt.OnChange(throttled, celsius)
+	}
+
+	return nil
+}
+
+// Stop stops reading the temperature, restores the brightness of the ring if
+// it is reduced, and returns once the monitor is stopped.
+func (m *ThermalMonitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.throttled {
+		m.throttled = false
+		m.r.SetBrightness(m.bright)
+	}
+}
+
+// Temperature returns the last temperature read, in degrees Celsius.
+func (m *ThermalMonitor) Temperature() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.celsius
+}
+
+// Throttled reports whether the brightness of the ring is reduced.
+func (m *ThermalMonitor) Throttled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.throttled
+}
+
+// readTemperature reads a temperature in millidegrees Celsius from a file, and
+// returns it in degrees Celsius.
+func readTemperature(path string) (float64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	milli, err := strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ring: invalid temperature in %s: %w", path, err)
+	}
+
+	return milli / 1000, nil
+}
@@ -0,0 +1,57 @@
+package ring
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestThermalMonitor(t *testing.T) {
+	f, err := ioutil.TempFile("", "temp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+
+	r, _ := newTestRing(t, 1)
+	r.SetBrightness(200)
+	var changes []bool
+	m := newThermalMonitor(r, &ThermalOptions{
+		Path:     f.Name(),
+		OnChange: func(throttled bool, celsius float64) { changes = append(changes, throttled) },
+	})
+
+	for _, ts := range []struct {
+		temp       string
+		brightness int
+	}{
+		{"60000\n", 200},
+		{"71500\n", 100},
+		{"67000\n", 100}, // within the hysteresis
+		{"64000\n", 200},
+	} {
+		if err := ioutil.WriteFile(f.Name(), []byte(ts.temp), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.check(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.Brightness(), ts.brightness; got != want {
+			t.Errorf("%q got: %d, want: %d", ts.temp, got, want)
+		}
+	}
+	if got, want := len(changes), 2; got != want {
+		t.Errorf("got: %d changes, want: %d", got, want)
+	}
+	if got, want := m.Temperature(), 64.0; got != want {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+
+	if err := ioutil.WriteFile(f.Name(), []byte("hot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.check(); err == nil {
+		t.Errorf("got: nil error, want: invalid temperature")
+	}
+}
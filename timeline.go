@@ -0,0 +1,205 @@
+package ring
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// Timeline is an animation that changes properties of layers and rings at
+// keyframes, easing the values in between. Play it with an Animator.
+type Timeline struct {
+	mu       sync.Mutex
+	tracks   []*track
+	loop     LoopMode
+	elapsed  time.Duration
+	duration time.Duration
+}
+
+// Keyframe is a value reached at a point in time of a timeline.
+type Keyframe struct {
+	// At is the time of the keyframe since the start of the timeline.
+	At time.Duration
+	// Value is the value of rotation, offset (in radians) and opacity
+	// tracks.
+	Value float64
+	// Color is the value of color tracks.
+	Color color.Color
+	// Easing sets how the value changes from the previous keyframe (default:
+	// Linear).
+	Easing Easing
+}
+
+// LoopMode defines what a timeline does after its last keyframe.
+type LoopMode uint8
+
+const (
+	// LoopNone finishes the timeline after the last keyframe (default).
+	LoopNone LoopMode = iota
+	// LoopRepeat restarts the timeline from the beginning.
+	LoopRepeat
+	// LoopPingPong plays the timeline backwards and forwards.
+	LoopPingPong
+)
+
+// track is a list of keyframes that change a property.
+type track struct {
+	keys  []Keyframe
+	value func(k Keyframe)               // sets the property at a keyframe
+	lerp  func(a, b Keyframe, t float64) // sets the property between keyframes
+}
+
+// NewTimeline creates an empty timeline.
+func NewTimeline() *Timeline {
+	return &Timeline{}
+}
+
+// SetLoop sets what the timeline does after its last keyframe.
+func (tl *Timeline) SetLoop(mode LoopMode) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.loop = mode
+	return tl
+}
+
+// Rotation adds a track that rotates a layer (see Layer.Rotate).
+func (tl *Timeline) Rotation(l *Layer, keys ...Keyframe) *Timeline {
+	return tl.add(&track{
+		keys:  keys,
+		value: func(k Keyframe) { l.Rotate(k.Value) },
+		lerp:  func(a, b Keyframe, t float64) { l.Rotate(a.Value + (b.Value-a.Value)*t) },
+	})
+}
+
+// Opacity adds a track that changes the opacity of a layer (see
+// Layer.SetOpacity).
+func (tl *Timeline) Opacity(l *Layer, keys ...Keyframe) *Timeline {
+	return tl.add(&track{
+		keys:  keys,
+		value: func(k Keyframe) { l.SetOpacity(k.Value) },
+		lerp:  func(a, b Keyframe, t float64) { l.SetOpacity(a.Value + (b.Value-a.Value)*t) },
+	})
+}
+
+// Color adds a track that sets all the pixels of a layer to a color (see
+// Layer.SetAll).
+func (tl *Timeline) Color(l *Layer, keys ...Keyframe) *Timeline {
+	return tl.add(&track{
+		keys:  keys,
+		value: func(k Keyframe) { l.SetAll(k.Color) },
+		lerp: func(a, b Keyframe, t float64) {
+			l.SetAll(blendLerp(toRGBA64(a.Color), toRGBA64(b.Color), t))
+		},
+	})
+}
+
+// Offset adds a track that sets the offset of a ring (see Ring.Offset).
+func (tl *Timeline) Offset(r *Ring, keys ...Keyframe) *Timeline {
+	return tl.add(&track{
+		keys:  keys,
+		value: func(k Keyframe) { r.Offset(k.Value) },
+		lerp:  func(a, b Keyframe, t float64) { r.Offset(a.Value + (b.Value-a.Value)*t) },
+	})
+}
+
+func (tl *Timeline) add(t *track) *Timeline {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.tracks = append(tl.tracks, t)
+	if n := len(t.keys); n > 0 && t.keys[n-1].At > tl.duration {
+		tl.duration = t.keys[n-1].At
+	}
+	return tl
+}
+
+// Duration returns the time of the last keyframe of the timeline.
+func (tl *Timeline) Duration() time.Duration {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	return tl.duration
+}
+
+// Reset moves the timeline back to its beginning.
+func (tl *Timeline) Reset() {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	tl.elapsed = 0
+}
+
+// Tick advances the timeline by dt and applies the values of all its tracks.
+// It reports whether the timeline finished, which never happens if it loops.
+func (tl *Timeline) Tick(dt time.Duration) bool {
+	tl.mu.Lock()
+	tl.elapsed += dt
+	at, done := tl.position()
+	tracks := tl.tracks
+	tl.mu.Unlock()
+
+	for _, t := range tracks {
+		t.apply(at)
+	}
+
+	return done
+}
+
+func (tl *Timeline) overrun() time.Duration {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	if tl.loop != LoopNone || tl.elapsed < tl.duration {
+		return 0
+	}
+	return tl.elapsed - tl.duration
+}
+
+// position returns the time of the tracks to apply after the elapsed time,
+// following the loop mode, and whether the timeline finished.
+func (tl *Timeline) position() (time.Duration, bool) {
+	if tl.duration == 0 {
+		return 0, tl.loop == LoopNone
+	}
+
+	switch tl.loop {
+	case LoopRepeat:
+		return tl.elapsed % tl.duration, false
+	case LoopPingPong:
+		at := tl.elapsed % (2 * tl.duration)
+		if at > tl.duration {
+			at = 2*tl.duration - at
+		}
+		return at, false
+	default:
+		if tl.elapsed >= tl.duration {
+			return tl.duration, true
+		}
+		return tl.elapsed, false
+	}
+}
+
+// apply sets the property of the track to its value at a point in time.
+func (t *track) apply(at time.Duration) {
+	if len(t.keys) == 0 {
+		return
+	}
+	if at <= t.keys[0].At {
+		t.value(t.keys[0])
+		return
+	}
+	for i := 1; i < len(t.keys); i++ {
+		a, b := t.keys[i-1], t.keys[i]
+		if at >= b.At {
+			continue
+		}
+		ease := b.Easing
+		if ease == nil {
+			ease = Linear
+		}
+		t.lerp(a, b, ease(float64(at-a.At)/float64(b.At-a.At)))
+		return
+	}
+	t.value(t.keys[len(t.keys)-1])
+}
@@ -0,0 +1,46 @@
+package ring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	keys := []Keyframe{
+		{At: 0, Value: 0},
+		{At: time.Second, Value: 1},
+		{At: 2 * time.Second, Value: 0.5, Easing: EaseIn},
+	}
+
+	tests := []struct {
+		name  string
+		loop  LoopMode
+		ticks []time.Duration
+		want  float64
+		done  bool
+	}{
+		{"start", LoopNone, []time.Duration{0}, 0, false},
+		{"linear", LoopNone, []time.Duration{500 * time.Millisecond}, 0.5, false},
+		{"eased", LoopNone, []time.Duration{time.Second, 500 * time.Millisecond}, 0.875, false},
+		{"end", LoopNone, []time.Duration{3 * time.Second}, 0.5, true},
+		{"repeat", LoopRepeat, []time.Duration{2 * time.Second, 250 * time.Millisecond}, 0.25, false},
+		{"ping-pong", LoopPingPong, []time.Duration{2 * time.Second, 500 * time.Millisecond}, 0.875, false},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			l := newTestLayer(t, &LayerOptions{Resolution: 12})
+			tl := NewTimeline().Opacity(l, keys...).SetLoop(ts.loop)
+			var done bool
+			for _, dt := range ts.ticks {
+				done = tl.Tick(dt)
+			}
+			if got := l.Opacity(); got != ts.want {
+				t.Errorf("got: %v, want: %v", got, ts.want)
+			}
+			if done != ts.done {
+				t.Errorf("got done: %v, want: %v", done, ts.done)
+			}
+		})
+	}
+}
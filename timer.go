@@ -0,0 +1,173 @@
+package ring
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// Timer is a layer that counts down a duration, as an arc from the first pixel
+// that shrinks as the time elapses, such as a kitchen timer. Add it to a ring
+// with AddLayer, start it, and run the ring (see Ring.Run) to count down.
+type Timer struct {
+	*Layer
+
+	mu        sync.Mutex
+	duration  time.Duration
+	remaining time.Duration
+	running   bool
+	color     color.Color
+	palette   Palette       // colors of the arc along the ring, if any
+	warning   color.Color   // color near zero, if any
+	warnAt    time.Duration // remaining time below which warning is used
+	onDone    []func()
+}
+
+// NewTimer creates a stopped timer of a duration, with an arc of a color.
+func NewTimer(d time.Duration, c color.Color, options *LayerOptions) (*Timer, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Timer{
+		Layer:     l,
+		duration:  d,
+		remaining: d,
+		color:     c,
+	}
+	t.paint()
+
+	return t, nil
+}
+
+// SetColor sets the color of the arc.
+func (t *Timer) SetColor(c color.Color) {
+	t.mu.Lock()
+	t.color = c
+	t.mu.Unlock()
+
+	t.paint()
+}
+
+// SetPalette paints the arc with the colors of a palette along the ring, from
+// the first pixel to the last, instead of a single color. A nil palette
+// paints the arc with the color of the timer again.
+func (t *Timer) SetPalette(p Palette) {
+	t.mu.Lock()
+	t.palette = p
+	t.mu.Unlock()
+
+	t.paint()
+}
+
+// SetWarning changes the color of the arc to c once the remaining time is
+// below a duration, such as red for the last minute. A nil color removes the
+// warning.
+func (t *Timer) SetWarning(c color.Color, below time.Duration) {
+	t.mu.Lock()
+	t.warning = c
+	t.warnAt = below
+	t.mu.Unlock()
+
+	t.paint()
+}
+
+// OnDone calls f once the timer reaches zero. f is called from the goroutine
+// that advances the timer, usually Ring.Run, so it must not block.
+func (t *Timer) OnDone(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onDone = append(t.onDone, f)
+}
+
+// Start starts or resumes counting down. It does nothing if the timer reached
+// zero; reset it first.
+func (t *Timer) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running = t.remaining > 0
+}
+
+// Stop pauses the timer, keeping the remaining time.
+func (t *Timer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running = false
+}
+
+// Reset stops the timer and sets it to a duration.
+func (t *Timer) Reset(d time.Duration) {
+	t.mu.Lock()
+	t.duration = d
+	t.remaining = d
+	t.running = false
+	t.mu.Unlock()
+
+	t.paint()
+}
+
+// Remaining returns the time left before the timer reaches zero.
+func (t *Timer) Remaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.remaining
+}
+
+// Running reports whether the timer is counting down.
+func (t *Timer) Running() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.running
+}
+
+func (t *Timer) advance(dt time.Duration) {
+	t.mu.Lock()
+	var done []func()
+	if t.running {
+		t.remaining -= dt
+		if t.remaining <= 0 {
+			t.remaining = 0
+			t.running = false
+			done = t.onDone
+		}
+	}
+	t.mu.Unlock()
+
+	t.paint()
+	t.Layer.advance(dt)
+	for _, f := range done {
+		f()
+	}
+}
+
+func (t *Timer) paint() {
+	t.mu.Lock()
+	frac := 0.0
+	if t.duration > 0 {
+		frac = float64(t.remaining) / float64(t.duration)
+	}
+	n := float64(t.opt.Resolution)
+	from, to := -0.5, frac*n-0.5
+	var paint func(float64) color.RGBA64
+	switch {
+	case t.warning != nil && t.remaining < t.warnAt:
+		paint = solid(t.warning)
+	case t.palette != nil:
+		p := t.palette
+		paint = func(s float64) color.RGBA64 {
+			return p.at64((from + s*(to-from) + 0.5) / n)
+		}
+	default:
+		paint = solid(t.color)
+	}
+	t.mu.Unlock()
+
+	t.SetAll(color.Transparent)
+	t.drawSpan(from, to, paint)
+}
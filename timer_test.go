@@ -0,0 +1,46 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	white := color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	tm, err := NewTimer(4*time.Second, white, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.SetWarning(red, 2*time.Second)
+	done := 0
+	tm.OnDone(func() { done++ })
+
+	tests := []struct {
+		name string
+		do   func()
+		want []color.RGBA64 // colors of the pixels
+		done int
+	}{
+		{"stopped", func() { tm.advance(time.Second) }, []color.RGBA64{toRGBA64(white), toRGBA64(white), toRGBA64(white), toRGBA64(white)}, 0},
+		{"started", func() { tm.Start(); tm.advance(time.Second) }, []color.RGBA64{toRGBA64(white), toRGBA64(white), toRGBA64(white), {}}, 0},
+		{"paused", func() { tm.Stop(); tm.advance(time.Second) }, []color.RGBA64{toRGBA64(white), toRGBA64(white), toRGBA64(white), {}}, 0},
+		{"warning", func() { tm.Start(); tm.advance(1500 * time.Millisecond) }, []color.RGBA64{toRGBA64(red), fade(toRGBA64(red), 0.5), {}, {}}, 0},
+		{"done", func() { tm.advance(time.Minute) }, []color.RGBA64{{}, {}, {}, {}}, 1},
+		{"still done", func() { tm.Start(); tm.advance(time.Minute) }, []color.RGBA64{{}, {}, {}, {}}, 1},
+		{"reset", func() { tm.Reset(8 * time.Second) }, []color.RGBA64{toRGBA64(white), toRGBA64(white), toRGBA64(white), toRGBA64(white)}, 1},
+	}
+
+	for _, ts := range tests {
+		ts.do()
+		for i, want := range ts.want {
+			if got := tm.pixel64(i); got != want {
+				t.Errorf("%s pixel %d got: %#v, want: %#v", ts.name, i, got, want)
+			}
+		}
+		if done != ts.done {
+			t.Errorf("%s got: done %d times, want: %d", ts.name, done, ts.done)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// Trails is a layer that keeps what the layers beneath it show, and fades it
+// out over time, so any moving effect beneath it leaves smooth trails, without
+// implementing them. Each pixel shows the brighter, by luminance, of the color
+// of the layers beneath it and of its trail, so trails never mix colors. Add it
+// to a ring with AddLayer above the layers that leave trails, and run the ring
+// (see Ring.Run) to fade them out.
+type Trails struct {
+	opt *LayerOptions
+
+	mu     sync.Mutex
+	decay  float64 // seconds for a trail to fade out
+	pixels []color.RGBA64
+	next   []color.RGBA64 // trails of the last frame rendered
+	shown  bool           // next holds a frame not yet advanced
+	ver    uint64
+}
+
+// trailer is implemented by layers that replace the colors of the layers
+// beneath them, such as Trails, instead of being blended over them.
+type trailer interface {
+	trail(i int, beneath color.RGBA64) color.RGBA64
+}
+
+// NewTrails creates a trails layer for a ring with a given number of LEDs,
+// whose trails fade out over a duration.
+func NewTrails(decay time.Duration, size int) *Trails {
+	return &Trails{
+		opt: &LayerOptions{
+			Resolution: size,
+		},
+		decay:  decay.Seconds(),
+		pixels: make([]color.RGBA64, size),
+		next:   make([]color.RGBA64, size),
+	}
+}
+
+// SetDecay sets the time for the trails to fade out. A zero duration leaves
+// no trails.
+func (t *Trails) SetDecay(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.decay = d.Seconds()
+}
+
+// Clear removes the trails.
+func (t *Trails) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.pixels {
+		t.pixels[i] = color.RGBA64{}
+	}
+	t.shown = false
+	t.ver++
+}
+
+// Pixel returns the color of the trail at position i.
+func (t *Trails) Pixel(i int) color.Color {
+	return t.pixel64(i)
+}
+
+// Options returns the options of the trails layer.
+func (t *Trails) Options() *LayerOptions {
+	return t.opt
+}
+
+func (t *Trails) pixel64(i int) color.RGBA64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.pixels[mod(i, len(t.pixels))]
+}
+
+func (t *Trails) version() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ver
+}
+
+// trail returns the color the pixel at position i shows: the color beneath
+// it, if at least as bright as its trail, or as opaque if as bright, or else
+// its trail. The color shown is kept as the trail of the next frame on
+// advance, so rendering or reading the same frame more than once leaves the
+// trails unchanged.
+func (t *Trails) trail(i int, beneath color.RGBA64) color.RGBA64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i = mod(i, len(t.pixels))
+	c := t.pixels[i]
+	lb, lc := luminance(beneath), luminance(c)
+	if lb > lc || lb == lc && beneath.A >= c.A {
+		c = beneath
+	}
+	if !t.shown {
+		copy(t.next, t.pixels)
+		t.shown = true
+	}
+	t.next[i] = c
+
+	return c
+}
+
+// advance keeps the colors of the last frame rendered as the trails, and fades
+// them out by the time elapsed.
+func (t *Trails) advance(dt time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.shown {
+		for i := range t.pixels {
+			if t.pixels[i] != t.next[i] {
+				t.pixels[i] = t.next[i]
+				t.ver++
+			}
+		}
+		t.shown = false
+	}
+
+	factor := 0.0
+	if t.decay > 0 {
+		factor = math.Pow(trailFloor, dt.Seconds()/t.decay)
+	}
+	for i := range t.pixels {
+		if t.pixels[i] != (color.RGBA64{}) {
+			t.pixels[i] = fade(t.pixels[i], factor)
+			t.ver++
+		}
+	}
+}
+
+// luminance returns the relative luminance of an alpha pre-multiplied color,
+// with the weights of ITU-R BT.709, scaled to 0 to 10000*0xFFFF.
+func luminance(c color.RGBA64) uint32 {
+	return 2126*uint32(c.R) + 7152*uint32(c.G) + 722*uint32(c.B)
+}
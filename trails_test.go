@@ -0,0 +1,101 @@
+package ring
+
+import (
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestTrails(t *testing.T) {
+	r, dev := newTestRing(t, 4)
+	l := newTestLayer(t, &LayerOptions{Resolution: 4})
+	tr := NewTrails(time.Second, 4)
+	r.AddLayer(l)
+	r.AddLayer(tr)
+
+	tests := []struct {
+		name string
+		dot  int // lit pixel of the layer beneath
+		dt   time.Duration
+		want []uint32
+	}{
+		{"start", 0, 0, []uint32{0xFFFFFF, 0, 0, 0}},
+		{"moved", 1, 500 * time.Millisecond, []uint32{0x191919, 0xFFFFFF, 0, 0}},
+		{"moved again", 2, 500 * time.Millisecond, []uint32{0x020202, 0x191919, 0xFFFFFF, 0}},
+		{"rendered again", 2, 0, []uint32{0x020202, 0x191919, 0xFFFFFF, 0}},
+	}
+
+	for _, ts := range tests {
+		r.advance(ts.dt)
+		l.SetAll(color.Transparent)
+		l.SetPixel(ts.dot, color.White)
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range ts.want {
+			if got := dev.leds[i]; got != want {
+				t.Errorf("%s led %d got: %#x, want: %#x", ts.name, i, got, want)
+			}
+		}
+	}
+
+	tr.Clear()
+	if got := tr.pixel64(1); got != (color.RGBA64{}) {
+		t.Errorf("got: %#v after clear, want: transparent", got)
+	}
+}
+
+func TestTrailsBrighter(t *testing.T) {
+	tr := NewTrails(time.Second, 1)
+	dim := toRGBA64(color.RGBA{0x00, 0x00, 0x40, 0xFF})
+	bright := toRGBA64(color.RGBA{0x19, 0x19, 0x19, 0xFF})
+
+	tests := []struct {
+		name    string
+		beneath color.RGBA64
+		want    color.RGBA64
+	}{
+		{"first", bright, bright},
+		{"dimmer", dim, bright},
+		{"transparent", color.RGBA64{}, bright},
+		{"brighter", color.RGBA64{0xFFFF, 0, 0, 0xFFFF}, color.RGBA64{0xFFFF, 0, 0, 0xFFFF}},
+	}
+
+	for _, ts := range tests {
+		if got := tr.trail(0, ts.beneath); got != ts.want {
+			t.Errorf("%s got: %#v, want: %#v", ts.name, got, ts.want)
+		}
+		tr.advance(0)
+	}
+}
+
+func TestTrailsRead(t *testing.T) {
+	r, _ := newTestRing(t, 2)
+	l := newTestLayer(t, &LayerOptions{Resolution: 2})
+	tr := NewTrails(time.Second, 2)
+	r.AddLayer(l)
+	r.AddLayer(tr)
+
+	l.SetPixel(0, color.White)
+	dst := make([]uint32, 2)
+	for i := 0; i < 3; i++ {
+		r.Snapshot()
+		if err := r.RenderTo(dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := tr.pixel64(0); got != (color.RGBA64{}) {
+		t.Errorf("got: %#v before advance, want: transparent", got)
+	}
+
+	r.advance(500 * time.Millisecond)
+	l.SetAll(color.Transparent)
+	for i := 0; i < 3; i++ {
+		if err := r.RenderTo(dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := dst[0], uint32(0x191919); got != want {
+		t.Errorf("got: %#x after advance, want: %#x", got, want)
+	}
+}
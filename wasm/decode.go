@@ -0,0 +1,514 @@
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Limits of the modules, to keep effects from exhausting the memory of the
+// device.
+const (
+	maxPages  = 256 // 16 MiB of linear memory
+	maxLocals = 50000
+	maxTable  = 65536
+	pageSize  = 65536
+)
+
+var magic = []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+
+// errUnexpectedEnd is returned when a module is truncated.
+var errUnexpectedEnd = errors.New("unexpected end of module")
+
+type valType byte
+
+const (
+	i32 valType = 0x7F
+	i64 valType = 0x7E
+	f32 valType = 0x7D
+	f64 valType = 0x7C
+)
+
+type funcType struct {
+	params, results []valType
+}
+
+type function struct {
+	typ    funcType
+	locals []valType
+	code   []byte
+	ends   map[int]int // position of block, loop, if and else to their end
+	elses  map[int]int // position of if to its else
+}
+
+type global struct {
+	typ      valType
+	mutable  bool
+	init     uint64
+	initType valType
+}
+
+type dataSegment struct {
+	offset uint32
+	data   []byte
+}
+
+type elemSegment struct {
+	offset uint32
+	funcs  []uint32
+}
+
+// module is a decoded WebAssembly module.
+type module struct {
+	types   []funcType
+	funcs   []*function
+	globals []global
+	memory  *limits
+	table   *limits
+	data    []dataSegment
+	elems   []elemSegment
+	exports map[string]uint32 // exported functions
+	start   *uint32
+}
+
+type limits struct {
+	min, max uint32
+	hasMax   bool
+}
+
+// reader reads the binary format of modules. The first error is kept, and
+// reads after it return zero values.
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (r *reader) done() bool {
+	return r.err != nil || r.pos >= len(r.b)
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.b) {
+		r.err = errUnexpectedEnd
+		return 0
+	}
+	b := r.b[r.pos]
+	r.pos++
+
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b)-r.pos {
+		r.err = errUnexpectedEnd
+		return nil
+	}
+	b := r.b[r.pos : r.pos+n]
+	r.pos += n
+
+	return b
+}
+
+// uleb reads an unsigned LEB128 integer of at most n bits.
+func (r *reader) uleb(n uint) uint64 {
+	var v uint64
+	for shift := uint(0); ; shift += 7 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		if shift >= n || (shift+7 > n && b&0x7F>>(n-shift) != 0) {
+			r.fail("integer too large")
+			return 0
+		}
+		v |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+}
+
+// sleb reads a signed LEB128 integer of at most n bits.
+func (r *reader) sleb(n uint) int64 {
+	var v int64
+	for shift := uint(0); ; shift += 7 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		if shift >= n {
+			r.fail("integer too large")
+			return 0
+		}
+		v |= int64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			if shift+7 < 64 && b&0x40 != 0 {
+				v |= -1 << (shift + 7)
+			}
+			if n < 64 && (v < -1<<(n-1) || v >= 1<<(n-1)) {
+				r.fail("integer too large")
+				return 0
+			}
+			return v
+		}
+	}
+}
+
+func (r *reader) u32() uint32 { return uint32(r.uleb(32)) }
+
+// count reads the length of a vector, which cannot be longer than the rest of
+// the input, since every element takes at least one byte.
+func (r *reader) count() int {
+	n := r.u32()
+	if int(n) > len(r.b)-r.pos {
+		r.fail("vector too long")
+		return 0
+	}
+
+	return int(n)
+}
+
+func (r *reader) name() string {
+	return string(r.bytes(r.count()))
+}
+
+func (r *reader) valType() valType {
+	t := valType(r.byte())
+	switch t {
+	case i32, i64, f32, f64:
+	default:
+		r.fail("unsupported value type 0x%02x", byte(t))
+	}
+
+	return t
+}
+
+func (r *reader) limits() *limits {
+	l := &limits{}
+	switch r.byte() {
+	case 0x00:
+		l.min = r.u32()
+	case 0x01:
+		l.min, l.max, l.hasMax = r.u32(), r.u32(), true
+	default:
+		r.fail("invalid limits")
+	}
+
+	return l
+}
+
+// constExpr reads a constant expression, which initializes globals and gives
+// the offsets of segments, and returns its type and value.
+func (r *reader) constExpr() (valType, uint64) {
+	var t valType
+	var v uint64
+	switch op := r.byte(); op {
+	case 0x41:
+		t, v = i32, uint64(uint32(r.sleb(32)))
+	case 0x42:
+		t, v = i64, uint64(r.sleb(64))
+	case 0x43:
+		t = f32
+		if b := r.bytes(4); b != nil {
+			v = uint64(binary.LittleEndian.Uint32(b))
+		}
+	case 0x44:
+		t = f64
+		if b := r.bytes(8); b != nil {
+			v = binary.LittleEndian.Uint64(b)
+		}
+	default:
+		r.fail("unsupported constant expression 0x%02x", op)
+	}
+	if r.byte() != 0x0B {
+		r.fail("unsupported constant expression")
+	}
+
+	return t, v
+}
+
+// offset reads a constant expression that gives the offset of a segment.
+func (r *reader) offset() uint32 {
+	t, v := r.constExpr()
+	if t != i32 {
+		r.fail("offset is not an i32")
+	}
+
+	return uint32(v)
+}
+
+// decode decodes the binary format of a module, and validates it.
+func decode(b []byte) (*module, error) {
+	if !bytes.HasPrefix(b, magic) {
+		return nil, errors.New("not a WebAssembly module")
+	}
+	m := &module{exports: make(map[string]uint32)}
+	var funcTypes []uint32
+
+	r := &reader{b: b, pos: len(magic)}
+	for !r.done() {
+		id := r.byte()
+		s := &reader{b: r.bytes(int(r.u32()))}
+		if r.err != nil {
+			break
+		}
+		switch id {
+		case 0: // custom
+		case 1: // type
+			for i, n := 0, s.count(); i < n; i++ {
+				if s.byte() != 0x60 {
+					s.fail("invalid function type")
+				}
+				var t funcType
+				for j, n := 0, s.count(); j < n; j++ {
+					t.params = append(t.params, s.valType())
+				}
+				for j, n := 0, s.count(); j < n; j++ {
+					t.results = append(t.results, s.valType())
+				}
+				if len(t.results) > 1 {
+					s.fail("multiple results are not supported")
+				}
+				m.types = append(m.types, t)
+			}
+		case 2: // import
+			if s.count() > 0 {
+				s.fail("imports are not supported, effects must be self-contained")
+			}
+		case 3: // function
+			for i, n := 0, s.count(); i < n; i++ {
+				t := s.u32()
+				if int(t) >= len(m.types) {
+					s.fail("invalid type index %d", t)
+				}
+				funcTypes = append(funcTypes, t)
+			}
+		case 4: // table
+			for i, n := 0, s.count(); i < n; i++ {
+				if i > 0 || s.byte() != 0x70 {
+					s.fail("unsupported table")
+				}
+				m.table = s.limits()
+				if m.table.min > maxTable {
+					s.fail("table too large")
+				}
+			}
+		case 5: // memory
+			for i, n := 0, s.count(); i < n; i++ {
+				if i > 0 {
+					s.fail("multiple memories are not supported")
+				}
+				m.memory = s.limits()
+				if m.memory.min > maxPages {
+					s.fail("memory too large: %d pages, maximum %d", m.memory.min, maxPages)
+				}
+			}
+		case 6: // global
+			for i, n := 0, s.count(); i < n; i++ {
+				g := global{typ: s.valType()}
+				switch s.byte() {
+				case 0x00:
+				case 0x01:
+					g.mutable = true
+				default:
+					s.fail("invalid global")
+				}
+				g.initType, g.init = s.constExpr()
+				m.globals = append(m.globals, g)
+			}
+		case 7: // export
+			names := make(map[string]bool)
+			for i, n := 0, s.count(); i < n; i++ {
+				name := s.name()
+				kind, index := s.byte(), s.u32()
+				if names[name] {
+					s.fail("duplicate export %q", name)
+				}
+				names[name] = true
+				if kind == 0x00 {
+					m.exports[name] = index
+				}
+			}
+		case 8: // start
+			start := s.u32()
+			m.start = &start
+		case 9: // element
+			for i, n := 0, s.count(); i < n; i++ {
+				if s.u32() != 0 {
+					s.fail("unsupported element segment")
+				}
+				e := elemSegment{offset: s.offset()}
+				for j, n := 0, s.count(); j < n; j++ {
+					e.funcs = append(e.funcs, s.u32())
+				}
+				m.elems = append(m.elems, e)
+			}
+		case 10: // code
+			n := s.count()
+			if n != len(funcTypes) {
+				s.fail("function and code sections do not match")
+				break
+			}
+			for i := 0; i < n; i++ {
+				f := &function{typ: m.types[funcTypes[i]]}
+				body := &reader{b: s.bytes(int(s.u32()))}
+				f.locals = append(f.locals, f.typ.params...)
+				for j, n := 0, body.count(); j < n; j++ {
+					c, t := body.u32(), body.valType()
+					if int(c) > maxLocals-len(f.locals) {
+						body.fail("too many locals")
+						break
+					}
+					for k := uint32(0); k < c; k++ {
+						f.locals = append(f.locals, t)
+					}
+				}
+				if body.err == nil {
+					f.code = body.b[body.pos:]
+					body.err = f.scan()
+				}
+				if body.err != nil {
+					s.fail("function %d: %v", i, body.err)
+				}
+				m.funcs = append(m.funcs, f)
+			}
+		case 11: // data
+			for i, n := 0, s.count(); i < n; i++ {
+				if s.u32() != 0 {
+					s.fail("unsupported data segment")
+				}
+				d := dataSegment{offset: s.offset()}
+				d.data = s.bytes(s.count())
+				m.data = append(m.data, d)
+			}
+		case 12: // data count
+		default:
+			s.fail("unknown section %d", id)
+		}
+		if s.err == nil && !s.done() {
+			s.fail("section %d has extra bytes", id)
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(m.funcs) != len(funcTypes) {
+		return nil, errors.New("function and code sections do not match")
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// scan finds the ends of the blocks of the code of a function, to jump over
+// them, and checks that the instructions are supported.
+func (f *function) scan() error {
+	f.ends = make(map[int]int)
+	f.elses = make(map[int]int)
+
+	r := &reader{b: f.code}
+	var open []int
+	for !r.done() {
+		pos := r.pos
+		op := r.byte()
+		switch op {
+		case 0x02, 0x03, 0x04: // block, loop, if
+			if t := r.byte(); t != 0x40 {
+				r.pos--
+				r.valType()
+			}
+			open = append(open, pos)
+		case 0x05: // else
+			if len(open) == 0 || f.code[open[len(open)-1]] != 0x04 {
+				return errors.New("else without if")
+			}
+			f.elses[open[len(open)-1]] = pos
+		case 0x0B: // end
+			if len(open) == 0 {
+				if r.pos != len(f.code) {
+					return errors.New("code after the end of the function")
+				}
+				return nil
+			}
+			start := open[len(open)-1]
+			open = open[:len(open)-1]
+			f.ends[start] = pos
+			if e, ok := f.elses[start]; ok {
+				f.ends[e] = pos
+			}
+		default:
+			if err := skipImmediates(r, op); err != nil {
+				return err
+			}
+		}
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	return errors.New("function without end")
+}
+
+// skipImmediates skips the immediate arguments of an instruction, and returns
+// an error if the instruction is not supported.
+func skipImmediates(r *reader, op byte) error {
+	switch {
+	case op == 0x00, op == 0x01, op == 0x0F, op == 0x1A, op == 0x1B:
+	case op == 0x0C, op == 0x0D, op == 0x10, op >= 0x20 && op <= 0x24:
+		r.u32()
+	case op == 0x0E: // br_table
+		for i, n := 0, r.count(); i <= n; i++ {
+			r.u32()
+		}
+	case op == 0x11: // call_indirect
+		r.u32()
+		r.byte()
+	case op >= 0x28 && op <= 0x3E: // loads and stores
+		r.u32()
+		r.u32()
+	case op == 0x3F, op == 0x40: // memory.size, memory.grow
+		r.byte()
+	case op == 0x41:
+		r.sleb(32)
+	case op == 0x42:
+		r.sleb(64)
+	case op == 0x43:
+		r.bytes(4)
+	case op == 0x44:
+		r.bytes(8)
+	case op >= 0x45 && op <= 0xC4: // numeric
+	case op == 0xFC:
+		switch sub := r.u32(); {
+		case sub <= 7: // saturating truncations
+		case sub == 10: // memory.copy
+			r.bytes(2)
+		case sub == 11: // memory.fill
+			r.byte()
+		default:
+			return fmt.Errorf("unsupported instruction 0xfc %d", sub)
+		}
+	default:
+		return fmt.Errorf("unsupported instruction 0x%02x", op)
+	}
+
+	return r.err
+}
@@ -0,0 +1,204 @@
+// Package wasm runs effects compiled to WebAssembly, so effects can be written
+// in any language that compiles to WebAssembly and shared as a single .wasm
+// file, without recompiling the program that shows them.
+//
+// An effect is a module that exports a function pixel, which returns the color
+// of the pixel i, of size pixels, at the time t since the effect started, in
+// seconds, as 0xRRGGBB:
+//
+//	pixel(i i32, size i32, t f64) i32
+//
+// For example, a comet in C, compiled with
+// clang --target=wasm32 -O2 -nostdlib -Wl,--no-entry -Wl,--export=pixel:
+//
+//	int pixel(int i, int size, double t) {
+//		int head = (int)(t * 8) % size;
+//		int d = (head - i + size) % size;
+//		return d < 4 ? (0xFF >> d) << 16 : 0;
+//	}
+//
+// The module can also export a function frame, called once per frame before
+// the pixels, to update the state of the effect:
+//
+//	frame(t f64)
+//
+// Effects run sandboxed in an interpreter: modules are validated before they
+// run, they cannot import functions, so they have no access to the system,
+// their memory is limited to 16 MiB, and a frame can run at most a few million
+// instructions, so an effect that loops forever stops instead of freezing the
+// ring. The interpreter is fuzzed with FuzzModule.
+package wasm
+
+import (
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// frameFuel is the number of instructions that an effect can run per frame.
+const frameFuel = 1 << 22
+
+// Effect is a layer painted by a WebAssembly module. Add it to a ring with
+// AddLayer, and play it with the animator of the ring (see ring.Ring.Animator)
+// to advance it:
+//
+//	e, err := wasm.Load("comet.wasm", &ring.LayerOptions{Resolution: 24})
+//	...
+//	r.AddLayer(e)
+//	r.Animator().Play(e)
+//
+// The module can be replaced while the effect plays (see Swap and Reload).
+type Effect struct {
+	*ring.Layer
+
+	mu     sync.Mutex
+	path   string
+	in     *instance
+	pixel  uint32
+	frame  int // index of the frame function, or -1
+	pixels map[int]color.Color
+	t      time.Duration
+	err    error
+}
+
+// Load creates an effect from a WebAssembly module file.
+func Load(path string, options *ring.LayerOptions) (*Effect, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("wasm: could not read module: %w", err)
+	}
+	e, err := New(src, options)
+	if err != nil {
+		return nil, err
+	}
+	e.path = path
+
+	return e, nil
+}
+
+// New creates an effect from the binary of a WebAssembly module, and paints
+// its first frame.
+func New(src []byte, options *ring.LayerOptions) (*Effect, error) {
+	l, err := ring.NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Effect{
+		Layer:  l,
+		pixels: make(map[int]color.Color),
+	}
+	if err := e.Swap(src); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Swap replaces the module of the effect, which continues from the same time,
+// and paints a frame with it. If the new module fails, the effect keeps the
+// previous one. Swapping the module of an effect that stopped because of an
+// error clears the error, but the effect must be played again.
+func (e *Effect) Swap(src []byte) error {
+	m, err := decode(src)
+	if err != nil {
+		return fmt.Errorf("wasm: invalid module: %w", err)
+	}
+	pixel, ok := m.exports["pixel"]
+	if !ok {
+		return fmt.Errorf("wasm: module does not export pixel")
+	}
+	if !sameType(m.funcs[pixel].typ, funcType{params: []valType{i32, i32, f64}, results: []valType{i32}}) {
+		return fmt.Errorf("wasm: pixel must be pixel(i32, i32, f64) i32")
+	}
+	frame := -1
+	if f, ok := m.exports["frame"]; ok {
+		if !sameType(m.funcs[f].typ, funcType{params: []valType{f64}}) {
+			return fmt.Errorf("wasm: frame must be frame(f64)")
+		}
+		frame = int(f)
+	}
+	in, err := instantiate(m, frameFuel)
+	if err != nil {
+		return fmt.Errorf("wasm: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	oldIn, oldPixel, oldFrame, oldErr := e.in, e.pixel, e.frame, e.err
+	e.in, e.pixel, e.frame, e.err = in, pixel, frame, nil
+	if err := e.paint(); err != nil {
+		e.in, e.pixel, e.frame, e.err = oldIn, oldPixel, oldFrame, oldErr
+		return err
+	}
+
+	return nil
+}
+
+// Reload replaces the module of an effect created by Load with the current
+// contents of its file (see Swap).
+func (e *Effect) Reload() error {
+	if e.path == "" {
+		return fmt.Errorf("wasm: effect was not loaded from a file")
+	}
+	src, err := ioutil.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("wasm: could not read module: %w", err)
+	}
+
+	return e.Swap(src)
+}
+
+// Tick advances the effect by dt and paints the next frame. If the module
+// traps, the effect stops (see Err).
+func (e *Effect) Tick(dt time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.err != nil {
+		return true
+	}
+	e.t += dt
+
+	return e.paint() != nil
+}
+
+// Err returns the error of the module that stopped the effect, if any.
+func (e *Effect) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.err
+}
+
+// paint runs the module for a frame and sets the pixels at once. It must be
+// called with mu held.
+func (e *Effect) paint() error {
+	t := e.t.Seconds()
+	size := e.Options().Resolution
+
+	e.in.fuel = frameFuel
+	if e.frame >= 0 {
+		if _, err := e.in.invoke(uint32(e.frame), math.Float64bits(t)); err != nil {
+			e.err = fmt.Errorf("wasm: frame: %w", err)
+			return e.err
+		}
+	}
+	for i := 0; i < size; i++ {
+		c, err := e.in.invoke(e.pixel, uint64(uint32(i)), uint64(uint32(size)), math.Float64bits(t))
+		if err != nil {
+			e.err = fmt.Errorf("wasm: pixel %d: %w", i, err)
+			return e.err
+		}
+		e.pixels[i] = color.RGBA{uint8(c >> 16), uint8(c >> 8), uint8(c), 0xFF}
+	}
+	e.SetPixels(e.pixels)
+
+	return nil
+}
@@ -0,0 +1,768 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"runtime"
+)
+
+// Limits of the call stack: the depth of nested calls, and the number of
+// locals of all the functions being called.
+const (
+	maxDepth       = 1024
+	maxStackLocals = 1 << 20
+)
+
+// trap is an error that aborts the execution of a module.
+type trap string
+
+func (t trap) Error() string {
+	return "trap: " + string(t)
+}
+
+var le = binary.LittleEndian
+
+// label is the target of a branch.
+type label struct {
+	cont   int  // position to continue from
+	height int  // height of the stack at the start of the block
+	arity  int  // number of values kept by a branch
+	loop   bool // branches go back to the start of the block
+}
+
+// instance is a module ready to run, with its own memory and globals.
+type instance struct {
+	m        *module
+	globals  []uint64
+	mem      []byte
+	maxPages uint32
+	table    []int64 // function indices, or -1 if undefined
+	stack    []uint64
+	fuel     int // instructions left to execute
+	depth    int
+	locals   int // locals of the functions being called
+}
+
+// instantiate creates an instance of a module, and runs its start function.
+func instantiate(m *module, fuel int) (*instance, error) {
+	in := &instance{m: m}
+	for _, g := range m.globals {
+		in.globals = append(in.globals, g.init)
+	}
+	if m.memory != nil {
+		in.mem = make([]byte, int(m.memory.min)*pageSize)
+		in.maxPages = maxPages
+		if m.memory.hasMax && m.memory.max < maxPages {
+			in.maxPages = m.memory.max
+		}
+	}
+	if m.table != nil {
+		in.table = make([]int64, m.table.min)
+		for i := range in.table {
+			in.table[i] = -1
+		}
+	}
+	for _, e := range m.elems {
+		if uint64(e.offset)+uint64(len(e.funcs)) > uint64(len(in.table)) {
+			return nil, errors.New("element segment out of bounds")
+		}
+		for i, f := range e.funcs {
+			in.table[int(e.offset)+i] = int64(f)
+		}
+	}
+	for _, d := range m.data {
+		if uint64(d.offset)+uint64(len(d.data)) > uint64(len(in.mem)) {
+			return nil, errors.New("data segment out of bounds")
+		}
+		copy(in.mem[d.offset:], d.data)
+	}
+	if m.start != nil {
+		in.fuel = fuel
+		if _, err := in.invoke(*m.start); err != nil {
+			return nil, fmt.Errorf("start function: %w", err)
+		}
+	}
+
+	return in, nil
+}
+
+// invoke calls a function, with the fuel left, and returns its result, if any.
+// Modules are validated when decoded, so they can only trap; a runtime error
+// would be a bug of the interpreter, and is returned as an internal error
+// instead of crashing the program.
+func (in *instance) invoke(f uint32, args ...uint64) (result uint64, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			in.stack = in.stack[:0]
+			in.depth = 0
+			in.locals = 0
+			switch v := v.(type) {
+			case trap:
+				err = v
+			case runtime.Error:
+				err = fmt.Errorf("internal error: %v", v)
+			default:
+				panic(v)
+			}
+		}
+	}()
+
+	in.stack = append(in.stack[:0], args...)
+	in.call(f)
+	if len(in.m.funcs[f].typ.results) > 0 {
+		result = in.pop()
+	}
+	in.stack = in.stack[:0]
+
+	return result, nil
+}
+
+func (in *instance) push(v uint64) {
+	in.stack = append(in.stack, v)
+}
+
+func (in *instance) pop() uint64 {
+	v := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+
+	return v
+}
+
+func (in *instance) pop32() uint32 {
+	return uint32(in.pop())
+}
+
+func (in *instance) pushF32(v float32) {
+	in.push(uint64(math.Float32bits(v)))
+}
+
+func (in *instance) pushF64(v float64) {
+	in.push(math.Float64bits(v))
+}
+
+func (in *instance) popF32() float32 {
+	return math.Float32frombits(in.pop32())
+}
+
+func (in *instance) popF64() float64 {
+	return math.Float64frombits(in.pop())
+}
+
+// address pops the base address of a memory access and reads its offset, and
+// returns the effective address of n bytes.
+func (in *instance) address(r *reader, n int) uint64 {
+	r.u32() // alignment
+	offset := r.u32()
+	ea := uint64(in.pop32()) + uint64(offset)
+	in.checkBounds(ea, uint64(n))
+
+	return ea
+}
+
+func (in *instance) checkBounds(ea, n uint64) {
+	if ea+n > uint64(len(in.mem)) {
+		panic(trap("out of bounds memory access"))
+	}
+}
+
+// call calls a function with the arguments on the stack, and leaves its result
+// on the stack.
+func (in *instance) call(idx uint32) {
+	f := in.m.funcs[idx]
+	in.depth++
+	in.locals += len(f.locals)
+	if in.depth > maxDepth || in.locals > maxStackLocals {
+		panic(trap("call stack exhausted"))
+	}
+
+	locals := make([]uint64, len(f.locals))
+	n := len(f.typ.params)
+	copy(locals, in.stack[len(in.stack)-n:])
+	in.stack = in.stack[:len(in.stack)-n]
+	base := len(in.stack)
+	arity := len(f.typ.results)
+	labels := []label{{cont: len(f.code), height: base, arity: arity}}
+
+	r := &reader{b: f.code}
+	br := func(depth uint32) {
+		l := labels[len(labels)-1-int(depth)]
+		copy(in.stack[l.height:], in.stack[len(in.stack)-l.arity:])
+		in.stack = in.stack[:l.height+l.arity]
+		if l.loop {
+			labels = labels[:len(labels)-int(depth)]
+		} else {
+			labels = labels[:len(labels)-1-int(depth)]
+		}
+		r.pos = l.cont
+	}
+	blockArity := func() int {
+		if r.byte() == 0x40 {
+			return 0
+		}
+		return 1
+	}
+
+	for r.pos < len(f.code) {
+		if in.fuel--; in.fuel < 0 {
+			panic(trap("out of fuel"))
+		}
+		pos := r.pos
+		op := r.byte()
+		switch {
+		case op == 0x00: // unreachable
+			panic(trap("unreachable"))
+		case op == 0x01: // nop
+		case op == 0x02: // block
+			labels = append(labels, label{cont: f.ends[pos] + 1, height: len(in.stack), arity: blockArity()})
+		case op == 0x03: // loop
+			blockArity()
+			labels = append(labels, label{cont: r.pos, height: len(in.stack), loop: true})
+		case op == 0x04: // if
+			a := blockArity()
+			if in.pop32() != 0 {
+				labels = append(labels, label{cont: f.ends[pos] + 1, height: len(in.stack), arity: a})
+			} else if e, ok := f.elses[pos]; ok {
+				r.pos = e + 1
+				labels = append(labels, label{cont: f.ends[pos] + 1, height: len(in.stack), arity: a})
+			} else {
+				r.pos = f.ends[pos] + 1
+			}
+		case op == 0x05: // else, at the end of the then branch
+			r.pos = f.ends[pos] + 1
+			labels = labels[:len(labels)-1]
+		case op == 0x0B: // end
+			labels = labels[:len(labels)-1]
+		case op == 0x0C: // br
+			br(r.u32())
+		case op == 0x0D: // br_if
+			depth := r.u32()
+			if in.pop32() != 0 {
+				br(depth)
+			}
+		case op == 0x0E: // br_table
+			targets := make([]uint32, r.count()+1)
+			for i := range targets {
+				targets[i] = r.u32()
+			}
+			i := in.pop32()
+			if int(i) >= len(targets)-1 {
+				i = uint32(len(targets) - 1)
+			}
+			br(targets[i])
+		case op == 0x0F: // return
+			br(uint32(len(labels) - 1))
+		case op == 0x10: // call
+			in.call(r.u32())
+		case op == 0x11: // call_indirect
+			t := in.m.types[r.u32()]
+			r.byte()
+			i := in.pop32()
+			if int(i) >= len(in.table) || in.table[i] < 0 {
+				panic(trap("undefined element"))
+			}
+			callee := uint32(in.table[i])
+			if !sameType(in.m.funcs[callee].typ, t) {
+				panic(trap("indirect call type mismatch"))
+			}
+			in.call(callee)
+		case op == 0x1A: // drop
+			in.pop()
+		case op == 0x1B: // select
+			c, b, a := in.pop32(), in.pop(), in.pop()
+			if c != 0 {
+				in.push(a)
+			} else {
+				in.push(b)
+			}
+		case op == 0x20: // local.get
+			in.push(locals[r.u32()])
+		case op == 0x21: // local.set
+			locals[r.u32()] = in.pop()
+		case op == 0x22: // local.tee
+			locals[r.u32()] = in.stack[len(in.stack)-1]
+		case op == 0x23: // global.get
+			in.push(in.globals[r.u32()])
+		case op == 0x24: // global.set
+			in.globals[r.u32()] = in.pop()
+		case op >= 0x28 && op <= 0x35:
+			in.load(r, op)
+		case op >= 0x36 && op <= 0x3E:
+			in.store(r, op)
+		case op == 0x3F: // memory.size
+			r.byte()
+			in.push(uint64(len(in.mem) / pageSize))
+		case op == 0x40: // memory.grow
+			r.byte()
+			n := in.pop32()
+			pages := uint32(len(in.mem) / pageSize)
+			if uint64(pages)+uint64(n) > uint64(in.maxPages) {
+				in.push(uint64(math.MaxUint32))
+				break
+			}
+			in.mem = append(in.mem, make([]byte, int(n)*pageSize)...)
+			in.push(uint64(pages))
+		case op == 0x41: // i32.const
+			in.push(uint64(uint32(r.sleb(32))))
+		case op == 0x42: // i64.const
+			in.push(uint64(r.sleb(64)))
+		case op == 0x43: // f32.const
+			in.push(uint64(le.Uint32(r.bytes(4))))
+		case op == 0x44: // f64.const
+			in.push(le.Uint64(r.bytes(8)))
+		case op == 0x45: // i32.eqz
+			in.push(b2u(in.pop32() == 0))
+		case op >= 0x46 && op <= 0x4F:
+			b, a := in.pop32(), in.pop32()
+			in.push(b2u(compareInt(op-0x46, int64(int32(a)), int64(int32(b)), uint64(a), uint64(b))))
+		case op == 0x50: // i64.eqz
+			in.push(b2u(in.pop() == 0))
+		case op >= 0x51 && op <= 0x5A:
+			b, a := in.pop(), in.pop()
+			in.push(b2u(compareInt(op-0x51, int64(a), int64(b), a, b)))
+		case op >= 0x5B && op <= 0x60:
+			b, a := in.popF32(), in.popF32()
+			in.push(b2u(compareFloat(op-0x5B, float64(a), float64(b))))
+		case op >= 0x61 && op <= 0x66:
+			b, a := in.popF64(), in.popF64()
+			in.push(b2u(compareFloat(op-0x61, a, b)))
+		case op >= 0x67 && op <= 0x69:
+			in.push(uint64(unaryInt32(op-0x67, in.pop32())))
+		case op >= 0x6A && op <= 0x78:
+			b, a := in.pop32(), in.pop32()
+			in.push(uint64(binaryInt32(op-0x6A, a, b)))
+		case op >= 0x79 && op <= 0x7B:
+			in.push(unaryInt64(op-0x79, in.pop()))
+		case op >= 0x7C && op <= 0x8A:
+			b, a := in.pop(), in.pop()
+			in.push(binaryInt64(op-0x7C, a, b))
+		case op >= 0x8B && op <= 0x91:
+			in.pushF32(float32(unaryFloat(op-0x8B, float64(in.popF32()))))
+		case op >= 0x92 && op <= 0x98:
+			b, a := in.popF32(), in.popF32()
+			in.pushF32(float32(binaryFloat(op-0x92, float64(a), float64(b))))
+		case op >= 0x99 && op <= 0x9F:
+			in.pushF64(unaryFloat(op-0x99, in.popF64()))
+		case op >= 0xA0 && op <= 0xA6:
+			b, a := in.popF64(), in.popF64()
+			in.pushF64(binaryFloat(op-0xA0, a, b))
+		case op >= 0xA7 && op <= 0xC4:
+			in.convert(op)
+		case op == 0xFC:
+			in.misc(r)
+		default:
+			panic(trap(fmt.Sprintf("unsupported instruction 0x%02x", op)))
+		}
+	}
+
+	copy(in.stack[base:], in.stack[len(in.stack)-arity:])
+	in.stack = in.stack[:base+arity]
+	in.depth--
+	in.locals -= len(f.locals)
+}
+
+func (in *instance) load(r *reader, op byte) {
+	switch op {
+	case 0x28: // i32.load
+		in.push(uint64(le.Uint32(in.mem[in.address(r, 4):])))
+	case 0x29: // i64.load
+		in.push(le.Uint64(in.mem[in.address(r, 8):]))
+	case 0x2A: // f32.load
+		in.push(uint64(le.Uint32(in.mem[in.address(r, 4):])))
+	case 0x2B: // f64.load
+		in.push(le.Uint64(in.mem[in.address(r, 8):]))
+	case 0x2C: // i32.load8_s
+		in.push(uint64(uint32(int8(in.mem[in.address(r, 1)]))))
+	case 0x2D: // i32.load8_u
+		in.push(uint64(in.mem[in.address(r, 1)]))
+	case 0x2E: // i32.load16_s
+		in.push(uint64(uint32(int16(le.Uint16(in.mem[in.address(r, 2):])))))
+	case 0x2F: // i32.load16_u
+		in.push(uint64(le.Uint16(in.mem[in.address(r, 2):])))
+	case 0x30: // i64.load8_s
+		in.push(uint64(int8(in.mem[in.address(r, 1)])))
+	case 0x31: // i64.load8_u
+		in.push(uint64(in.mem[in.address(r, 1)]))
+	case 0x32: // i64.load16_s
+		in.push(uint64(int16(le.Uint16(in.mem[in.address(r, 2):]))))
+	case 0x33: // i64.load16_u
+		in.push(uint64(le.Uint16(in.mem[in.address(r, 2):])))
+	case 0x34: // i64.load32_s
+		in.push(uint64(int32(le.Uint32(in.mem[in.address(r, 4):]))))
+	case 0x35: // i64.load32_u
+		in.push(uint64(le.Uint32(in.mem[in.address(r, 4):])))
+	}
+}
+
+func (in *instance) store(r *reader, op byte) {
+	v := in.pop()
+	switch op {
+	case 0x36, 0x38, 0x3E: // i32.store, f32.store, i64.store32
+		le.PutUint32(in.mem[in.address(r, 4):], uint32(v))
+	case 0x37, 0x39: // i64.store, f64.store
+		le.PutUint64(in.mem[in.address(r, 8):], v)
+	case 0x3A, 0x3C: // i32.store8, i64.store8
+		in.mem[in.address(r, 1)] = byte(v)
+	case 0x3B, 0x3D: // i32.store16, i64.store16
+		le.PutUint16(in.mem[in.address(r, 2):], uint16(v))
+	}
+}
+
+func (in *instance) convert(op byte) {
+	switch op {
+	case 0xA7: // i32.wrap_i64
+		in.push(uint64(in.pop32()))
+	case 0xA8: // i32.trunc_f32_s
+		in.push(truncate(float64(in.popF32()), true, 32, false))
+	case 0xA9: // i32.trunc_f32_u
+		in.push(truncate(float64(in.popF32()), false, 32, false))
+	case 0xAA: // i32.trunc_f64_s
+		in.push(truncate(in.popF64(), true, 32, false))
+	case 0xAB: // i32.trunc_f64_u
+		in.push(truncate(in.popF64(), false, 32, false))
+	case 0xAC: // i64.extend_i32_s
+		in.push(uint64(int32(in.pop32())))
+	case 0xAD: // i64.extend_i32_u
+		in.push(uint64(in.pop32()))
+	case 0xAE: // i64.trunc_f32_s
+		in.push(truncate(float64(in.popF32()), true, 64, false))
+	case 0xAF: // i64.trunc_f32_u
+		in.push(truncate(float64(in.popF32()), false, 64, false))
+	case 0xB0: // i64.trunc_f64_s
+		in.push(truncate(in.popF64(), true, 64, false))
+	case 0xB1: // i64.trunc_f64_u
+		in.push(truncate(in.popF64(), false, 64, false))
+	case 0xB2: // f32.convert_i32_s
+		in.pushF32(float32(int32(in.pop32())))
+	case 0xB3: // f32.convert_i32_u
+		in.pushF32(float32(in.pop32()))
+	case 0xB4: // f32.convert_i64_s
+		in.pushF32(float32(int64(in.pop())))
+	case 0xB5: // f32.convert_i64_u
+		in.pushF32(float32(in.pop()))
+	case 0xB6: // f32.demote_f64
+		in.pushF32(float32(in.popF64()))
+	case 0xB7: // f64.convert_i32_s
+		in.pushF64(float64(int32(in.pop32())))
+	case 0xB8: // f64.convert_i32_u
+		in.pushF64(float64(in.pop32()))
+	case 0xB9: // f64.convert_i64_s
+		in.pushF64(float64(int64(in.pop())))
+	case 0xBA: // f64.convert_i64_u
+		in.pushF64(float64(in.pop()))
+	case 0xBB: // f64.promote_f32
+		in.pushF64(float64(in.popF32()))
+	case 0xBC, 0xBD, 0xBE, 0xBF: // reinterpretations keep the bits
+	case 0xC0: // i32.extend8_s
+		in.push(uint64(uint32(int8(in.pop()))))
+	case 0xC1: // i32.extend16_s
+		in.push(uint64(uint32(int16(in.pop()))))
+	case 0xC2: // i64.extend8_s
+		in.push(uint64(int8(in.pop())))
+	case 0xC3: // i64.extend16_s
+		in.push(uint64(int16(in.pop())))
+	case 0xC4: // i64.extend32_s
+		in.push(uint64(int32(in.pop())))
+	}
+}
+
+// misc runs the instructions with the prefix 0xFC.
+func (in *instance) misc(r *reader) {
+	switch sub := r.u32(); sub {
+	case 0, 1, 2, 3, 4, 5, 6, 7: // saturating truncations
+		signed := sub%2 == 0
+		n := uint(32)
+		if sub >= 4 {
+			n = 64
+		}
+		var x float64
+		if sub%4 < 2 {
+			x = float64(in.popF32())
+		} else {
+			x = in.popF64()
+		}
+		in.push(truncate(x, signed, n, true))
+	case 10: // memory.copy
+		r.bytes(2)
+		n, src, dst := uint64(in.pop32()), uint64(in.pop32()), uint64(in.pop32())
+		in.checkBounds(src, n)
+		in.checkBounds(dst, n)
+		copy(in.mem[dst:dst+n], in.mem[src:src+n])
+	case 11: // memory.fill
+		r.byte()
+		n, v, dst := uint64(in.pop32()), byte(in.pop()), uint64(in.pop32())
+		in.checkBounds(dst, n)
+		for i := dst; i < dst+n; i++ {
+			in.mem[i] = v
+		}
+	default:
+		panic(trap(fmt.Sprintf("unsupported instruction 0xfc %d", sub)))
+	}
+}
+
+func sameType(a, b funcType) bool {
+	if len(a.params) != len(b.params) || len(a.results) != len(b.results) {
+		return false
+	}
+	for i := range a.params {
+		if a.params[i] != b.params[i] {
+			return false
+		}
+	}
+	for i := range a.results {
+		if a.results[i] != b.results[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func b2u(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compareInt compares integers, as signed (as, bs) or unsigned (au, bu), in
+// the order of the instructions: eq, ne, lt_s, lt_u, gt_s, gt_u, le_s, le_u,
+// ge_s and ge_u.
+func compareInt(k byte, as, bs int64, au, bu uint64) bool {
+	switch k {
+	case 0:
+		return au == bu
+	case 1:
+		return au != bu
+	case 2:
+		return as < bs
+	case 3:
+		return au < bu
+	case 4:
+		return as > bs
+	case 5:
+		return au > bu
+	case 6:
+		return as <= bs
+	case 7:
+		return au <= bu
+	case 8:
+		return as >= bs
+	default:
+		return au >= bu
+	}
+}
+
+// compareFloat compares floats in the order of the instructions: eq, ne, lt,
+// gt, le and ge.
+func compareFloat(k byte, a, b float64) bool {
+	switch k {
+	case 0:
+		return a == b
+	case 1:
+		return a != b
+	case 2:
+		return a < b
+	case 3:
+		return a > b
+	case 4:
+		return a <= b
+	default:
+		return a >= b
+	}
+}
+
+// unaryInt32 runs clz, ctz or popcnt.
+func unaryInt32(k byte, a uint32) uint32 {
+	switch k {
+	case 0:
+		return uint32(bits.LeadingZeros32(a))
+	case 1:
+		return uint32(bits.TrailingZeros32(a))
+	default:
+		return uint32(bits.OnesCount32(a))
+	}
+}
+
+func unaryInt64(k byte, a uint64) uint64 {
+	switch k {
+	case 0:
+		return uint64(bits.LeadingZeros64(a))
+	case 1:
+		return uint64(bits.TrailingZeros64(a))
+	default:
+		return uint64(bits.OnesCount64(a))
+	}
+}
+
+// binaryInt32 runs, in the order of the instructions, add, sub, mul, div_s,
+// div_u, rem_s, rem_u, and, or, xor, shl, shr_s, shr_u, rotl and rotr.
+func binaryInt32(k byte, a, b uint32) uint32 {
+	if k >= 3 && k <= 6 && b == 0 {
+		panic(trap("integer divide by zero"))
+	}
+	switch k {
+	case 0:
+		return a + b
+	case 1:
+		return a - b
+	case 2:
+		return a * b
+	case 3:
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			panic(trap("integer overflow"))
+		}
+		return uint32(int32(a) / int32(b))
+	case 4:
+		return a / b
+	case 5:
+		return uint32(int32(a) % int32(b))
+	case 6:
+		return a % b
+	case 7:
+		return a & b
+	case 8:
+		return a | b
+	case 9:
+		return a ^ b
+	case 10:
+		return a << (b % 32)
+	case 11:
+		return uint32(int32(a) >> (b % 32))
+	case 12:
+		return a >> (b % 32)
+	case 13:
+		return bits.RotateLeft32(a, int(b%32))
+	default:
+		return bits.RotateLeft32(a, -int(b%32))
+	}
+}
+
+func binaryInt64(k byte, a, b uint64) uint64 {
+	if k >= 3 && k <= 6 && b == 0 {
+		panic(trap("integer divide by zero"))
+	}
+	switch k {
+	case 0:
+		return a + b
+	case 1:
+		return a - b
+	case 2:
+		return a * b
+	case 3:
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			panic(trap("integer overflow"))
+		}
+		return uint64(int64(a) / int64(b))
+	case 4:
+		return a / b
+	case 5:
+		return uint64(int64(a) % int64(b))
+	case 6:
+		return a % b
+	case 7:
+		return a & b
+	case 8:
+		return a | b
+	case 9:
+		return a ^ b
+	case 10:
+		return a << (b % 64)
+	case 11:
+		return uint64(int64(a) >> (b % 64))
+	case 12:
+		return a >> (b % 64)
+	case 13:
+		return bits.RotateLeft64(a, int(b%64))
+	default:
+		return bits.RotateLeft64(a, -int(b%64))
+	}
+}
+
+// unaryFloat runs, in the order of the instructions, abs, neg, ceil, floor,
+// trunc, nearest and sqrt. Results of 32-bit floats are exact when rounded.
+func unaryFloat(k byte, a float64) float64 {
+	switch k {
+	case 0:
+		return math.Abs(a)
+	case 1:
+		return -a
+	case 2:
+		return math.Ceil(a)
+	case 3:
+		return math.Floor(a)
+	case 4:
+		return math.Trunc(a)
+	case 5:
+		return math.RoundToEven(a)
+	default:
+		return math.Sqrt(a)
+	}
+}
+
+// binaryFloat runs, in the order of the instructions, add, sub, mul, div,
+// min, max and copysign.
+func binaryFloat(k byte, a, b float64) float64 {
+	switch k {
+	case 0:
+		return a + b
+	case 1:
+		return a - b
+	case 2:
+		return a * b
+	case 3:
+		return a / b
+	case 4:
+		return math.Min(a, b)
+	case 5:
+		return math.Max(a, b)
+	default:
+		return math.Copysign(a, b)
+	}
+}
+
+// truncate converts a float to a signed or unsigned integer of n bits. Out of
+// range values trap, unless saturated to the closest integer.
+func truncate(x float64, signed bool, n uint, saturate bool) uint64 {
+	if math.IsNaN(x) {
+		if saturate {
+			return 0
+		}
+		panic(trap("invalid conversion to integer"))
+	}
+	x = math.Trunc(x)
+
+	var v uint64
+	if signed {
+		min, max := -math.Ldexp(1, int(n-1)), math.Ldexp(1, int(n-1))
+		switch {
+		case x >= min && x < max:
+			v = uint64(int64(x))
+		case !saturate:
+			panic(trap("integer overflow"))
+		case x < min:
+			v = uint64(int64(-1) << (n - 1))
+		default:
+			v = uint64(int64(1)<<(n-1) - 1)
+		}
+	} else {
+		max := math.Ldexp(1, int(n))
+		switch {
+		case x > -1 && x < max:
+			v = uint64(x)
+		case !saturate:
+			panic(trap("integer overflow"))
+		case x < 0:
+			v = 0
+		default:
+			v = math.MaxUint64
+		}
+	}
+	if n == 32 {
+		v = uint64(uint32(v))
+	}
+
+	return v
+}
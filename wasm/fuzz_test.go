@@ -0,0 +1,18 @@
+//go:build go1.18
+// +build go1.18
+
+package wasm
+
+import "testing"
+
+// FuzzModule checks that no module, valid or not, makes the interpreter fail
+// instead of rejecting the module or trapping. Run it with:
+//
+//	go test -fuzz FuzzModule ./wasm
+func FuzzModule(f *testing.F) {
+	for _, seed := range seedModules() {
+		f.Add(seed)
+	}
+
+	f.Fuzz(runModule)
+}
@@ -0,0 +1,558 @@
+package wasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// unknown is the type of a value popped from the stack of unreachable code,
+// which matches any type.
+const unknown valType = 0
+
+// validate checks that a decoded module is valid, following the validation
+// algorithm of the WebAssembly specification, so that running it can only
+// trap, never misbehave: every instruction finds operands of the right types
+// on the stack, and every index refers to something that exists.
+func (m *module) validate() error {
+	for i, g := range m.globals {
+		if g.initType != g.typ {
+			return fmt.Errorf("global %d: initializer does not match its type", i)
+		}
+	}
+	if len(m.data) > 0 && m.memory == nil {
+		return errors.New("data segment without memory")
+	}
+	if m.memory != nil && m.memory.hasMax && m.memory.max < m.memory.min {
+		return errors.New("memory maximum is less than its minimum")
+	}
+	if len(m.elems) > 0 && m.table == nil {
+		return errors.New("element segment without table")
+	}
+	if m.table != nil && m.table.hasMax && m.table.max < m.table.min {
+		return errors.New("table maximum is less than its minimum")
+	}
+	for _, e := range m.elems {
+		for _, f := range e.funcs {
+			if int(f) >= len(m.funcs) {
+				return fmt.Errorf("element segment: invalid function index %d", f)
+			}
+		}
+	}
+	for name, f := range m.exports {
+		if int(f) >= len(m.funcs) {
+			return fmt.Errorf("export %q: invalid function index %d", name, f)
+		}
+	}
+	if m.start != nil {
+		if int(*m.start) >= len(m.funcs) {
+			return fmt.Errorf("invalid start function %d", *m.start)
+		}
+		if t := m.funcs[*m.start].typ; len(t.params) != 0 || len(t.results) != 0 {
+			return errors.New("invalid start function type")
+		}
+	}
+	for i, f := range m.funcs {
+		if err := m.validateFunc(f); err != nil {
+			return fmt.Errorf("function %d: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ctrlFrame is a block being validated.
+type ctrlFrame struct {
+	op          byte      // block, loop, if or else
+	results     []valType // types left on the stack at the end
+	height      int       // height of the stack at the start
+	unreachable bool      // the rest of the block cannot run
+}
+
+// labelTypes returns the types that a branch to the block keeps on the stack.
+func (c *ctrlFrame) labelTypes() []valType {
+	if c.op == 0x03 { // loop
+		return nil
+	}
+	return c.results
+}
+
+// validator checks the types of the code of a function.
+type validator struct {
+	m     *module
+	vals  []valType
+	ctrls []ctrlFrame
+}
+
+func (v *validator) push(t valType) {
+	v.vals = append(v.vals, t)
+}
+
+func (v *validator) pushAll(ts []valType) {
+	v.vals = append(v.vals, ts...)
+}
+
+func (v *validator) pop() (valType, error) {
+	c := &v.ctrls[len(v.ctrls)-1]
+	if len(v.vals) == c.height {
+		if c.unreachable {
+			return unknown, nil
+		}
+		return 0, errors.New("type mismatch: not enough operands")
+	}
+	t := v.vals[len(v.vals)-1]
+	v.vals = v.vals[:len(v.vals)-1]
+
+	return t, nil
+}
+
+func (v *validator) popExpect(want valType) error {
+	got, err := v.pop()
+	if err != nil {
+		return err
+	}
+	if got != want && got != unknown && want != unknown {
+		return fmt.Errorf("type mismatch: got %s, want %s", got, want)
+	}
+
+	return nil
+}
+
+// popAll pops the types, which are in the order they were pushed.
+func (v *validator) popAll(ts []valType) error {
+	for i := len(ts) - 1; i >= 0; i-- {
+		if err := v.popExpect(ts[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// op pops the operands and pushes the result of an instruction.
+func (v *validator) op(params []valType, result valType) error {
+	if err := v.popAll(params); err != nil {
+		return err
+	}
+	if result != unknown {
+		v.push(result)
+	}
+
+	return nil
+}
+
+func (v *validator) pushCtrl(op byte, results []valType) {
+	v.ctrls = append(v.ctrls, ctrlFrame{op: op, results: results, height: len(v.vals)})
+}
+
+func (v *validator) popCtrl() (ctrlFrame, error) {
+	c := v.ctrls[len(v.ctrls)-1]
+	if err := v.popAll(c.results); err != nil {
+		return c, err
+	}
+	if len(v.vals) != c.height {
+		return c, errors.New("type mismatch: too many operands at the end of a block")
+	}
+	v.ctrls = v.ctrls[:len(v.ctrls)-1]
+
+	return c, nil
+}
+
+// setUnreachable marks the rest of the current block as unreachable.
+func (v *validator) setUnreachable() {
+	c := &v.ctrls[len(v.ctrls)-1]
+	v.vals = v.vals[:c.height]
+	c.unreachable = true
+}
+
+// label returns the block that a branch of a depth targets.
+func (v *validator) label(depth uint32) (*ctrlFrame, error) {
+	if int(depth) >= len(v.ctrls) {
+		return nil, fmt.Errorf("invalid branch depth %d", depth)
+	}
+
+	return &v.ctrls[len(v.ctrls)-1-int(depth)], nil
+}
+
+// validateFunc checks the code of a function.
+func (m *module) validateFunc(f *function) error {
+	v := &validator{m: m}
+	v.pushCtrl(0x02, f.typ.results)
+
+	r := &reader{b: f.code}
+	for len(v.ctrls) > 0 {
+		if r.done() {
+			if r.err != nil {
+				return r.err
+			}
+			return errors.New("function without end")
+		}
+		if err := v.instr(r, f); err != nil {
+			return err
+		}
+		if r.err != nil {
+			return r.err
+		}
+	}
+	if !r.done() {
+		return errors.New("code after the end of the function")
+	}
+
+	return nil
+}
+
+// blockType reads the type of the results of a block.
+func blockType(r *reader) []valType {
+	if r.byte() == 0x40 {
+		return nil
+	}
+	r.pos--
+
+	return []valType{r.valType()}
+}
+
+// instr checks an instruction.
+func (v *validator) instr(r *reader, f *function) error {
+	m := v.m
+	op := r.byte()
+	switch {
+	case op == 0x00: // unreachable
+		v.setUnreachable()
+	case op == 0x01: // nop
+	case op == 0x02, op == 0x03: // block, loop
+		v.pushCtrl(op, blockType(r))
+	case op == 0x04: // if
+		results := blockType(r)
+		if err := v.popExpect(i32); err != nil {
+			return err
+		}
+		v.pushCtrl(op, results)
+	case op == 0x05: // else
+		c, err := v.popCtrl()
+		if err != nil {
+			return err
+		}
+		if c.op != 0x04 {
+			return errors.New("else without if")
+		}
+		v.pushCtrl(op, c.results)
+	case op == 0x0B: // end
+		c, err := v.popCtrl()
+		if err != nil {
+			return err
+		}
+		if c.op == 0x04 && len(c.results) != 0 {
+			return errors.New("type mismatch: if with a result needs an else")
+		}
+		v.pushAll(c.results)
+	case op == 0x0C: // br
+		l, err := v.label(r.u32())
+		if err != nil {
+			return err
+		}
+		if err := v.popAll(l.labelTypes()); err != nil {
+			return err
+		}
+		v.setUnreachable()
+	case op == 0x0D: // br_if
+		l, err := v.label(r.u32())
+		if err != nil {
+			return err
+		}
+		if err := v.popExpect(i32); err != nil {
+			return err
+		}
+		ts := l.labelTypes()
+		if err := v.popAll(ts); err != nil {
+			return err
+		}
+		v.pushAll(ts)
+	case op == 0x0E: // br_table
+		n := r.count()
+		var depths []uint32
+		for i := 0; i <= n; i++ {
+			depths = append(depths, r.u32())
+		}
+		if err := v.popExpect(i32); err != nil {
+			return err
+		}
+		def, err := v.label(depths[n])
+		if err != nil {
+			return err
+		}
+		for _, d := range depths[:n] {
+			l, err := v.label(d)
+			if err != nil {
+				return err
+			}
+			if !sameTypes(l.labelTypes(), def.labelTypes()) {
+				return errors.New("type mismatch: br_table targets of different types")
+			}
+		}
+		if err := v.popAll(def.labelTypes()); err != nil {
+			return err
+		}
+		v.setUnreachable()
+	case op == 0x0F: // return
+		if err := v.popAll(f.typ.results); err != nil {
+			return err
+		}
+		v.setUnreachable()
+	case op == 0x10: // call
+		i := r.u32()
+		if int(i) >= len(m.funcs) {
+			return fmt.Errorf("invalid function index %d", i)
+		}
+		t := m.funcs[i].typ
+		if err := v.popAll(t.params); err != nil {
+			return err
+		}
+		v.pushAll(t.results)
+	case op == 0x11: // call_indirect
+		i := r.u32()
+		if int(i) >= len(m.types) {
+			return fmt.Errorf("invalid type index %d", i)
+		}
+		if r.byte() != 0x00 || m.table == nil {
+			return errors.New("call_indirect without table")
+		}
+		if err := v.popExpect(i32); err != nil {
+			return err
+		}
+		t := m.types[i]
+		if err := v.popAll(t.params); err != nil {
+			return err
+		}
+		v.pushAll(t.results)
+	case op == 0x1A: // drop
+		if _, err := v.pop(); err != nil {
+			return err
+		}
+	case op == 0x1B: // select
+		if err := v.popExpect(i32); err != nil {
+			return err
+		}
+		a, err := v.pop()
+		if err != nil {
+			return err
+		}
+		b, err := v.pop()
+		if err != nil {
+			return err
+		}
+		if a != b && a != unknown && b != unknown {
+			return errors.New("type mismatch: select of different types")
+		}
+		if a == unknown {
+			a = b
+		}
+		v.push(a)
+	case op >= 0x20 && op <= 0x22: // local.get, local.set, local.tee
+		i := r.u32()
+		if int(i) >= len(f.locals) {
+			return fmt.Errorf("invalid local index %d", i)
+		}
+		t := f.locals[i]
+		switch op {
+		case 0x20:
+			v.push(t)
+		case 0x21:
+			return v.popExpect(t)
+		case 0x22:
+			return v.op([]valType{t}, t)
+		}
+	case op == 0x23: // global.get
+		i := r.u32()
+		if int(i) >= len(m.globals) {
+			return fmt.Errorf("invalid global index %d", i)
+		}
+		v.push(m.globals[i].typ)
+	case op == 0x24: // global.set
+		i := r.u32()
+		if int(i) >= len(m.globals) {
+			return fmt.Errorf("invalid global index %d", i)
+		}
+		if !m.globals[i].mutable {
+			return fmt.Errorf("global %d is immutable", i)
+		}
+		return v.popExpect(m.globals[i].typ)
+	case op >= 0x28 && op <= 0x3E: // loads and stores
+		if m.memory == nil {
+			return errors.New("memory access without memory")
+		}
+		a := memAccesses[op-0x28]
+		if align := r.u32(); align >= 32 || 1<<align > a.size {
+			return errors.New("alignment larger than natural")
+		}
+		r.u32() // offset
+		if op <= 0x35 {
+			return v.op([]valType{i32}, a.typ)
+		}
+		return v.op([]valType{i32, a.typ}, unknown)
+	case op == 0x3F, op == 0x40: // memory.size, memory.grow
+		if r.byte() != 0x00 || m.memory == nil {
+			return errors.New("memory instruction without memory")
+		}
+		if op == 0x3F {
+			v.push(i32)
+			return nil
+		}
+		return v.op([]valType{i32}, i32)
+	case op == 0x41:
+		r.sleb(32)
+		v.push(i32)
+	case op == 0x42:
+		r.sleb(64)
+		v.push(i64)
+	case op == 0x43:
+		r.bytes(4)
+		v.push(f32)
+	case op == 0x44:
+		r.bytes(8)
+		v.push(f64)
+	case op >= 0x45 && op <= 0xC4:
+		s := numericSig(op)
+		return v.op(s.params, s.result)
+	case op == 0xFC:
+		sub := r.u32()
+		switch {
+		case sub <= 7: // saturating truncations
+			from, to := f32, i32
+			if sub%4 >= 2 {
+				from = f64
+			}
+			if sub >= 4 {
+				to = i64
+			}
+			return v.op([]valType{from}, to)
+		case sub == 10, sub == 11: // memory.copy, memory.fill
+			imm := 1
+			if sub == 10 {
+				imm = 2
+			}
+			for _, b := range r.bytes(imm) {
+				if b != 0x00 {
+					return errors.New("invalid memory index")
+				}
+			}
+			if m.memory == nil {
+				return errors.New("memory instruction without memory")
+			}
+			return v.op([]valType{i32, i32, i32}, unknown)
+		default:
+			return fmt.Errorf("unsupported instruction 0xfc %d", sub)
+		}
+	default:
+		return fmt.Errorf("unsupported instruction 0x%02x", op)
+	}
+
+	return nil
+}
+
+// memAccess is the type and size in bytes of a load or store.
+type memAccess struct {
+	typ  valType
+	size uint32
+}
+
+// memAccesses are the loads and stores from 0x28 to 0x3E.
+var memAccesses = []memAccess{
+	{i32, 4}, {i64, 8}, {f32, 4}, {f64, 8},
+	{i32, 1}, {i32, 1}, {i32, 2}, {i32, 2},
+	{i64, 1}, {i64, 1}, {i64, 2}, {i64, 2}, {i64, 4}, {i64, 4},
+	{i32, 4}, {i64, 8}, {f32, 4}, {f64, 8},
+	{i32, 1}, {i32, 2}, {i64, 1}, {i64, 2}, {i64, 4},
+}
+
+// signature is the type of a numeric instruction.
+type signature struct {
+	params []valType
+	result valType
+}
+
+// numericSig returns the type of the numeric instructions, from 0x45 to 0xC4.
+func numericSig(op byte) signature {
+	unary := func(t, r valType) signature { return signature{[]valType{t}, r} }
+	binary := func(t, r valType) signature { return signature{[]valType{t, t}, r} }
+
+	switch {
+	case op == 0x45:
+		return unary(i32, i32)
+	case op <= 0x4F:
+		return binary(i32, i32)
+	case op == 0x50:
+		return unary(i64, i32)
+	case op <= 0x5A:
+		return binary(i64, i32)
+	case op <= 0x60:
+		return binary(f32, i32)
+	case op <= 0x66:
+		return binary(f64, i32)
+	case op <= 0x69:
+		return unary(i32, i32)
+	case op <= 0x78:
+		return binary(i32, i32)
+	case op <= 0x7B:
+		return unary(i64, i64)
+	case op <= 0x8A:
+		return binary(i64, i64)
+	case op <= 0x91:
+		return unary(f32, f32)
+	case op <= 0x98:
+		return binary(f32, f32)
+	case op <= 0x9F:
+		return unary(f64, f64)
+	case op <= 0xA6:
+		return binary(f64, f64)
+	}
+
+	return conversions[op-0xA7]
+}
+
+// conversions are the types of the conversions from 0xA7 to 0xC4.
+var conversions = []signature{
+	{[]valType{i64}, i32},                        // i32.wrap_i64
+	{[]valType{f32}, i32}, {[]valType{f32}, i32}, // i32.trunc_f32
+	{[]valType{f64}, i32}, {[]valType{f64}, i32}, // i32.trunc_f64
+	{[]valType{i32}, i64}, {[]valType{i32}, i64}, // i64.extend_i32
+	{[]valType{f32}, i64}, {[]valType{f32}, i64}, // i64.trunc_f32
+	{[]valType{f64}, i64}, {[]valType{f64}, i64}, // i64.trunc_f64
+	{[]valType{i32}, f32}, {[]valType{i32}, f32}, // f32.convert_i32
+	{[]valType{i64}, f32}, {[]valType{i64}, f32}, // f32.convert_i64
+	{[]valType{f64}, f32},                        // f32.demote_f64
+	{[]valType{i32}, f64}, {[]valType{i32}, f64}, // f64.convert_i32
+	{[]valType{i64}, f64}, {[]valType{i64}, f64}, // f64.convert_i64
+	{[]valType{f32}, f64},                        // f64.promote_f32
+	{[]valType{f32}, i32},                        // i32.reinterpret_f32
+	{[]valType{f64}, i64},                        // i64.reinterpret_f64
+	{[]valType{i32}, f32},                        // f32.reinterpret_i32
+	{[]valType{i64}, f64},                        // f64.reinterpret_i64
+	{[]valType{i32}, i32}, {[]valType{i32}, i32}, // i32.extend8_s, i32.extend16_s
+	{[]valType{i64}, i64}, {[]valType{i64}, i64}, {[]valType{i64}, i64}, // i64.extend*_s
+}
+
+func sameTypes(a, b []valType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t valType) String() string {
+	switch t {
+	case i32:
+		return "i32"
+	case i64:
+		return "i64"
+	case f32:
+		return "f32"
+	case f64:
+		return "f64"
+	}
+	return "unknown"
+}
@@ -0,0 +1,316 @@
+package wasm
+
+import (
+	"image/color"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// leb encodes a signed LEB128 integer.
+func leb(v int64) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7F)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	b := []byte{byte(len(items))}
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func section(id byte, contents []byte) []byte {
+	return append([]byte{id, byte(len(contents))}, contents...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// moduleWith builds a module with a memory of one page and the given
+// functions, exported by name.
+func moduleWith(funcs ...testFunc) []byte {
+	var types, indices, exports, bodies [][]byte
+	for i, f := range funcs {
+		types = append(types, concat([]byte{0x60, byte(len(f.params))}, f.params, []byte{byte(len(f.results))}, f.results))
+		indices = append(indices, []byte{byte(i)})
+		if f.name != "" {
+			exports = append(exports, concat([]byte{byte(len(f.name))}, []byte(f.name), []byte{0x00, byte(i)}))
+		}
+		body := concat(vec(f.locals...), f.code, []byte{0x0B})
+		bodies = append(bodies, concat([]byte{byte(len(body))}, body))
+	}
+
+	return concat(
+		magic,
+		section(1, vec(types...)),
+		section(3, vec(indices...)),
+		section(5, []byte{0x01, 0x00, 0x01}),
+		section(7, vec(exports...)),
+		section(10, vec(bodies...)),
+	)
+}
+
+type testFunc struct {
+	name            string
+	params, results []byte
+	locals          [][]byte
+	code            []byte
+}
+
+func i32Const(v int32) []byte {
+	return append([]byte{0x41}, leb(int64(v))...)
+}
+
+// comet returns red for the pixel int(t) % size, and black for the others.
+var comet = testFunc{
+	name:    "pixel",
+	params:  []byte{0x7F, 0x7F, 0x7C},
+	results: []byte{0x7F},
+	code: concat(
+		[]byte{0x20, 0x00, 0x20, 0x02, 0xAA, 0x20, 0x01, 0x6F, 0x46},
+		[]byte{0x04, 0x7F}, i32Const(0xFF0000), []byte{0x05}, i32Const(0), []byte{0x0B},
+	),
+}
+
+func TestEffect(t *testing.T) {
+	e, err := New(moduleWith(comet), &ring.LayerOptions{Resolution: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	black := color.RGBA{0x00, 0x00, 0x00, 0xFF}
+	tests := []struct {
+		dt   time.Duration
+		want []color.Color
+	}{
+		{0, []color.Color{red, black, black}},
+		{time.Second, []color.Color{black, red, black}},
+		{2 * time.Second, []color.Color{red, black, black}},
+	}
+
+	for _, ts := range tests {
+		if done := e.Tick(ts.dt); done {
+			t.Fatalf("got: done after %v, want: running (%v)", ts.dt, e.Err())
+		}
+		for i, want := range ts.want {
+			if got := color.RGBAModel.Convert(e.Pixel(i)); got != want {
+				t.Errorf("after %v, pixel %d got: %#v, want: %#v", ts.dt, i, got, want)
+			}
+		}
+	}
+}
+
+func TestEffectSwap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wasm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "effect.wasm")
+	if err := ioutil.WriteFile(path, moduleWith(comet), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := Load(path, &ring.LayerOptions{Resolution: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blue := comet
+	blue.code = i32Const(0x0000FF)
+	if err := ioutil.WriteFile(path, moduleWith(blue), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	want := color.RGBA{0x00, 0x00, 0xFF, 0xFF}
+	if got := color.RGBAModel.Convert(e.Pixel(1)); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+
+	broken := comet
+	broken.code = []byte{0x00}
+	if err := e.Swap(moduleWith(broken)); err == nil {
+		t.Errorf("got: nil, want: error")
+	}
+	if done := e.Tick(time.Second); done {
+		t.Errorf("got: done, want: running the previous module (%v)", e.Err())
+	}
+	if got := color.RGBAModel.Convert(e.Pixel(1)); got != want {
+		t.Errorf("got: %#v, want: %#v", got, want)
+	}
+}
+
+func TestEffectErrors(t *testing.T) {
+	pixel := func(code ...byte) testFunc {
+		f := comet
+		f.code = code
+		return f
+	}
+
+	tests := []struct {
+		name string
+		src  []byte
+		want string
+	}{
+		{"not wasm", []byte("hello"), "not a WebAssembly module"},
+		{"truncated", moduleWith(comet)[:20], "unexpected end"},
+		{"imports", concat(magic, section(2, []byte{0x01, 0x03, 'e', 'n', 'v', 0x01, 'f', 0x00, 0x00})), "imports are not supported"},
+		{"no pixel", moduleWith(testFunc{name: "draw", code: []byte{}}), "does not export pixel"},
+		{"signature", moduleWith(testFunc{name: "pixel", results: []byte{0x7F}, code: i32Const(0)}), "pixel must be"},
+		{"unreachable", moduleWith(pixel(0x00)), "trap: unreachable"},
+		{"infinite loop", moduleWith(pixel(0x03, 0x40, 0x0C, 0x00, 0x0B, 0x41, 0x00)), "trap: out of fuel"},
+		{"divide by zero", moduleWith(pixel(0x41, 0x01, 0x41, 0x00, 0x6D)), "trap: integer divide by zero"},
+		{"out of bounds", moduleWith(pixel(0x41, 0x80, 0x80, 0x04, 0x28, 0x02, 0x00)), "trap: out of bounds memory access"},
+		{"recursion", moduleWith(pixel(0x20, 0x00, 0x20, 0x01, 0x20, 0x02, 0x10, 0x00)), "trap: call stack exhausted"},
+		{"type mismatch", moduleWith(pixel(0x42, 0x00)), "type mismatch"},
+		{"missing operand", moduleWith(pixel(0x41, 0x00, 0x6A)), "not enough operands"},
+		{"invalid local", moduleWith(pixel(0x20, 0x09)), "invalid local index"},
+		{"invalid branch", moduleWith(pixel(0x0C, 0x05)), "invalid branch depth"},
+		{"invalid call", moduleWith(pixel(0x10, 0x07)), "invalid function index"},
+		{"if without else", moduleWith(pixel(0x41, 0x01, 0x04, 0x7F, 0x41, 0x00, 0x0B)), "needs an else"},
+	}
+
+	for _, ts := range tests {
+		_, err := New(ts.src, &ring.LayerOptions{Resolution: 2})
+		if err == nil || !strings.Contains(err.Error(), ts.want) {
+			t.Errorf("%s got: %v, want: error with %q", ts.name, err, ts.want)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	tests := []struct {
+		name   string
+		locals [][]byte
+		code   []byte
+		want   uint32
+	}{
+		{"arithmetic", nil, concat(i32Const(7), i32Const(-3), []byte{0x6C}, i32Const(2), []byte{0x6A}), 0xFFFFFFED},
+		{"unsigned division", nil, concat(i32Const(-1), i32Const(16), []byte{0x6E}), 0x0FFFFFFF},
+		{"rotation", nil, concat(i32Const(0x12345678), i32Const(8), []byte{0x77}), 0x34567812},
+		{"i64", nil, concat([]byte{0x42}, leb(1<<40), []byte{0x42}, leb(1<<40), []byte{0x7C, 0x42}, leb(32), []byte{0x88, 0xA7}), 0x200},
+		{"float", nil, concat([]byte{0x44, 0, 0, 0, 0, 0, 0, 0x04, 0x40}, []byte{0x9F, 0xAA}), 1},
+		{"saturation", nil, concat([]byte{0x44, 0, 0, 0, 0, 0, 0, 0xF0, 0xC1}, []byte{0xFC, 0x02}), 0x80000000},
+		{"memory", nil, concat(i32Const(8), i32Const(0x0A0B0C0D), []byte{0x36, 0x02, 0x00}, i32Const(9), []byte{0x2D, 0x00, 0x00}), 0x0C},
+		{"memory.grow", nil, concat(i32Const(1), []byte{0x40, 0x00, 0x1A, 0x3F, 0x00}), 2},
+		{"sum loop", [][]byte{{0x02, 0x7F}}, concat(
+			i32Const(10), []byte{0x21, 0x00},
+			[]byte{0x03, 0x40},
+			[]byte{0x20, 0x01, 0x20, 0x00, 0x6A, 0x21, 0x01},
+			[]byte{0x20, 0x00}, i32Const(1), []byte{0x6B, 0x22, 0x00, 0x0D, 0x00},
+			[]byte{0x0B, 0x20, 0x01},
+		), 55},
+		{"br_table", nil, concat(
+			[]byte{0x02, 0x40, 0x02, 0x40, 0x02, 0x40},
+			i32Const(1), []byte{0x0E, 0x02, 0x00, 0x01, 0x02},
+			[]byte{0x0B}, i32Const(10), []byte{0x0F},
+			[]byte{0x0B}, i32Const(20), []byte{0x0F},
+			[]byte{0x0B}, i32Const(30),
+		), 20},
+		{"select", nil, concat(i32Const(1), i32Const(2), i32Const(0), []byte{0x1B}), 2},
+		{"sign extension", nil, concat(i32Const(0x80), []byte{0xC0}), 0xFFFFFF80},
+	}
+
+	for _, ts := range tests {
+		m, err := decode(moduleWith(testFunc{results: []byte{0x7F}, locals: ts.locals, code: ts.code}))
+		if err != nil {
+			t.Errorf("%s: %v", ts.name, err)
+			continue
+		}
+		in, err := instantiate(m, frameFuel)
+		if err != nil {
+			t.Errorf("%s: %v", ts.name, err)
+			continue
+		}
+		in.fuel = frameFuel
+		got, err := in.invoke(0)
+		if err != nil {
+			t.Errorf("%s: %v", ts.name, err)
+			continue
+		}
+		if uint32(got) != ts.want {
+			t.Errorf("%s got: %#x, want: %#x", ts.name, got, ts.want)
+		}
+	}
+}
+
+// seedModules are valid modules to mutate when fuzzing.
+func seedModules() [][]byte {
+	loop := comet
+	loop.locals = [][]byte{{0x01, 0x7F}}
+	loop.code = concat(
+		[]byte{0x03, 0x40},
+		[]byte{0x20, 0x03}, i32Const(1), []byte{0x6A, 0x22, 0x03},
+		[]byte{0x20, 0x00, 0x49, 0x0D, 0x00, 0x0B},
+		[]byte{0x20, 0x03, 0x41, 0x04, 0x6C, 0x28, 0x02, 0x00},
+	)
+	frame := testFunc{name: "frame", params: []byte{0x7C}, code: concat(
+		i32Const(0), []byte{0x20, 0x00, 0xFC, 0x02, 0x36, 0x02, 0x00},
+		[]byte{0x02, 0x40, 0x41, 0x00, 0x0E, 0x01, 0x00, 0x00, 0x0B},
+	)}
+
+	return [][]byte{
+		moduleWith(comet),
+		moduleWith(loop),
+		moduleWith(comet, frame),
+	}
+}
+
+// runModule decodes and runs every exported function of a module, which may
+// fail or trap, but must not reach an error of the interpreter.
+func runModule(t *testing.T, src []byte) {
+	m, err := decode(src)
+	if err != nil {
+		return
+	}
+	in, err := instantiate(m, 1<<16)
+	if err == nil {
+		for _, f := range m.exports {
+			in.fuel = 1 << 16
+			args := make([]uint64, len(m.funcs[f].typ.params))
+			if _, err = in.invoke(f, args...); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil && strings.Contains(err.Error(), "internal error") {
+		t.Errorf("module %x got: %v, want: a trap", src, err)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, seed := range seedModules() {
+		runModule(t, seed)
+		for i := 0; i < 2000; i++ {
+			src := append([]byte(nil), seed...)
+			for n := 1 + rnd.Intn(4); n > 0; n-- {
+				src[len(magic)+rnd.Intn(len(src)-len(magic))] = byte(rnd.Intn(256))
+			}
+			runModule(t, src)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Watchdog turns off the LEDs of a ring when the program stops rendering it
+// for a while, so a stalled program does not leave the ring lit. The ring is
+// rendered again as usual on the next call to Render.
+type Watchdog struct {
+	r       *Ring
+	timeout time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewWatchdog starts watching a ring, turning off its LEDs whenever Render is
+// not called for a timeout, timed by the clock of the ring (see
+// Options.Clock). The timeout must be positive. Call Stop before closing the
+// ring.
+func NewWatchdog(r *Ring, timeout time.Duration) (*Watchdog, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("ring: watchdog timeout must be positive, got %v", timeout)
+	}
+
+	w := &Watchdog{
+		r:       r,
+		timeout: timeout,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+func (w *Watchdog) run() {
+	defer close(w.done)
+
+	clock := w.r.opt.clock()
+	start := clock.Now()
+	var blanked time.Time // last render before the LEDs were turned off
+	for {
+		last := w.r.lastRender()
+		if last.Before(start) {
+			last = start
+		}
+
+		wait := w.timeout - clock.Now().Sub(last)
+		if wait <= 0 {
+			if !last.Equal(blanked) {
+				w.r.opt.logger().Warn("ring stopped rendering, turning off the LEDs", "timeout", w.timeout)
+				w.r.TurnOff()
+				blanked = last
+			}
+			wait = w.timeout
+		}
+
+		select {
+		case <-w.stop:
+			return
+		case <-clock.After(wait):
+		}
+	}
+}
+
+// Stop stops watching the ring, and returns once the watchdog is stopped.
+func (w *Watchdog) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+	<-w.done
+}
+
+// lastRender returns the time of the last call to Render.
+func (r *Ring) lastRender() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rendered
+}
+
+// Recover turns off the LEDs of the ring if the calling goroutine panics, and
+// then panics again with the same value. It must be deferred:
+//
+//	defer r.Recover()
+//
+// Panics in other goroutines are not recovered.
+func (r *Ring) Recover() {
+	if v := recover(); v != nil {
+		r.opt.logger().Error("program panicked, turning off the LEDs", "panic", v)
+		r.TurnOff()
+		panic(v)
+	}
+}
@@ -0,0 +1,95 @@
+package ring
+
+import (
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedDevice is a fakeDevice that can be read while rendered to by other
+// goroutines.
+type lockedDevice struct {
+	mu  sync.Mutex
+	dev fakeDevice
+}
+
+func (d *lockedDevice) Render(frame []uint32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dev.Render(frame)
+}
+
+func (d *lockedDevice) Close() {}
+
+func (d *lockedDevice) led(i int) uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dev.leds[i]
+}
+
+func TestWatchdog(t *testing.T) {
+	dev := &lockedDevice{dev: fakeDevice{leds: make([]uint32, 2)}}
+	r := newRing(dev, &Options{LedCount: 2, MaxBrightness: 255})
+	l := newTestLayer(t, &LayerOptions{Resolution: 2})
+	l.SetAll(color.White)
+	r.AddLayer(l)
+
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		if _, err := NewWatchdog(r, timeout); err == nil {
+			t.Errorf("timeout %v got: nil, want: error", timeout)
+		}
+	}
+	w, err := NewWatchdog(r, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.led(0), uint32(0xFFFFFF); got != want {
+		t.Fatalf("got: %#x, want: %#x", got, want)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for dev.led(0) != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got, want := dev.led(0), uint32(0); got != want {
+		t.Errorf("got: %#x after the timeout, want: %#x", got, want)
+	}
+
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.led(0), uint32(0xFFFFFF); got != want {
+		t.Errorf("got: %#x after rendering again, want: %#x", got, want)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	r, dev := newTestRing(t, 2)
+	l := newTestLayer(t, &LayerOptions{Resolution: 2})
+	l.SetAll(color.White)
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("got: panic %#v, want: %#v", v, "boom")
+			}
+		}()
+		defer r.Recover()
+		panic("boom")
+	}()
+
+	if got, want := dev.leds[0], uint32(0); got != want {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+}
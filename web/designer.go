@@ -0,0 +1,286 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/cgxeiji/ring"
+)
+
+// sceneName is the pattern of the names of saved scenes, which are used as
+// file names.
+var sceneName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Designs are bounded, so a single request cannot make the designer run out
+// of memory.
+const (
+	// maxDesignSize is the most bytes of a design posted as JSON.
+	maxDesignSize = 1 << 20
+	// maxDesignPixels is the most pixels of all the layers and effects of a
+	// design.
+	maxDesignPixels = 1 << 16
+)
+
+// Designer is a handler that serves a web page to design scenes on a ring:
+// layers can be added and removed, their colors picked and their rotation,
+// spin and opacity set, and effects of ring.DefaultRegistry added on top with
+// the values of their parameters, while the ring shows the result live. Scenes
+// are saved as scene files that ring.LoadScene can load, and their effects as
+// a preset of the same name in the file presets.json (see ring.OpenPresets).
+//
+// The page is served at the root of the handler, so it can be mounted under a
+// prefix with http.StripPrefix:
+//
+//	http.Handle("/designer/", http.StripPrefix("/designer", web.NewDesigner(r, "scenes")))
+//
+// The handler also serves the design at /scene, which can be replaced with a
+// POST of a Design in JSON, the effects of the registry with their parameters
+// at /effects, the frames of the ring at /frames (see WebSocket) and a video of
+// the ring at /stream (see MJPEG).
+//
+// POST requests from the pages of other sites are rejected, by their Origin
+// header, so visiting a web page cannot change the ring or write files. The
+// designer has no authentication: serve it only on trusted networks.
+type Designer struct {
+	r   *ring.Ring
+	dir string
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	design  Design
+	scene   *ring.Scene    // scene shown on the ring
+	effects []ring.Pixeler // effects shown on the ring
+}
+
+// Design is a scene being designed, with effects on top of its layers.
+type Design struct {
+	ring.SceneSpec
+	// Effects are effects of ring.DefaultRegistry, from bottom to top.
+	Effects []ring.PresetLayer `json:"effects"`
+}
+
+// NewDesigner creates a designer of the scenes of a ring, which saves the
+// scenes as JSON files in a directory. If dir is empty, scenes cannot be
+// saved.
+func NewDesigner(r *ring.Ring, dir string) *Designer {
+	d := &Designer{
+		r:   r,
+		dir: dir,
+		mux: http.NewServeMux(),
+	}
+	d.mux.HandleFunc("/", d.serveIndex)
+	d.mux.HandleFunc("/scene", d.serveScene)
+	d.mux.HandleFunc("/save", d.serveSave)
+	d.mux.HandleFunc("/effects", d.serveEffects)
+	d.mux.Handle("/frames", &WebSocket{Ring: r})
+	d.mux.Handle("/stream", &MJPEG{Ring: r})
+
+	return d
+}
+
+func (d *Designer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost && !sameOrigin(req) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+	d.mux.ServeHTTP(w, req)
+}
+
+// sameOrigin reports whether a request comes from a page of the same host, or
+// from a client that is not a browser, which sends no Origin header.
+func sameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == req.Host
+}
+
+func (d *Designer) serveIndex(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/" {
+		http.NotFound(w, req)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, designerHTML)
+}
+
+func (d *Designer) serveScene(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		writeJSON(w, &d.design)
+	case http.MethodPost:
+		var design Design
+		body := http.MaxBytesReader(w, req.Body, maxDesignSize)
+		if err := json.NewDecoder(body).Decode(&design); err != nil {
+			http.Error(w, fmt.Sprintf("invalid scene: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := d.SetDesign(&design); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (d *Designer) serveSave(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path, err := d.Save()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]string{"path": path})
+}
+
+// paramView is a parameter of an effect, as served at /effects.
+type paramView struct {
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	Default string  `json:"default"`
+	Doc     string  `json:"doc"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+}
+
+// effectView is an effect of the registry, as served at /effects.
+type effectView struct {
+	Name   string      `json:"name"`
+	Doc    string      `json:"doc"`
+	Params []paramView `json:"params"`
+}
+
+func (d *Designer) serveEffects(w http.ResponseWriter, req *http.Request) {
+	specs := ring.DefaultRegistry.Effects()
+	views := make([]effectView, len(specs))
+	for i, spec := range specs {
+		views[i] = effectView{Name: spec.Name, Doc: spec.Doc, Params: make([]paramView, len(spec.Params))}
+		for j, ps := range spec.Params {
+			views[i].Params[j] = paramView{
+				Name:    ps.Name,
+				Type:    ps.Type.String(),
+				Default: ps.Default,
+				Doc:     ps.Doc,
+				Min:     ps.Min,
+				Max:     ps.Max,
+			}
+		}
+	}
+	writeJSON(w, views)
+}
+
+// SetScene replaces the scene shown on the ring, and keeps its effects.
+func (d *Designer) SetScene(spec *ring.SceneSpec) error {
+	d.mu.Lock()
+	design := Design{SceneSpec: *spec, Effects: d.design.Effects}
+	d.mu.Unlock()
+
+	return d.SetDesign(&design)
+}
+
+// SetDesign replaces the scene and the effects shown on the ring. If the
+// scene or any effect cannot be created, or the layers and effects have more
+// than 65536 pixels together, the ring is not changed.
+func (d *Designer) SetDesign(design *Design) error {
+	left := maxDesignPixels - len(design.Effects)*d.r.Size()
+	for _, ls := range design.Layers {
+		if left < 0 {
+			break
+		}
+		left -= ls.Resolution
+	}
+	if left < 0 {
+		return fmt.Errorf("web: design has more than %d pixels", maxDesignPixels)
+	}
+	s, err := ring.NewScene(&design.SceneSpec)
+	if err != nil {
+		return err
+	}
+	p := &ring.Preset{Name: design.Name, Layers: design.Effects}
+	effects, err := p.Effects(ring.DefaultRegistry, d.r.Size())
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if old := d.scene; old != nil {
+		for _, tl := range old.Timelines {
+			d.r.Animator().Stop(tl)
+		}
+		for _, l := range old.Layers {
+			d.r.RemoveLayer(l)
+		}
+	}
+	for _, e := range d.effects {
+		d.r.RemoveEffect(e)
+	}
+	s.AddTo(d.r)
+	for _, e := range effects {
+		d.r.AddEffect(e)
+	}
+	d.scene = s
+	d.effects = effects
+	d.design = *design
+
+	return nil
+}
+
+// Save writes the scene as a JSON file named after the scene in the directory
+// of the designer, and returns the path of the file. If the design has
+// effects, they are saved as a preset named after the scene in the file
+// presets.json of the directory.
+func (d *Designer) Save() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dir == "" {
+		return "", fmt.Errorf("web: saving scenes is disabled")
+	}
+	name := d.design.Name
+	if !sceneName.MatchString(name) || name == "presets" {
+		return "", fmt.Errorf("web: invalid scene name %q, use letters, digits, - and _", name)
+	}
+	data, err := json.MarshalIndent(&d.design.SceneSpec, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(d.dir, name+".json")
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("web: could not save scene: %w", err)
+	}
+	if len(d.design.Effects) > 0 {
+		store, err := ring.OpenPresets(filepath.Join(d.dir, "presets.json"))
+		if err != nil {
+			return "", fmt.Errorf("web: could not save effects: %w", err)
+		}
+		if err := store.Save(&ring.Preset{Name: name, Layers: d.design.Effects}); err != nil {
+			return "", fmt.Errorf("web: could not save effects: %w", err)
+		}
+	}
+
+	return path, nil
+}
+
+// writeJSON writes a value as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
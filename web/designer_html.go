@@ -0,0 +1,164 @@
+package web
+
+// designerHTML is the page of the designer. It only uses relative URLs, so the
+// designer can be mounted under any prefix.
+const designerHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Ring designer</title>
+<style>
+body { font-family: sans-serif; background: #111; color: #eee; margin: 0; display: flex; flex-wrap: wrap; }
+#preview { padding: 1em; }
+#editor { padding: 1em; flex: 1; min-width: 20em; }
+.layer { border: 1px solid #444; border-radius: 4px; padding: 0.5em; margin-bottom: 0.5em; }
+label { display: inline-block; margin: 0.2em 0.5em 0.2em 0; }
+input[type=number] { width: 5em; }
+button { margin: 0.2em 0.5em 0.2em 0; }
+#status { color: #aaa; }
+</style>
+</head>
+<body>
+<div id="preview"><canvas id="ring" width="320" height="320"></canvas></div>
+<div id="editor">
+	<label>Scene <input id="name" placeholder="name"></label>
+	<div id="layers"></div>
+	<button id="add">Add layer</button>
+	<div id="effects"></div>
+	<select id="effect"></select>
+	<button id="add-effect">Add effect</button>
+	<button id="save">Save</button>
+	<span id="status"></span>
+</div>
+<script>
+"use strict";
+let scene = {name: "", layers: [], effects: []};
+let registry = [];
+
+function el(tag, attrs, children) {
+	const e = document.createElement(tag);
+	Object.assign(e, attrs || {});
+	(children || []).forEach(c => e.append(c));
+	return e;
+}
+
+function field(layer, key, label, attrs, parse) {
+	const input = el("input", attrs);
+	input.value = layer[key] === undefined || layer[key] === null ? "" : layer[key];
+	input.oninput = () => { layer[key] = parse ? parse(input.value) : input.value; apply(); };
+	return el("label", {}, [label + " ", input]);
+}
+
+function draw() {
+	const layers = document.getElementById("layers");
+	layers.replaceChildren();
+	scene.layers.forEach((layer, i) => {
+		const remove = el("button", {textContent: "Remove", onclick: () => { scene.layers.splice(i, 1); draw(); apply(); }});
+		layers.append(el("div", {className: "layer"}, [
+			field(layer, "name", "Name", {}),
+			field(layer, "resolution", "Pixels", {type: "number", min: 1}, Number),
+			field(layer, "color", "Color", {type: "color"}),
+			field(layer, "rotation", "Rotation", {type: "number", step: 15}, Number),
+			field(layer, "spin", "Spin", {type: "number", step: 15}, Number),
+			field(layer, "opacity", "Opacity", {type: "range", min: 0, max: 1, step: 0.05}, Number),
+			remove,
+		]));
+	});
+	const effects = document.getElementById("effects");
+	effects.replaceChildren();
+	scene.effects.forEach((effect, i) => {
+		const spec = registry.find(e => e.name === effect.effect) || {params: []};
+		effect.params = effect.params || {};
+		const remove = el("button", {textContent: "Remove", onclick: () => { scene.effects.splice(i, 1); draw(); apply(); }});
+		effects.append(el("div", {className: "layer", title: spec.doc || ""}, [
+			el("strong", {textContent: effect.effect + " "}),
+			...spec.params.map(p => param(effect.params, p)),
+			remove,
+		]));
+	});
+	document.getElementById("name").value = scene.name || "";
+}
+
+function param(values, p) {
+	let attrs = {title: p.doc};
+	let parse = String;
+	if (p.type === "float" || p.type === "int") {
+		attrs.type = p.min === 0 && p.max === 0 ? "number" : "range";
+		if (attrs.type === "range") {
+			attrs.min = p.min;
+			attrs.max = p.max;
+			attrs.step = p.type === "int" ? 1 : (p.max - p.min) / 100;
+		}
+	} else if (p.type === "color" && /^#[0-9a-f]{6}$/i.test(values[p.name] || p.default)) {
+		attrs.type = "color";
+	}
+	if (values[p.name] === undefined) {
+		values[p.name] = p.default;
+	}
+	return field(values, p.name, p.name, attrs, parse);
+}
+
+let pending = null;
+function apply() {
+	clearTimeout(pending);
+	pending = setTimeout(() => {
+		fetch("scene", {method: "POST", body: JSON.stringify(scene)})
+			.then(r => r.ok ? status("") : r.text().then(status));
+	}, 100);
+}
+
+function status(msg) {
+	document.getElementById("status").textContent = msg;
+}
+
+document.getElementById("name").oninput = e => { scene.name = e.target.value; apply(); };
+document.getElementById("add").onclick = () => {
+	scene.layers.push({name: "layer" + (scene.layers.length + 1), resolution: 1, content_mode: "scale", color: "#ff8800", opacity: 1});
+	draw();
+	apply();
+};
+document.getElementById("add-effect").onclick = () => {
+	scene.effects.push({effect: document.getElementById("effect").value, params: {}});
+	draw();
+	apply();
+};
+document.getElementById("save").onclick = () => {
+	fetch("save", {method: "POST"})
+		.then(r => r.ok ? r.json().then(v => status("saved to " + v.path)) : r.text().then(status));
+};
+
+fetch("effects").then(r => r.json()).then(effects => {
+	registry = effects;
+	const select = document.getElementById("effect");
+	effects.forEach(e => select.append(el("option", {value: e.name, textContent: e.name, title: e.doc})));
+	return fetch("scene");
+}).then(r => r.json()).then(s => {
+	scene = s;
+	scene.layers = scene.layers || [];
+	scene.effects = scene.effects || [];
+	draw();
+});
+
+const canvas = document.getElementById("ring");
+const ctx = canvas.getContext("2d");
+const base = location.href.replace(/[^/]*$/, "").replace(/^http/, "ws");
+const socket = new WebSocket(base + "frames?format=json");
+socket.onmessage = e => {
+	const leds = JSON.parse(e.data).leds;
+	const c = canvas.width / 2, r = 0.85 * c;
+	const dot = Math.min(0.8 * r * Math.sin(Math.PI / leds.length), 0.12 * c);
+	ctx.fillStyle = "#000";
+	ctx.fillRect(0, 0, canvas.width, canvas.height);
+	leds.forEach((color, i) => {
+		const a = 2 * Math.PI * i / leds.length;
+		ctx.fillStyle = color;
+		ctx.beginPath();
+		ctx.arc(c + r * Math.sin(a), c - r * Math.cos(a), dot, 0, 2 * Math.PI);
+		ctx.fill();
+	});
+};
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestDesigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 2, MaxBrightness: 255})
+	srv := httptest.NewServer(http.StripPrefix("/designer", NewDesigner(r, dir)))
+	defer srv.Close()
+	url := srv.URL + "/designer/"
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got, want := resp.Header.Get("Content-Type"), "text/html; charset=utf-8"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+
+	for _, ts := range []struct {
+		scene string
+		code  int
+		want  uint32
+	}{
+		{`{"name": "sunset", "layers": [{"resolution": 1, "content_mode": "scale", "color": "#ff8800"}]}`, http.StatusNoContent, 0xFF8800},
+		{`{"name": "sunset", "layers": [{"resolution": 1, "content_mode": "scale", "color": "blue"}]}`, http.StatusNoContent, 0x0000FF},
+		{`{"name": "sunset", "layers": [{"resolution": 0}]}`, http.StatusBadRequest, 0x0000FF},
+		{`{"name": "sunset", "layers": [{"resolution": -3}]}`, http.StatusBadRequest, 0x0000FF},
+		{`{"name": "sunset", "layers": [{"resolution": 1000000000000}]}`, http.StatusBadRequest, 0x0000FF},
+		{`{"name": "sunset", "layers": [{"resolution": 40000}, {"resolution": 40000}]}`, http.StatusBadRequest, 0x0000FF},
+		{`{"name": "sunset", "layers": [` + strings.Repeat(`{"resolution": 1},`, 1<<16) + `{"resolution": 1}]}`, http.StatusBadRequest, 0x0000FF},
+	} {
+		resp, err := http.Post(url+"scene", "application/json", strings.NewReader(ts.scene))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != ts.code {
+			t.Errorf("%.80s got: status %d, want: %d", ts.scene, resp.StatusCode, ts.code)
+		}
+		if err := r.Render(); err != nil {
+			t.Fatal(err)
+		}
+		if got := dev.Frame()[1]; got != ts.want {
+			t.Errorf("%.80s got: %#x, want: %#x", ts.scene, got, ts.want)
+		}
+	}
+	if got, want := len(r.Layers()), 1; got != want {
+		t.Errorf("got: %d layers, want: %d", got, want)
+	}
+
+	resp, err = http.Post(url+"save", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct{ Path string }
+	err = json.NewDecoder(resp.Body).Decode(&saved)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := saved.Path, filepath.Join(dir, "sunset.json"); got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	s, err := ring.LoadScene(saved.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Name, "sunset"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestDesignerEffects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, dev := ringtest.NewRing(t, &ring.Options{LedCount: 2, MaxBrightness: 255})
+	srv := httptest.NewServer(NewDesigner(r, dir))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/effects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var effects []struct {
+		Name   string
+		Params []struct{ Name, Type string }
+	}
+	err = json.NewDecoder(resp.Body).Decode(&effects)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(effects), len(ring.DefaultRegistry.Effects()); got != want {
+		t.Errorf("got: %d effects, want: %d", got, want)
+	}
+
+	design := `{"name": "glow", "layers": [], "effects": [{"effect": "solid", "params": {"color": "#00ff00"}}]}`
+	resp, err = http.Post(srv.URL+"/scene", "application/json", strings.NewReader(design))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusNoContent; got != want {
+		t.Fatalf("got: status %d, want: %d", got, want)
+	}
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dev.Frame()[0], uint32(0x00FF00); got != want {
+		t.Errorf("got: %#x, want: %#x", got, want)
+	}
+
+	resp, err = http.Post(srv.URL+"/save", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	store, err := ring.OpenPresets(filepath.Join(dir, "presets.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := store.Get("glow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.Layers[0].Params["color"], "#00ff00"; got != want {
+		t.Errorf("got: saved color %q, want: %q", got, want)
+	}
+}
+
+func TestDesignerOrigin(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 2})
+	srv := httptest.NewServer(NewDesigner(r, ""))
+	defer srv.Close()
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusNoContent},
+		{srv.URL, http.StatusNoContent},
+		{"http://evil.example", http.StatusForbidden},
+	}
+
+	for _, ts := range tests {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/scene", strings.NewReader(`{"name": "x"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ts.origin != "" {
+			req.Header.Set("Origin", ts.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.StatusCode; got != ts.want {
+			t.Errorf("origin %q got: status %d, want: %d", ts.origin, got, ts.want)
+		}
+	}
+}
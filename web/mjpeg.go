@@ -0,0 +1,108 @@
+// Package web serves rings over HTTP, to monitor the LEDs remotely, such as
+// rings installed out of sight, as a video (see MJPEG) or as a stream of
+// frames for custom dashboards (see WebSocket):
+//
+//	r, err := ring.New(&ring.Options{LedCount: 12})
+//	...
+//	http.Handle("/stream", &web.MJPEG{Ring: r})
+//	http.Handle("/frames", &web.WebSocket{Ring: r})
+//	log.Fatal(http.ListenAndServe(":8080", nil))
+//
+// The frames are the frames last rendered by the ring (see ring.Ring.Snapshot),
+// so the ring must be rendered, usually by ring.Ring.Run.
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"image/jpeg"
+	"net/http"
+	"net/textproto"
+	"time"
+
+	"github.com/cgxeiji/ring"
+)
+
+// mjpegBoundary separates the frames of a Motion JPEG stream.
+const mjpegBoundary = "ringframe"
+
+// MJPEG is a handler that streams the frames of a ring as a Motion JPEG video,
+// drawn as a circle of LEDs (see ring.Ring.CircleImage). Browsers show the
+// stream in an <img> element.
+type MJPEG struct {
+	// Ring is the ring to stream.
+	Ring *ring.Ring
+	// FPS is the number of frames sent per second (default: 10).
+	FPS float64
+	// Size is the width and height of the frames, in pixels (default: 256).
+	Size int
+	// Quality is the JPEG quality of the frames, from 1 to 100 (default:
+	// jpeg.DefaultQuality).
+	Quality int
+}
+
+func (m *MJPEG) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	fps, size, quality := m.FPS, m.Size, m.Quality
+	if fps <= 0 {
+		fps = 10
+	}
+	if size <= 0 {
+		size = 256
+	}
+	if quality <= 0 {
+		quality = jpeg.DefaultQuality
+	}
+
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mjpegBoundary)
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	ticker := m.Ring.Clock().NewTicker(time.Duration(float64(time.Second) / fps))
+	defer ticker.Stop()
+
+	var buf bytes.Buffer
+	for {
+		buf.Reset()
+		if err := jpeg.Encode(&buf, m.Ring.CircleImage(size), &jpeg.Options{Quality: quality}); err != nil {
+			return
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "image/jpeg")
+		header.Set("Content-Length", fmt.Sprint(buf.Len()))
+		if err := writePart(w, header, buf.Bytes()); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// writePart writes a part of a multipart stream, with its boundary.
+func writePart(w http.ResponseWriter, header textproto.MIMEHeader, body []byte) error {
+	if _, err := fmt.Fprintf(w, "--%s\r\n", mjpegBoundary); err != nil {
+		return err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			if _, err := fmt.Fprintf(w, "%s: %s\r\n", k, v); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprint(w, "\r\n"); err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(w, "\r\n")
+
+	return err
+}
@@ -0,0 +1,70 @@
+package web
+
+import (
+	"context"
+	"image/color"
+	"image/jpeg"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestMJPEG(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 4})
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetPixel(0, color.RGBA{0xFF, 0x00, 0x00, 0xFF})
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(&MJPEG{Ring: r, Size: 64, FPS: 100})
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "multipart/x-mixed-replace"; mediaType != want {
+		t.Fatalf("got: %q, want: %q", mediaType, want)
+	}
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for i := 0; i < 2; i++ {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := jpeg.Decode(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := img.Bounds().Dx(), 64; got != want {
+			t.Errorf("got: %d pixels wide, want: %d", got, want)
+		}
+		// The first LED is at the top, and the center is dark.
+		top, _, _, _ := img.At(32, 5).RGBA()
+		center, _, _, _ := img.At(32, 32).RGBA()
+		if top < 0xC000 || center > 0x2000 {
+			t.Errorf("got: top %#x, center %#x, want: a red LED at the top", top, center)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/cgxeiji/ring"
+)
+
+// WebSocket is a handler that streams the frames of a ring over a WebSocket,
+// for custom dashboards and previews. A frame is sent whenever it changes, as
+// a binary message with 3 bytes (R, G, B) per LED, by physical position. With
+// the query ?format=json, frames are sent as text messages instead, with the
+// colors as hex strings:
+//
+//	{"leds":["#ff0000","#000000",...]}
+type WebSocket struct {
+	// Ring is the ring to stream.
+	Ring *ring.Ring
+	// FPS is the number of times per second the frame is checked for changes
+	// (default: 30).
+	FPS float64
+	// CheckOrigin reports whether a request from a web page of another origin
+	// is allowed (default: nil, only the same origin).
+	CheckOrigin func(req *http.Request) bool
+}
+
+func (s *WebSocket) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	fps := s.FPS
+	if fps <= 0 {
+		fps = 30
+	}
+	asJSON := req.URL.Query().Get("format") == "json"
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.CheckOrigin}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Read the messages of the client, which are ignored, to notice when it
+	// closes the connection.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := s.Ring.Clock().NewTicker(time.Duration(float64(time.Second) / fps))
+	defer ticker.Stop()
+
+	var last []byte
+	for {
+		frame := encodeFrame(s.Ring.Snapshot())
+		if !bytes.Equal(frame, last) {
+			var err error
+			if asJSON {
+				err = conn.WriteMessage(websocket.TextMessage, frameJSON(frame))
+			} else {
+				err = conn.WriteMessage(websocket.BinaryMessage, frame)
+			}
+			if err != nil {
+				return
+			}
+			last = frame
+		}
+
+		select {
+		case <-closed:
+			return
+		case <-req.Context().Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// encodeFrame encodes the colors of the LEDs as 3 bytes (R, G, B) per LED,
+// blended over black.
+func encodeFrame(leds []color.Color) []byte {
+	frame := make([]byte, 0, 3*len(leds))
+	for _, c := range leds {
+		r, g, b, _ := c.RGBA()
+		frame = append(frame, byte(r>>8), byte(g>>8), byte(b>>8))
+	}
+
+	return frame
+}
+
+// frameJSON converts an encoded frame to a JSON object with the colors of the
+// LEDs as hex strings.
+func frameJSON(frame []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"leds":[`)
+	for i := 0; i < len(frame); i += 3 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `"#%02x%02x%02x"`, frame[i], frame[i+1], frame[i+2])
+	}
+	buf.WriteString(`]}`)
+
+	return buf.Bytes()
+}
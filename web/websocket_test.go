@@ -0,0 +1,58 @@
+package web
+
+import (
+	"image/color"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/cgxeiji/ring"
+	"github.com/cgxeiji/ring/ringtest"
+)
+
+func TestWebSocket(t *testing.T) {
+	r, _ := ringtest.NewRing(t, &ring.Options{LedCount: 2})
+	l, err := ring.NewLayer(&ring.LayerOptions{Resolution: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetPixel(0, color.RGBA{0xFF, 0x88, 0x00, 0xFF})
+	r.AddLayer(l)
+	if err := r.Render(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(&WebSocket{Ring: r, FPS: 100})
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	tests := []struct {
+		name  string
+		query string
+		typ   int
+		want  string
+	}{
+		{"binary", "", websocket.BinaryMessage, "\xFF\x88\x00\x00\x00\x00"},
+		{"json", "?format=json", websocket.TextMessage, `{"leds":["#ff8800","#000000"]}`},
+	}
+
+	for _, ts := range tests {
+		t.Run(ts.name, func(t *testing.T) {
+			conn, _, err := websocket.DefaultDialer.Dial(url+ts.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != ts.typ || string(msg) != ts.want {
+				t.Errorf("got: %d %q, want: %d %q", typ, msg, ts.typ, ts.want)
+			}
+		})
+	}
+}
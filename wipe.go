@@ -0,0 +1,202 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+)
+
+// wipeCover returns how much of the pixel at position i of a ring of size
+// pixels a wipe covers, from 0.0 to 1.0, when its front has moved t of the way
+// around the ring from an angle (in radians), in the direction of the pixel
+// indices or, reversed, against it.
+func wipeCover(i, size int, t, start float64, reverse bool) float64 {
+	n := float64(size)
+	s := start * n / (2 * math.Pi)
+	k := float64(i) - s
+	if reverse {
+		k = -k
+	}
+	k -= n * math.Floor(k/n) // distance from the start, along the wipe
+	if k > n-0.5 {
+		k -= n // the pixel at the start is covered first
+	}
+
+	return math.Max(0, math.Min(1, t*n-(k-0.5)))
+}
+
+// WipeTransition is a Transition that sweeps the new scene around the ring
+// from an angle (in radians), in the direction of the pixel indices or,
+// reversed, against it.
+func WipeTransition(start float64, reverse bool) Transition {
+	return func(i, size int, t float64) float64 {
+		return wipeCover(i, size, t, start, reverse)
+	}
+}
+
+// ColorWipe is a layer that sweeps a color around the ring, from an angle,
+// over the colors it shows. Add it to a ring with AddLayer and run the ring
+// (see Ring.Run) to animate it. Its parameters are "color", "start" in
+// degrees, "duration" in seconds and "reverse", 1 to sweep against the
+// direction of the pixel indices (see Tunable).
+type ColorWipe struct {
+	*Layer
+	params
+
+	mu       sync.Mutex
+	color    color.Color
+	from     []color.RGBA64 // pixels before the current wipe
+	start    float64        // angle in radians
+	reverse  bool
+	duration time.Duration
+	elapsed  time.Duration
+}
+
+// NewColorWipe creates a transparent layer that wipes a color around the ring
+// from the first pixel in 1 second.
+func NewColorWipe(c color.Color, options *LayerOptions) (*ColorWipe, error) {
+	l, err := NewLayer(options)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ColorWipe{
+		Layer:    l,
+		from:     make([]color.RGBA64, l.opt.Resolution),
+		duration: time.Second,
+	}
+	w.params = params{specs: wipeParams, get: w.param, set: w.setParam}
+	w.Wipe(c)
+
+	return w, nil
+}
+
+// Wipe starts sweeping a color over the colors the layer shows.
+func (w *ColorWipe) Wipe(c color.Color) {
+	w.mu.Lock()
+	for i := range w.from {
+		w.from[i] = w.paint(i)
+	}
+	w.color = c
+	w.elapsed = 0
+	w.mu.Unlock()
+
+	w.draw()
+}
+
+// SetStart sets the angle from which the wipes start (in radians).
+func (w *ColorWipe) SetStart(angle float64) {
+	w.mu.Lock()
+	w.start = angle
+	w.mu.Unlock()
+
+	w.draw()
+}
+
+// SetReverse makes the wipes sweep against the direction of the pixel indices.
+func (w *ColorWipe) SetReverse(reverse bool) {
+	w.mu.Lock()
+	w.reverse = reverse
+	w.mu.Unlock()
+
+	w.draw()
+}
+
+// SetDuration sets how long a wipe takes to go around the ring.
+func (w *ColorWipe) SetDuration(d time.Duration) {
+	w.mu.Lock()
+	w.duration = d
+	w.mu.Unlock()
+
+	w.draw()
+}
+
+// Done reports whether the current wipe has covered the whole ring.
+func (w *ColorWipe) Done() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.elapsed >= w.duration
+}
+
+var wipeParams = []ParamSpec{
+	{Name: "color", Type: ParamColor, Default: "#ffffff", Doc: "color swept around the ring, starting a new wipe"},
+	{Name: "start", Type: ParamFloat, Default: "0", Doc: "angle where the wipes start, in degrees", Min: -360, Max: 360},
+	{Name: "duration", Type: ParamFloat, Default: "1", Doc: "seconds to sweep around the ring", Min: 0, Max: 60},
+	{Name: "reverse", Type: ParamInt, Default: "0", Doc: "1 to sweep against the direction of the pixels", Min: 0, Max: 1},
+}
+
+func (w *ColorWipe) param(name string) interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch name {
+	case "color":
+		return w.color
+	case "start":
+		return w.start * 180 / math.Pi
+	case "duration":
+		return w.duration.Seconds()
+	case "reverse":
+		if w.reverse {
+			return 1
+		}
+		return 0
+	}
+	return nil
+}
+
+func (w *ColorWipe) setParam(name string, v interface{}) {
+	switch name {
+	case "color":
+		w.Wipe(v.(color.Color))
+	case "start":
+		w.SetStart(radians(v.(float64)))
+	case "duration":
+		w.SetDuration(time.Duration(v.(float64) * float64(time.Second)))
+	case "reverse":
+		w.SetReverse(v.(int) != 0)
+	}
+}
+
+func (w *ColorWipe) advance(dt time.Duration) {
+	w.mu.Lock()
+	done := w.elapsed >= w.duration
+	w.elapsed += dt
+	w.mu.Unlock()
+
+	if !done {
+		w.draw()
+	}
+	w.Layer.advance(dt)
+}
+
+// paint returns the color of the pixel at position i of the current wipe. It
+// must be called with mu held.
+func (w *ColorWipe) paint(i int) color.RGBA64 {
+	if w.color == nil {
+		return w.from[i]
+	}
+	t := 1.0
+	if w.duration > 0 {
+		t = math.Min(1, float64(w.elapsed)/float64(w.duration))
+	}
+	cover := wipeCover(i, len(w.from), t, w.start, w.reverse)
+
+	return blendLerp(w.from[i], toRGBA64(w.color), cover)
+}
+
+func (w *ColorWipe) draw() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	l := w.Layer
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.pixels {
+		l.pixels[i] = w.paint(i)
+	}
+	l.update()
+}
@@ -0,0 +1,77 @@
+package ring
+
+import (
+	"image/color"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWipeCover(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       float64
+		start   float64
+		reverse bool
+		want    []float64 // cover of the pixels
+	}{
+		{"start", 0, 0, false, []float64{0.5, 0, 0, 0}},
+		{"forward", 0.5, 0, false, []float64{1, 1, 0.5, 0}},
+		{"reverse", 0.5, 0, true, []float64{1, 0, 0.5, 1}},
+		{"from angle", 0.25, math.Pi, false, []float64{0, 0, 1, 0.5}},
+		{"done", 1, math.Pi / 2, true, []float64{1, 1, 1, 1}},
+	}
+
+	for _, ts := range tests {
+		for i, want := range ts.want {
+			if got := wipeCover(i, 4, ts.t, ts.start, ts.reverse); math.Abs(got-want) > 1e-9 {
+				t.Errorf("%s pixel %d got: %v, want: %v", ts.name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestColorWipe(t *testing.T) {
+	red := color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	blue := color.RGBA{0x00, 0x00, 0xFF, 0xFF}
+	w, err := NewColorWipe(red, &LayerOptions{Resolution: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetDuration(4 * time.Second)
+	w.advance(4 * time.Second)
+	if !w.Done() {
+		t.Errorf("got: not done, want: done")
+	}
+	w.Wipe(blue)
+	w.advance(1500 * time.Millisecond)
+
+	want := []uint32{0x0000FF, 0x0000FF, 0xFF0000, 0xFF0000}
+	for i := range want {
+		if got := serialize(w.Pixel(i)); got != want[i] {
+			t.Errorf("pixel %d got: %#x, want: %#x", i, got, want[i])
+		}
+	}
+	if w.Done() {
+		t.Errorf("got: done, want: not done")
+	}
+}
+
+func TestSceneManagerWipe(t *testing.T) {
+	scene := func(c color.Color) *Scene {
+		l := newTestLayer(t, &LayerOptions{Resolution: 1, ContentMode: ContentScale})
+		l.SetAll(c)
+		return &Scene{Layers: []*Layer{l}}
+	}
+	m := NewSceneManager(4)
+	m.SwitchTo(scene(color.RGBA{0xFF, 0x00, 0x00, 0xFF}), 0)
+	m.SwitchWith(scene(color.RGBA{0x00, 0x00, 0xFF, 0xFF}), time.Second, WipeTransition(0, true))
+	m.advance(375 * time.Millisecond)
+
+	want := []uint32{0x0000FF, 0xFF0000, 0xFF0000, 0x0000FF}
+	for i := range want {
+		if got := serialize(m.Pixel(i)); got != want[i] {
+			t.Errorf("pixel %d got: %#x, want: %#x", i, got, want[i])
+		}
+	}
+}